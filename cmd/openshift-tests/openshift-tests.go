@@ -14,7 +14,11 @@ import (
 	collectdiskcertificates "github.com/openshift/origin/pkg/cmd/openshift-tests/collect-disk-certificates"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/dev"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/disruption"
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/explain"
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/extensions"
+	historical_data "github.com/openshift/origin/pkg/cmd/openshift-tests/historical-data"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/images"
+	merge_results "github.com/openshift/origin/pkg/cmd/openshift-tests/merge-results"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor"
 	run_monitor "github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/run"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/timeline"
@@ -25,6 +29,7 @@ import (
 	run_test "github.com/openshift/origin/pkg/cmd/openshift-tests/run-test"
 	run_upgrade "github.com/openshift/origin/pkg/cmd/openshift-tests/run-upgrade"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/run_resource_watch"
+	verify_exceptions "github.com/openshift/origin/pkg/cmd/openshift-tests/verify-exceptions"
 	versioncmd "github.com/openshift/origin/pkg/cmd/openshift-tests/version"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	exutil "github.com/openshift/origin/test/extended/util"
@@ -83,16 +88,21 @@ func main() {
 		run_upgrade.NewRunUpgradeCommand(ioStreams),
 		images.NewImagesCommand(),
 		run_test.NewRunTestCommand(ioStreams),
+		explain.NewExplainCommand(ioStreams),
+		extensions.NewExtensionsCommand(ioStreams),
+		merge_results.NewMergeResultsCommand(ioStreams),
 		dev.NewDevCommand(),
 		run_monitor.NewRunMonitorCommand(ioStreams),
 		monitor.NewMonitorCommand(ioStreams),
 		disruption.NewDisruptionCommand(ioStreams),
 		risk_analysis.NewTestFailureRiskAnalysisCommand(),
+		historical_data.NewHistoricalDataCommand(ioStreams),
 		run_resource_watch.NewRunResourceWatchCommand(),
 		timeline.NewTimelineCommand(ioStreams),
 		run_disruption.NewRunInClusterDisruptionMonitorCommand(ioStreams),
 		collectdiskcertificates.NewRunCollectDiskCertificatesCommand(ioStreams),
 		render.NewRenderCommand(ioStreams),
+		verify_exceptions.NewVerifyExceptionsCommand(ioStreams),
 		versioncmd.NewVersionCommand(ioStreams),
 	)
 
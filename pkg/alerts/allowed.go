@@ -0,0 +1,50 @@
+package alerts
+
+import (
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
+)
+
+// AllowedAlertsDuringConformance lists all alerts that are allowed to be pending or firing during
+// conformance testing.  The alerts are defined in the exception registry, scope them to the conformance
+// suite there.
+func AllowedAlertsDuringConformance(env exceptionregistry.Environment) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending MetricConditions) {
+	return allowedAlerts(exceptionregistry.Default(), exceptionregistry.ConformanceSuite, env)
+}
+
+// AllowedAlertsDuringUpgrade lists all alerts that are allowed to be pending or firing during
+// upgrade.  The alerts are defined in the exception registry, scope them to the upgrade suite there.
+func AllowedAlertsDuringUpgrade(env exceptionregistry.Environment) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending MetricConditions) {
+	return allowedAlerts(exceptionregistry.Default(), exceptionregistry.UpgradeSuite, env)
+}
+
+// allowedAlerts splits the alert exceptions in scope by state, and by whether they have a bug.
+func allowedAlerts(registry *exceptionregistry.Registry, suite exceptionregistry.Suite, env exceptionregistry.Environment) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending MetricConditions) {
+	allowedFiringWithBugs, allowedFiring = MetricConditions{}, MetricConditions{}
+	allowedPendingWithBugs, allowedPending = MetricConditions{}, MetricConditions{}
+
+	for _, exception := range registry.For(exceptionregistry.AlertKind, suite, env) {
+		condition := MetricCondition{
+			Name:           exception.Name,
+			Bug:            exception.Bug,
+			AlertName:      exception.Alert.AlertName,
+			AlertNamespace: exception.Alert.AlertNamespace,
+			Text:           exception.Description(),
+		}
+		hasBug := len(exception.Bug) > 0
+		if exception.HasAlertState(exceptionregistry.AlertFiring) {
+			if hasBug {
+				allowedFiringWithBugs = append(allowedFiringWithBugs, condition)
+			} else {
+				allowedFiring = append(allowedFiring, condition)
+			}
+		}
+		if exception.HasAlertState(exceptionregistry.AlertPending) {
+			if hasBug {
+				allowedPendingWithBugs = append(allowedPendingWithBugs, condition)
+			} else {
+				allowedPending = append(allowedPending, condition)
+			}
+		}
+	}
+	return allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending
+}
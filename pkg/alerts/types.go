@@ -6,6 +6,11 @@ import (
 )
 
 type MetricCondition struct {
+	// Name identifies the exception this condition came from when reporting how often it was used.
+	Name string
+	// Bug links to the jira tracking the fix, if there is one.
+	Bug string
+
 	AlertName      string
 	AlertNamespace string
 	AlertLevel     string
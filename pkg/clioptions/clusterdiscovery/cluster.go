@@ -277,57 +277,78 @@ func LoadConfig(state *ClusterState) (*ClusterConfiguration, error) {
 // MatchFn returns a function that tests if a named function should be run based on
 // the cluster configuration
 func (c *ClusterConfiguration) MatchFn() func(string) bool {
-	var skips []string
-	skips = append(skips, fmt.Sprintf("[Skipped:%s]", c.ProviderName))
+	skips := c.skips()
+	matchFn := func(name string) bool {
+		for _, skip := range skips {
+			if strings.Contains(name, skip.annotation) {
+				return false
+			}
+		}
+		return true
+	}
+	return matchFn
+}
+
+// ExplainMatch is MatchFn, but also returns the annotation that skips the test and why it is skipped on this cluster.
+func (c *ClusterConfiguration) ExplainMatch(name string) (bool, string) {
+	for _, skip := range c.skips() {
+		if strings.Contains(name, skip.annotation) {
+			return false, fmt.Sprintf("annotated %s, %s", skip.annotation, skip.reason)
+		}
+	}
+	return true, ""
+}
+
+// clusterSkip is an annotation of tests that do not run on the cluster, and the reason.
+type clusterSkip struct {
+	annotation string
+	reason     string
+}
+
+func (c *ClusterConfiguration) skips() []clusterSkip {
+	var skips []clusterSkip
+	skips = append(skips, clusterSkip{fmt.Sprintf("[Skipped:%s]", c.ProviderName), fmt.Sprintf("the provider is %s", c.ProviderName)})
 
 	if c.IsIBMROKS {
-		skips = append(skips, "[Skipped:ibmroks]")
+		skips = append(skips, clusterSkip{"[Skipped:ibmroks]", "the cluster is IBM ROKS"})
 	}
 	if c.NetworkPlugin != "" {
-		skips = append(skips, fmt.Sprintf("[Skipped:Network/%s]", c.NetworkPlugin))
+		skips = append(skips, clusterSkip{fmt.Sprintf("[Skipped:Network/%s]", c.NetworkPlugin), fmt.Sprintf("the network plugin is %s", c.NetworkPlugin)})
 		if c.NetworkPluginMode != "" {
-			skips = append(skips, fmt.Sprintf("[Skipped:Network/%s/%s]", c.NetworkPlugin, c.NetworkPluginMode))
+			skips = append(skips, clusterSkip{fmt.Sprintf("[Skipped:Network/%s/%s]", c.NetworkPlugin, c.NetworkPluginMode), fmt.Sprintf("the network plugin is %s in %s mode", c.NetworkPlugin, c.NetworkPluginMode)})
 		}
 	}
 
 	if c.Disconnected {
-		skips = append(skips, "[Skipped:Disconnected]")
+		skips = append(skips, clusterSkip{"[Skipped:Disconnected]", "the cluster is disconnected"})
 	}
 
 	if c.IsProxied {
-		skips = append(skips, "[Skipped:Proxy]")
+		skips = append(skips, clusterSkip{"[Skipped:Proxy]", "the cluster is behind a proxy"})
 	}
 
 	if c.SingleReplicaTopology {
-		skips = append(skips, "[Skipped:SingleReplicaTopology]")
+		skips = append(skips, clusterSkip{"[Skipped:SingleReplicaTopology]", "the cluster has a single replica topology"})
 	}
 
 	if !c.HasIPv4 {
-		skips = append(skips, "[Feature:Networking-IPv4]")
+		skips = append(skips, clusterSkip{"[Feature:Networking-IPv4]", "the cluster has no IPv4"})
 	}
 	if !c.HasIPv6 {
-		skips = append(skips, "[Feature:Networking-IPv6]")
+		skips = append(skips, clusterSkip{"[Feature:Networking-IPv6]", "the cluster has no IPv6"})
 	}
 	if !c.HasIPv4 || !c.HasIPv6 {
 		// lack of "]" is intentional; this matches multiple tags
-		skips = append(skips, "[Feature:IPv6DualStack")
+		skips = append(skips, clusterSkip{"[Feature:IPv6DualStack", "the cluster is not dual stack"})
 	}
 
 	if !c.HasSCTP {
-		skips = append(skips, "[Feature:SCTPConnectivity]")
+		skips = append(skips, clusterSkip{"[Feature:SCTPConnectivity]", "the cluster has no SCTP"})
 	}
 
 	if c.HasNoOptionalCapabilities {
-		skips = append(skips, "[Skipped:NoOptionalCapabilities]")
+		skips = append(skips, clusterSkip{"[Skipped:NoOptionalCapabilities]", "the cluster has no optional capabilities"})
 	}
 
-	matchFn := func(name string) bool {
-		for _, skip := range skips {
-			if strings.Contains(name, skip) {
-				return false
-			}
-		}
-		return true
-	}
-	return matchFn
+	return skips
 }
@@ -0,0 +1,130 @@
+package clusterdiscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// clusterStateFile is how a ClusterState is serialized.  The API URL is kept as a string so it survives the round trip.
+type clusterStateFile struct {
+	APIURL               string                              `json:"apiURL,omitempty"`
+	PlatformStatus       *configv1.PlatformStatus            `json:"platformStatus"`
+	Masters              *corev1.NodeList                    `json:"masters"`
+	NonMasters           *corev1.NodeList                    `json:"nonMasters"`
+	NetworkSpec          *operatorv1.NetworkSpec             `json:"networkSpec"`
+	ControlPlaneTopology *configv1.TopologyMode              `json:"controlPlaneTopology"`
+	OptionalCapabilities []configv1.ClusterVersionCapability `json:"optionalCapabilities"`
+	Version              *configv1.ClusterVersion            `json:"version"`
+}
+
+// WriteClusterState writes the state of a cluster to a file that ReadClusterState can read back, so the tests that
+// apply to the cluster can be determined later without it.
+func WriteClusterState(filename string, state *ClusterState) error {
+	serialized := clusterStateFile{
+		PlatformStatus:       state.PlatformStatus,
+		Masters:              withoutManagedFields(state.Masters),
+		NonMasters:           withoutManagedFields(state.NonMasters),
+		NetworkSpec:          state.NetworkSpec,
+		ControlPlaneTopology: state.ControlPlaneTopology,
+		OptionalCapabilities: state.OptionalCapabilities,
+		Version:              state.Version,
+	}
+	if state.APIURL != nil {
+		serialized.APIURL = state.APIURL.String()
+	}
+	if serialized.Version != nil {
+		serialized.Version = serialized.Version.DeepCopy()
+		serialized.Version.ManagedFields = nil
+	}
+
+	content, err := json.MarshalIndent(serialized, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0644)
+}
+
+// withoutManagedFields returns a copy of the nodes without their managed fields, which are large and never used to
+// configure the tests.
+func withoutManagedFields(nodes *corev1.NodeList) *corev1.NodeList {
+	if nodes == nil {
+		return nil
+	}
+	ret := nodes.DeepCopy()
+	for i := range ret.Items {
+		ret.Items[i].ManagedFields = nil
+	}
+	return ret
+}
+
+// ReadClusterState reads a ClusterState written by WriteClusterState.  Everything LoadConfig and the environment flags
+// of extensions need must be present.
+func ReadClusterState(filename string) (*ClusterState, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	serialized := clusterStateFile{}
+	if err := json.Unmarshal(content, &serialized); err != nil {
+		return nil, fmt.Errorf("unable to decode cluster state %q: %w", filename, err)
+	}
+
+	switch {
+	case serialized.PlatformStatus == nil:
+		return nil, fmt.Errorf("cluster state %q has no platformStatus", filename)
+	case serialized.ControlPlaneTopology == nil:
+		return nil, fmt.Errorf("cluster state %q has no controlPlaneTopology", filename)
+	case serialized.Masters == nil || serialized.NonMasters == nil:
+		return nil, fmt.Errorf("cluster state %q must have both masters and nonMasters", filename)
+	case serialized.NetworkSpec == nil:
+		return nil, fmt.Errorf("cluster state %q has no networkSpec", filename)
+	case serialized.Version == nil:
+		return nil, fmt.Errorf("cluster state %q has no version", filename)
+	}
+
+	state := &ClusterState{
+		PlatformStatus:       serialized.PlatformStatus,
+		Masters:              serialized.Masters,
+		NonMasters:           serialized.NonMasters,
+		NetworkSpec:          serialized.NetworkSpec,
+		ControlPlaneTopology: serialized.ControlPlaneTopology,
+		OptionalCapabilities: serialized.OptionalCapabilities,
+		Version:              serialized.Version,
+	}
+	if len(serialized.APIURL) > 0 {
+		if state.APIURL, err = url.Parse(serialized.APIURL); err != nil {
+			return nil, fmt.Errorf("cluster state %q has an invalid apiURL: %w", filename, err)
+		}
+	}
+	return state, nil
+}
+
+// replayedClusterState is used instead of discovering the state of a live cluster when set.
+var replayedClusterState *ClusterState
+
+// ReplayClusterState makes provider decoding, environment flags, and extension test listing use the ClusterState in
+// filename instead of a live cluster.  An empty filename leaves discovery alone.
+func ReplayClusterState(filename string) error {
+	if len(filename) == 0 {
+		return nil
+	}
+	state, err := ReadClusterState(filename)
+	if err != nil {
+		return err
+	}
+	logrus.WithField("file", filename).Info("Using the cluster state from a file instead of the cluster")
+	replayedClusterState = state
+	return nil
+}
+
+// ReplayedClusterState returns the ClusterState set by ReplayClusterState, or nil when the cluster is used.
+func ReplayedClusterState() *ClusterState {
+	return replayedClusterState
+}
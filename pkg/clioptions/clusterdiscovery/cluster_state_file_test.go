@@ -0,0 +1,76 @@
+package clusterdiscovery
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterStateRoundTrip(t *testing.T) {
+	apiURL, err := url.Parse("https://api.example.com:6443")
+	require.NoError(t, err)
+	topology := configv1.HighlyAvailableTopologyMode
+	masters := gceMasters.DeepCopy()
+	masters.Items[0].ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubelet"}}
+
+	state := &ClusterState{
+		APIURL:               apiURL,
+		PlatformStatus:       gcePlatform,
+		Masters:              masters,
+		NonMasters:           nonMasters,
+		NetworkSpec:          ovnKubernetesConfig,
+		ControlPlaneTopology: &topology,
+		OptionalCapabilities: configv1.KnownClusterVersionCapabilities,
+		Version: &configv1.ClusterVersion{
+			Status: configv1.ClusterVersionStatus{
+				Desired: configv1.Release{Version: "4.18.0", Image: "quay.io/openshift-release-dev/ocp-release:4.18.0-x86_64"},
+			},
+		},
+	}
+	filename := filepath.Join(t.TempDir(), "cluster-state.json")
+	require.NoError(t, WriteClusterState(filename, state))
+	assert.NotEmpty(t, masters.Items[0].ManagedFields, "writing must not modify the state")
+
+	read, err := ReadClusterState(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com:6443", read.APIURL.String())
+	assert.Empty(t, read.Masters.Items[0].ManagedFields)
+	assert.Equal(t, state.Version.Status.Desired, read.Version.Status.Desired)
+
+	// the replayed state must configure the tests exactly like the live one
+	expected, err := LoadConfig(state)
+	require.NoError(t, err)
+	actual, err := LoadConfig(read)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadClusterStateRequiresFields(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cluster-state.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"platformStatus": {"type": "AWS"}}`), 0644))
+
+	_, err := ReadClusterState(filename)
+	assert.EqualError(t, err, `cluster state "`+filename+`" has no controlPlaneTopology`)
+}
+
+func TestReadClusterStateRequiresVersion(t *testing.T) {
+	topology := configv1.HighlyAvailableTopologyMode
+	state := &ClusterState{
+		PlatformStatus:       gcePlatform,
+		Masters:              gceMasters,
+		NonMasters:           nonMasters,
+		NetworkSpec:          ovnKubernetesConfig,
+		ControlPlaneTopology: &topology,
+	}
+	filename := filepath.Join(t.TempDir(), "cluster-state.json")
+	require.NoError(t, WriteClusterState(filename, state))
+
+	_, err := ReadClusterState(filename)
+	assert.EqualError(t, err, `cluster state "`+filename+`" has no version`)
+}
@@ -0,0 +1,51 @@
+package clusterdiscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainMatch(t *testing.T) {
+	config := &ClusterConfiguration{
+		ProviderName:  "aws",
+		NetworkPlugin: "OVNKubernetes",
+		HasIPv4:       true,
+		IsProxied:     true,
+	}
+	matchFn := config.MatchFn()
+
+	tests := []struct {
+		name     string
+		testName string
+		reason   string
+	}{
+		{
+			name:     "runs",
+			testName: "[sig-network] test [Skipped:gce] [Suite:openshift/conformance/parallel]",
+		},
+		{
+			name:     "skipped on the provider",
+			testName: "[sig-network] test [Skipped:aws] [Suite:openshift/conformance/parallel]",
+			reason:   "annotated [Skipped:aws], the provider is aws",
+		},
+		{
+			name:     "skipped behind a proxy",
+			testName: "[sig-network] test [Skipped:Proxy] [Suite:openshift/conformance/parallel]",
+			reason:   "annotated [Skipped:Proxy], the cluster is behind a proxy",
+		},
+		{
+			name:     "needs dual stack",
+			testName: "[sig-network] test [Feature:IPv6DualStackAlphaFeature] [Suite:openshift/conformance/parallel]",
+			reason:   "annotated [Feature:IPv6DualStack, the cluster is not dual stack",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches, reason := config.ExplainMatch(test.testName)
+			assert.Equal(t, test.reason, reason)
+			assert.Equal(t, len(test.reason) == 0, matches)
+			assert.Equal(t, matches, matchFn(test.testName))
+		})
+	}
+}
@@ -70,6 +70,11 @@ func InitializeTestFramework(context *e2e.TestContextType, config *ClusterConfig
 	// IPFamily constants are taken from kube e2e and used by tests
 	context.IPFamily = config.IPFamily
 
+	// a replayed cluster state is for planning which tests apply, there is no cluster to ask
+	if ReplayedClusterState() != nil {
+		return nil
+	}
+
 	coreClient, err := e2e.LoadClientset(true)
 	if err != nil {
 		return err
@@ -102,6 +107,9 @@ func DecodeProvider(providerTypeOrJSON string, dryRun, discover bool, clusterSta
 		"discover":     discover,
 		"clusterState": clusterState,
 	}).Info("Decoding provider")
+	if clusterState == nil {
+		clusterState = ReplayedClusterState()
+	}
 	switch providerTypeOrJSON {
 	case "none":
 		config := &ClusterConfiguration{
@@ -130,7 +138,7 @@ func DecodeProvider(providerTypeOrJSON string, dryRun, discover bool, clusterSta
 				return &ClusterConfiguration{ProviderName: "local"}, nil
 			}
 		}
-		if dryRun {
+		if dryRun && clusterState == nil {
 			return &ClusterConfiguration{ProviderName: "skeleton"}, nil
 		}
 		fallthrough
@@ -0,0 +1,75 @@
+package historicaldataoptions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortests/etcd/etcdmetrics"
+	"github.com/openshift/origin/pkg/monitortests/node/machineconfigrollout"
+	"github.com/sirupsen/logrus"
+)
+
+// OverrideHistoricalData replaces the embedded disruption, alert, etcd metric, and machine config rollout historical
+// data with the content of the files.
+// Each file is in the format of a query_results.json, as written by `openshift-tests historical-data`, and the kind
+// of data is detected from its content.
+func OverrideHistoricalData(files []string) error {
+	for _, file := range files {
+		historicalJSON, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("unable to read historical data: %w", err)
+		}
+		kind, err := historicaldata.DetectDataKind(historicalJSON)
+		if err != nil {
+			return fmt.Errorf("unable to read historical data %q: %w", file, err)
+		}
+
+		switch kind {
+		case historicaldata.DisruptionDataKind:
+			err = allowedbackenddisruption.OverrideCurrentResults(historicalJSON)
+		case historicaldata.AlertDataKind:
+			err = allowedalerts.OverrideHistoricalData(historicalJSON)
+		case historicaldata.EtcdMetricDataKind:
+			err = etcdmetrics.OverrideHistoricalData(historicalJSON)
+		case historicaldata.MachineConfigRolloutDataKind:
+			err = machineconfigrollout.OverrideHistoricalData(historicalJSON)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to load historical %s data %q: %w", kind, file, err)
+		}
+		logrus.Infof("Using historical %s data from %s", kind, file)
+	}
+	return nil
+}
+
+// SetFallbackStrategies configures which fallbacks are tried when historical data has too few job runs for a job type.
+// An empty list keeps the default.
+func SetFallbackStrategies(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if err := historicaldata.SetFallbackStrategies(names); err != nil {
+		return err
+	}
+	logrus.Infof("Using historical data fallbacks %v", names)
+	return nil
+}
+
+// FallbackFlagUsage is the help text for --historical-data-fallback.
+func FallbackFlagUsage() string {
+	return fmt.Sprintf("Ordered list of fallbacks to try when historical data has too few job runs for this job type.  One or more of [%s], defaults to previous-release.",
+		strings.Join(historicaldata.FallbackStrategyNames(), ", "))
+}
+
+// SetDisruptionEvaluator chooses how observed disruption is compared to historical data.
+func SetDisruptionEvaluator(evaluator string, flakeProbability, failProbability float64) error {
+	return disruptionlibrary.SetEvaluator(disruptionlibrary.Evaluator(evaluator), historicaldata.AnomalyThresholds{
+		FlakeProbability: flakeProbability,
+		FailProbability:  failProbability,
+	})
+}
@@ -55,7 +55,9 @@ func newFeatureGateFilter(ctx context.Context, configClient clientconfigv1.Inter
 	return ret, nil
 }
 
-func (f *featureGateFilter) includeTest(name string) bool {
+// explain returns whether the test runs with the feature gates of the cluster, and if not, the feature gate that
+// keeps it from running.
+func (f *featureGateFilter) explain(name string) (bool, string) {
 	featureGates := []string{}
 	matches := featureGateRegex.FindAllStringSubmatch(name, -1)
 	for _, match := range matches {
@@ -66,15 +68,24 @@ func (f *featureGateFilter) includeTest(name string) bool {
 		featureGates = append(featureGates, featureGate)
 	}
 
-	if f.disabled.HasAny(featureGates...) {
-		return false
+	for _, featureGate := range featureGates {
+		if f.disabled.Has(featureGate) {
+			return false, fmt.Sprintf("annotated [OCPFeatureGate:%s], which is disabled on the cluster", featureGate)
+		}
 	}
-
-	return f.enabled.HasAll(featureGates...)
+	for _, featureGate := range featureGates {
+		if !f.enabled.Has(featureGate) {
+			return false, fmt.Sprintf("annotated [OCPFeatureGate:%s], which is not enabled on the cluster", featureGate)
+		}
+	}
+	return true, ""
 }
 
-func includeNonFeatureGateTest(name string) bool {
-	return featureGateRegex.FindAllStringSubmatch(name, -1) == nil
+func explainNonFeatureGateTest(name string) (bool, string) {
+	if match := featureGateRegex.FindString(name); len(match) > 0 {
+		return false, fmt.Sprintf("annotated %s, and the cluster has no FeatureGates", match)
+	}
+	return true, ""
 }
 
 var (
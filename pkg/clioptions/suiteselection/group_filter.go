@@ -36,16 +36,17 @@ var (
 	apiGroupRegex = regexp.MustCompile(`\[apigroup:([^]]*)\]`)
 )
 
-func (agf *apiGroupFilter) includeTest(name string) bool {
-	apiGroups := []string{}
+// explain returns whether the cluster serves every api group the test needs, and if not, the first one it does not.
+func (agf *apiGroupFilter) explain(name string) (bool, string) {
 	matches := apiGroupRegex.FindAllStringSubmatch(name, -1)
 	for _, match := range matches {
 		if len(match) < 2 {
 			panic(fmt.Errorf("regexp match %v is invalid: len(match) < 2 for %v", match, name))
 		}
 		apigroup := match[1]
-		apiGroups = append(apiGroups, apigroup)
+		if !agf.apiGroups.Has(apigroup) {
+			return false, fmt.Sprintf("annotated [apigroup:%s], which is not served by the cluster", apigroup)
+		}
 	}
-
-	return agf.apiGroups.HasAll(apiGroups...)
+	return true, ""
 }
@@ -100,14 +100,32 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 	if err != nil {
 		return nil, err
 	}
-	suite.AddRequiredMatchFunc(testFileMatchFn)
+	if testFileMatchFn != nil {
+		suite.AddRequiredFilter(testginkgo.TestFilter{
+			Name: "test file",
+			Explain: func(name string) (bool, string) {
+				if !testFileMatchFn(name) {
+					return false, fmt.Sprintf("not listed in --file %s", f.TestFile)
+				}
+				return true, ""
+			},
+		})
+	}
 
 	if len(f.Regex) > 0 {
 		re, err := regexp.Compile(f.Regex)
 		if err != nil {
 			return nil, err
 		}
-		suite.AddRequiredMatchFunc(re.MatchString)
+		suite.AddRequiredFilter(testginkgo.TestFilter{
+			Name: "run regex",
+			Explain: func(name string) (bool, string) {
+				if !re.MatchString(name) {
+					return false, fmt.Sprintf("does not match --run %s", f.Regex)
+				}
+				return true, ""
+			},
+		})
 	}
 
 	suite.AddRequiredMatchFunc(f.MatchFn)
@@ -135,7 +153,7 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 			if err != nil {
 				return nil, fmt.Errorf("unable to build api group filter: %w", err)
 			}
-			suite.AddRequiredMatchFunc(apiGroupFilter.includeTest)
+			suite.AddRequiredFilter(testginkgo.TestFilter{Name: "api groups", Explain: apiGroupFilter.explain})
 		}
 	}
 
@@ -151,11 +169,11 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 		case apierrors.IsNotFound(err):
 			// In case we are unable to determine if there is support for feature gates, exclude all featuregated tests
 			// as the test target doesnt comply with preconditions.
-			suite.AddRequiredMatchFunc(includeNonFeatureGateTest)
+			suite.AddRequiredFilter(testginkgo.TestFilter{Name: "feature gates", Explain: explainNonFeatureGateTest})
 		case err != nil:
 			return nil, fmt.Errorf("unable to build FeatureGate filter: %w", err)
 		default:
-			suite.AddRequiredMatchFunc(featureGateFilter.includeTest)
+			suite.AddRequiredFilter(testginkgo.TestFilter{Name: "feature gates", Explain: featureGateFilter.explain})
 		}
 	}
 
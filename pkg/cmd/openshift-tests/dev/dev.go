@@ -80,7 +80,8 @@ a running cluster.
 				configv1.Default,
 				allowedalerts.DefaultAllowances,
 				intervals,
-				monitorapi.ResourcesMap{})
+				monitorapi.ResourcesMap{},
+				nil)
 			for _, tc := range testCases {
 				if tc.FailureOutput != nil {
 					logrus.Warnf("FAIL: %s\n\n%s\n\n", tc.Name, tc.FailureOutput.Output)
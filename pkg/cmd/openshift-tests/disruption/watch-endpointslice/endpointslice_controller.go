@@ -207,7 +207,7 @@ func (c *EndpointSliceController) syncEndpointSlice(ctx context.Context, key str
 		historicalBackendDisruptionDataForReusedConnectionsName := fmt.Sprintf("%s-%v-connections", c.backendPrefix, monitorapi.ReusedConnectionType)
 		intervalLocator := fmt.Sprintf("%s-from-node-%v-to-node-%v-endpoint-%v", c.backendPrefix, c.myNodeName, newWatcher.nodeName, newWatcher.address)
 		newWatcher.newConnectionSampler = backenddisruption.NewSimpleBackendWithLocator(
-			monitorapi.NewLocator().LocateDisruptionCheck(historicalBackendDisruptionDataForNewConnectionsName, intervalLocator, monitorapi.NewConnectionType),
+			monitorapi.NewLocator().LocateDisruptionCheckBetweenNodes(historicalBackendDisruptionDataForNewConnectionsName, intervalLocator, monitorapi.NewConnectionType, c.myNodeName, newWatcher.nodeName),
 			url,
 			"",
 			monitorapi.NewConnectionType,
@@ -218,7 +218,7 @@ func (c *EndpointSliceController) syncEndpointSlice(ctx context.Context, key str
 		newWatcher.newConnectionSampler.StartEndpointMonitoring(ctx, c.recorder, nil)
 
 		newWatcher.reusedConnectionSampler = backenddisruption.NewSimpleBackendWithLocator(
-			monitorapi.NewLocator().LocateDisruptionCheck(historicalBackendDisruptionDataForReusedConnectionsName, intervalLocator, monitorapi.ReusedConnectionType),
+			monitorapi.NewLocator().LocateDisruptionCheckBetweenNodes(historicalBackendDisruptionDataForReusedConnectionsName, intervalLocator, monitorapi.ReusedConnectionType, c.myNodeName, newWatcher.nodeName),
 			url,
 			"",
 			monitorapi.ReusedConnectionType,
@@ -0,0 +1,231 @@
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
+	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
+	"github.com/openshift/origin/pkg/clioptions/suiteselection"
+	"github.com/openshift/origin/pkg/test/extensions"
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/testsuites"
+	exutil "github.com/openshift/origin/test/extended/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type ExplainFlags struct {
+	TestSuiteSelectionFlags *suiteselection.TestSuiteSelectionFlags
+
+	Suite                 string
+	ProviderTypeOrJSON    string
+	ClusterStateFile      string
+	ExtensionBinaries     []string
+	ExtensionBinariesFile string
+	Upgrade               bool
+	JSONFile              string
+
+	genericclioptions.IOStreams
+}
+
+func NewExplainFlags(streams genericclioptions.IOStreams) *ExplainFlags {
+	return &ExplainFlags{
+		TestSuiteSelectionFlags: suiteselection.NewTestSuiteSelectionFlags(streams),
+		Suite:                   "openshift/conformance",
+		IOStreams:               streams,
+	}
+}
+
+func NewExplainCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewExplainFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "explain [TESTNAME]",
+		Short: "Explain why a test is included in or removed from a suite on this cluster",
+		Long: templates.LongDesc(`
+		Explain why a test is included in or removed from a suite on this cluster
+
+		Each stage of test selection the run command applies is reported for the test: the environment flags
+		extensions use to list their tests, the definition of the suite including [Disabled] and [SkippedUntil]
+		annotations, --file and --run, the provider, network, IP family, SCTP, and proxy of the cluster, the API
+		groups it serves, its feature gates, and the exclusions of a kube rebase in progress.  When a stage removes
+		the test, the rule or annotation that removed it is shown.
+
+		If no test has exactly the given name, every test containing it is explained.  With --json-file, the
+		selection of every test is written to the file, and the test name may be omitted.
+
+		With --cluster-state-file, tests are selected for the cluster state a previous run wrote to its
+		artifacts, without a cluster.  The API groups, feature gates, and rebase exclusions of that cluster are
+		not known, so those stages are not explained.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions(args)
+			if err != nil {
+				return err
+			}
+			return o.Run(context.Background())
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (f *ExplainFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Suite, "suite", f.Suite, "The suite to explain the selection of tests for.")
+	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
+	flags.StringVar(&f.ClusterStateFile, "cluster-state-file", f.ClusterStateFile, "Explain the selection for the cluster state in this file, like the cluster-state_*.json of a previous run, instead of the cluster.")
+	flags.StringArrayVar(&f.ExtensionBinaries, "extension-binary", f.ExtensionBinaries, "Run the tests of a locally built extension binary, instead of the extension of the release payload reporting the same component. May be repeated.")
+	flags.StringVar(&f.ExtensionBinariesFile, "extension-binaries-file", f.ExtensionBinariesFile, "A yaml file listing locally built extension binaries, with the component each must report, to use like --extension-binary.")
+	flags.BoolVar(&f.Upgrade, "upgrade", f.Upgrade, "Explain the selection for a suite run during an upgrade, which extensions may list different tests for.")
+	flags.StringVar(&f.JSONFile, "json-file", f.JSONFile, "Write the selection of every test, with the stages that removed it, to this file.")
+	f.TestSuiteSelectionFlags.BindFlags(flags)
+}
+
+func (f *ExplainFlags) ToOptions(args []string) (*ExplainOptions, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("only one test name may be explained at a time")
+	}
+	var testName string
+	if len(args) == 1 {
+		testName = args[0]
+	}
+	if len(testName) == 0 && len(f.JSONFile) == 0 {
+		return nil, fmt.Errorf("specify a test name, --json-file, or both")
+	}
+
+	if err := clusterdiscovery.ReplayClusterState(f.ClusterStateFile); err != nil {
+		return nil, err
+	}
+	if err := extensions.UseLocalBinaries(f.ExtensionBinaries, f.ExtensionBinariesFile); err != nil {
+		return nil, err
+	}
+	// without a cluster, the stages that need one are skipped the same way run --dry-run skips them
+	withoutCluster := len(f.ClusterStateFile) > 0
+
+	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
+	switch {
+	case err != nil && withoutCluster:
+		adminRESTConfig = &rest.Config{}
+	case err != nil:
+		return nil, fmt.Errorf("unable to get admin rest config, %w", err)
+	}
+
+	providerConfig, err := clusterdiscovery.DecodeProvider(f.ProviderTypeOrJSON, withoutCluster, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := clusterdiscovery.InitializeTestFramework(exutil.TestContext, providerConfig, withoutCluster); err != nil {
+		return nil, err
+	}
+
+	suites := append(testsuites.StandardTestSuites(), testsuites.UpgradeTestSuites()...)
+	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
+		suites,
+		[]string{f.Suite},
+		kubeconfig.NewDiscoveryGetter(adminRESTConfig),
+		kubeconfig.NewConfigClientGetter(adminRESTConfig),
+		withoutCluster,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// the same filter run adds with the provider's MatchFn, but able to say which annotation removed a test
+	suite.AddRequiredFilter(testginkgo.TestFilter{Name: "cluster configuration", Explain: providerConfig.ExplainMatch})
+
+	o := &ExplainOptions{
+		TestName:        testName,
+		Suite:           suite,
+		AdminRESTConfig: adminRESTConfig,
+		DryRun:          withoutCluster,
+		Upgrade:         f.Upgrade,
+		JSONFile:        f.JSONFile,
+		IOStreams:       f.IOStreams,
+	}
+	if withoutCluster {
+		o.AdminRESTConfig = nil
+	}
+	return o, nil
+}
+
+type ExplainOptions struct {
+	TestName string
+	Suite    *testginkgo.TestSuite
+	// AdminRESTConfig is nil without a cluster.
+	AdminRESTConfig *rest.Config
+	DryRun          bool
+	Upgrade         bool
+	JSONFile        string
+
+	genericclioptions.IOStreams
+}
+
+func (o *ExplainOptions) Run(ctx context.Context) error {
+	selections, err := testginkgo.ExplainTestSelection(ctx, o.Suite, o.AdminRESTConfig, o.Upgrade, o.DryRun, testsuites.DisabledReason)
+	if err != nil {
+		return err
+	}
+
+	if len(o.JSONFile) > 0 {
+		content, err := json.MarshalIndent(selections, "", "    ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.JSONFile, content, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.ErrOut, "Wrote the selection of %d tests to %s\n", len(selections), o.JSONFile)
+	}
+	if len(o.TestName) == 0 {
+		return nil
+	}
+
+	matching := matchingSelections(selections, o.TestName)
+	if len(matching) == 0 {
+		return fmt.Errorf("no test in openshift-tests or its extensions is named %q", o.TestName)
+	}
+	for _, selection := range matching {
+		printSelection(o.Out, o.Suite.Name, selection)
+	}
+	return nil
+}
+
+// matchingSelections returns the test with exactly the name, or if there is none, every test containing it.
+func matchingSelections(selections []*testginkgo.TestSelection, name string) []*testginkgo.TestSelection {
+	var contains []*testginkgo.TestSelection
+	for _, selection := range selections {
+		if selection.Name == name {
+			return []*testginkgo.TestSelection{selection}
+		}
+		if strings.Contains(selection.Name, name) {
+			contains = append(contains, selection)
+		}
+	}
+	return contains
+}
+
+func printSelection(out io.Writer, suiteName string, selection *testginkgo.TestSelection) {
+	result := "runs"
+	if !selection.Included {
+		result = "does not run"
+	}
+	fmt.Fprintf(out, "%q from %s %s in suite %s\n", selection.Name, selection.Source, result, suiteName)
+	for _, stage := range selection.Stages {
+		if stage.Passed {
+			fmt.Fprintf(out, "  %s: passed\n", stage.Stage)
+			continue
+		}
+		fmt.Fprintf(out, "  %s: removed, %s\n", stage.Stage, stage.Reason)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,163 @@
+package extensions
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/origin/pkg/cmd"
+	testextensions "github.com/openshift/origin/pkg/test/extensions"
+)
+
+func NewExtensionsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:              "extensions",
+		Short:            "Manage the extension binaries openshift-tests runs",
+		PersistentPreRun: cmd.NoPrintVersion,
+		SilenceErrors:    true,
+	}
+	cmd.AddCommand(
+		NewCacheCommand(streams),
+	)
+	return cmd
+}
+
+func NewCacheCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clean up the cache of extension binaries extracted from release payloads",
+		Long: templates.LongDesc(`
+		Inspect and clean up the cache of extension binaries extracted from release payloads
+
+		Extension binaries are cached in $XDG_CACHE_HOME/openshift-tests, or $HOME/.cache/openshift-tests, keyed by
+		the digest of the image they were extracted from and their path in it.  The cache is shared by every
+		openshift-tests process on the host, and binaries in use by one are never removed.
+		`),
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		newCacheListCommand(streams),
+		newCachePruneCommand(streams),
+		newCacheVerifyCommand(streams),
+	)
+	return cmd
+}
+
+func newCacheListCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:           "ls",
+		Short:         "List the cached extension binaries",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binaries, err := testextensions.ListCachedBinaries(testextensions.CacheDir())
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(streams.Out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "IMAGE\tPATH\tSIZE\tLAST USED")
+			for _, binary := range binaries {
+				if len(binary.SHA256) == 0 {
+					fmt.Fprintf(w, "%s\t<incomplete>\t\t%s\n", binary.Dir, lastUsed(binary.LastUsed))
+					continue
+				}
+				size := resource.NewQuantity(binary.Size, resource.BinarySI)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", binary.Image, binary.Path, size, lastUsed(binary.LastUsed))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func lastUsed(t time.Time) string {
+	return duration.HumanDuration(time.Since(t)) + " ago"
+}
+
+type CachePruneFlags struct {
+	MaxAge time.Duration
+	All    bool
+
+	genericclioptions.IOStreams
+}
+
+func newCachePruneCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := &CachePruneFlags{
+		MaxAge:    testextensions.DefaultCacheMaxAge,
+		IOStreams: streams,
+	}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached extension binaries that were not used recently",
+		Long: templates.LongDesc(`
+		Remove cached extension binaries that were not used recently
+
+		Every run of openshift-tests prunes binaries that were not used for a week.  Binaries in use by another
+		openshift-tests process are kept, even with --all.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return f.Run()
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (f *CachePruneFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&f.MaxAge, "max-age", f.MaxAge, "Remove binaries that were not used for this long.")
+	flags.BoolVar(&f.All, "all", f.All, "Remove every binary that is not in use.")
+}
+
+func (f *CachePruneFlags) Run() error {
+	maxAge := f.MaxAge
+	if f.All {
+		maxAge = 0
+	}
+	removed, err := testextensions.PruneCache(testextensions.CacheDir(), maxAge)
+	for _, dir := range removed {
+		fmt.Fprintf(f.Out, "Removed %s\n", dir)
+	}
+	return err
+}
+
+func newCacheVerifyCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the checksum of every cached extension binary",
+		Long: templates.LongDesc(`
+		Verify the checksum of every cached extension binary
+
+		Binaries are verified before every use, and extracted again when they do not match their checksum, so this
+		is only needed to inspect the cache.  The command fails when any binary is invalid.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binaries, err := testextensions.ListCachedBinaries(testextensions.CacheDir())
+			if err != nil {
+				return err
+			}
+			invalid := 0
+			for _, binary := range binaries {
+				if err := binary.Verify(); err != nil {
+					invalid++
+					fmt.Fprintf(streams.Out, "INVALID %s: %v\n", binary.Dir, err)
+					continue
+				}
+				fmt.Fprintf(streams.Out, "OK      %s %s\n", binary.Image, binary.Path)
+			}
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d cached binaries are invalid, remove them with prune --all or let openshift-tests extract them again", invalid, len(binaries))
+			}
+			return nil
+		},
+	}
+}
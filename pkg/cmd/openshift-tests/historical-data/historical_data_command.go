@@ -0,0 +1,252 @@
+package historical_data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/origin/pkg/cmd"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortests/etcd/etcdmetrics"
+	"github.com/openshift/origin/pkg/monitortests/node/machineconfigrollout"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type HistoricalDataFlags struct {
+	Kind         string
+	InputFile    string
+	InputFormat  string
+	BaselineFile string
+	OutputFile   string
+	ReportFile   string
+
+	genericclioptions.IOStreams
+}
+
+func NewHistoricalDataFlags(streams genericclioptions.IOStreams) *HistoricalDataFlags {
+	return &HistoricalDataFlags{
+		Kind:      string(historicaldata.DisruptionDataKind),
+		IOStreams: streams,
+	}
+}
+
+func NewHistoricalDataCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewHistoricalDataFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "historical-data",
+		Short: "Refresh historical data from a BigQuery export",
+		Long: templates.LongDesc(`
+		Refresh disruption, alert, etcd metric, or machine config rollout historical data from a BigQuery export
+
+		The input is a csv or json export with either one observation per row (a DisruptionSeconds, AlertSeconds,
+		WorstP99Seconds, or NodeRolloutSeconds column) or one row per key with precomputed P95, P99, and JobRuns columns.  Rows are validated, percentiles
+		are computed, and the result is compared against the data embedded in this binary.  Every added, removed,
+		or changed key is reported.
+
+		The result is written in the same format as the embedded query_results.json.  By default it is stored in
+		the user cache directory so it can be passed to --historical-data-file on later runs.
+		`),
+		PersistentPreRun: cmd.NoPrintVersion,
+		SilenceUsage:     true,
+		SilenceErrors:    true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *HistoricalDataFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Kind, "kind", f.Kind, "The kind of historical data: disruption, alerts, etcd-metrics, or machine-config-rollout.")
+	flags.StringVar(&f.InputFile, "input", f.InputFile, "The BigQuery export to read.")
+	flags.StringVar(&f.InputFormat, "input-format", f.InputFormat, "csv or json.  Defaults to the extension of --input.")
+	flags.StringVar(&f.BaselineFile, "baseline", f.BaselineFile, "A query_results.json to compare against instead of the data embedded in this binary.")
+	flags.StringVar(&f.OutputFile, "output-file", f.OutputFile, "Where to write the new query_results.json.  Defaults to the user cache directory.")
+	flags.StringVar(&f.ReportFile, "report-file", f.ReportFile, "If set, a json list of every changed key is written here.")
+}
+
+func (f *HistoricalDataFlags) ToOptions() (*HistoricalDataOptions, error) {
+	kind := historicaldata.DataKind(f.Kind)
+	switch kind {
+	case historicaldata.DisruptionDataKind, historicaldata.AlertDataKind, historicaldata.EtcdMetricDataKind, historicaldata.MachineConfigRolloutDataKind:
+	default:
+		return nil, fmt.Errorf("--kind must be %q, %q, %q, or %q", historicaldata.DisruptionDataKind, historicaldata.AlertDataKind,
+			historicaldata.EtcdMetricDataKind, historicaldata.MachineConfigRolloutDataKind)
+	}
+	if len(f.InputFile) == 0 {
+		return nil, fmt.Errorf("--input is required")
+	}
+
+	inputFormat := f.InputFormat
+	if len(inputFormat) == 0 {
+		inputFormat = strings.TrimPrefix(filepath.Ext(f.InputFile), ".")
+	}
+	switch inputFormat {
+	case "csv", "json":
+	default:
+		return nil, fmt.Errorf("unable to determine the format of %q, set --input-format to csv or json", f.InputFile)
+	}
+
+	outputFile := f.OutputFile
+	if len(outputFile) == 0 {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine the cache directory, set --output-file: %w", err)
+		}
+		outputFile = filepath.Join(cacheDir, "openshift-tests", "historical-data", fmt.Sprintf("%s_query_results.json", kind))
+	}
+
+	return &HistoricalDataOptions{
+		Kind:         kind,
+		InputFile:    f.InputFile,
+		InputFormat:  inputFormat,
+		BaselineFile: f.BaselineFile,
+		OutputFile:   outputFile,
+		ReportFile:   f.ReportFile,
+		IOStreams:    f.IOStreams,
+	}, nil
+}
+
+type HistoricalDataOptions struct {
+	Kind         historicaldata.DataKind
+	InputFile    string
+	InputFormat  string
+	BaselineFile string
+	OutputFile   string
+	ReportFile   string
+
+	genericclioptions.IOStreams
+}
+
+func (o *HistoricalDataOptions) Run() error {
+	input, err := os.Open(o.InputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	rows, err := historicaldata.ReadExportedRows(input, o.InputFormat)
+	if err != nil {
+		return fmt.Errorf("unable to read %q: %w", o.InputFile, err)
+	}
+
+	var baselineJSON []byte
+	if len(o.BaselineFile) > 0 {
+		baselineJSON, err = os.ReadFile(o.BaselineFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	output := &bytes.Buffer{}
+	var changes []historicaldata.DataChange
+	switch o.Kind {
+	case historicaldata.DisruptionDataKind:
+		newData, err := historicaldata.IngestDisruptionData(rows)
+		if err != nil {
+			return err
+		}
+		baseline, err := allowedbackenddisruption.GetCurrentResults()
+		if baselineJSON != nil {
+			baseline, err = historicaldata.NewDisruptionMatcher(baselineJSON)
+			if err != nil {
+				return fmt.Errorf("unable to read baseline %q: %w", o.BaselineFile, err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		changes = historicaldata.DiffDisruptionData(baseline.HistoricalData, newData)
+		if err := historicaldata.WriteDisruptionQueryResults(output, newData); err != nil {
+			return err
+		}
+
+	case historicaldata.AlertDataKind:
+		newData, err := historicaldata.IngestAlertData(rows)
+		if err != nil {
+			return err
+		}
+		baseline := allowedalerts.GetHistoricalData()
+		if baselineJSON != nil {
+			if baseline, err = historicaldata.NewAlertMatcher(baselineJSON); err != nil {
+				return fmt.Errorf("unable to read baseline %q: %w", o.BaselineFile, err)
+			}
+		}
+		changes = historicaldata.DiffAlertData(baseline.HistoricalData, newData)
+		if err := historicaldata.WriteAlertQueryResults(output, newData); err != nil {
+			return err
+		}
+
+	case historicaldata.EtcdMetricDataKind:
+		newData, err := historicaldata.IngestEtcdMetricData(rows)
+		if err != nil {
+			return err
+		}
+		baseline := etcdmetrics.GetHistoricalData()
+		if baselineJSON != nil {
+			if baseline, err = historicaldata.NewEtcdMetricMatcher(baselineJSON); err != nil {
+				return fmt.Errorf("unable to read baseline %q: %w", o.BaselineFile, err)
+			}
+		}
+		changes = historicaldata.DiffPercentileData(baseline.HistoricalData, newData)
+		if err := historicaldata.WritePercentileQueryResults(output, newData); err != nil {
+			return err
+		}
+
+	case historicaldata.MachineConfigRolloutDataKind:
+		newData, err := historicaldata.IngestMachineConfigRolloutData(rows)
+		if err != nil {
+			return err
+		}
+		baseline := machineconfigrollout.GetHistoricalData()
+		if baselineJSON != nil {
+			if baseline, err = historicaldata.NewMachineConfigRolloutMatcher(baselineJSON); err != nil {
+				return fmt.Errorf("unable to read baseline %q: %w", o.BaselineFile, err)
+			}
+		}
+		changes = historicaldata.DiffPercentileData(baseline.HistoricalData, newData)
+		if err := historicaldata.WritePercentileQueryResults(output, newData); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(o.Out, change.String())
+	}
+	fmt.Fprintf(o.Out, "%s historical data: %s\n", o.Kind, historicaldata.SummarizeChanges(changes))
+
+	if len(o.ReportFile) > 0 {
+		report, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.ReportFile, report, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.OutputFile), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.OutputFile, output.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Wrote %s, use --historical-data-file=%s to evaluate runs against it\n", o.OutputFile, o.OutputFile)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package merge_results
+
+import (
+	"fmt"
+
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type MergeResultsFlags struct {
+	JUnitDir string
+
+	genericclioptions.IOStreams
+}
+
+func NewMergeResultsFlags(streams genericclioptions.IOStreams) *MergeResultsFlags {
+	return &MergeResultsFlags{
+		IOStreams: streams,
+	}
+}
+
+func NewMergeResultsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewMergeResultsFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "merge-results SHARD_JUNIT_DIR...",
+		Short: "Merge the results of every shard of a sharded run into the results of the suite",
+		Long: templates.LongDesc(`
+		Merge the results of every shard of a sharded run into the results of the suite
+
+		Each argument is the --junit-dir of one run started with --shard-index and --shard-count.  The junit, extension
+		test results, and test failure summaries of the shards are combined and written to --junit-dir, as if the suite
+		had run in a single process.
+
+		A test that failed and then passed when its shard retried it is a flake, unless more tests failed across all the
+		shards than the suite allows flakes, in which case it is a failure.  A test reported by more than one shard, like
+		the invariants each shard checks, fails if it failed in any of them.  The command fails when any test failed, or
+		when a test assigned to a shard has no result because the shard did not finish.
+		`),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions(args)
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+	f.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (f *MergeResultsFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.JUnitDir, "junit-dir", f.JUnitDir, "The directory to write the merged test reports to.")
+}
+
+func (f *MergeResultsFlags) ToOptions(args []string) (*MergeResultsOptions, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("specify the --junit-dir of every shard")
+	}
+	if len(f.JUnitDir) == 0 {
+		return nil, fmt.Errorf("--junit-dir is required")
+	}
+	return &MergeResultsOptions{
+		ShardDirs: args,
+		JUnitDir:  f.JUnitDir,
+		IOStreams: f.IOStreams,
+	}, nil
+}
+
+type MergeResultsOptions struct {
+	ShardDirs []string
+	JUnitDir  string
+
+	genericclioptions.IOStreams
+}
+
+func (o *MergeResultsOptions) Run() error {
+	merged, err := testginkgo.MergeShardResults(o.ShardDirs, o.JUnitDir, o.Out, o.ErrOut)
+	if err != nil {
+		return err
+	}
+	if len(merged.Failed) > 0 || len(merged.Missing) > 0 {
+		return fmt.Errorf("%d fail, %d without results, %d flake, %d pass, %d skip",
+			len(merged.Failed), len(merged.Missing), len(merged.Flaked), len(merged.Passed), len(merged.Skipped))
+	}
+	fmt.Fprintf(o.Out, "%d pass, %d flake, %d skip\n", len(merged.Passed), len(merged.Flaked), len(merged.Skipped))
+	return nil
+}
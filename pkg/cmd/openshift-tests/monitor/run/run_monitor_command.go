@@ -10,9 +10,12 @@ import (
 	"time"
 
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
+	"github.com/openshift/origin/pkg/clioptions/historicaldataoptions"
 
 	"github.com/openshift/origin/pkg/clioptions/imagesetup"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/test/extended/util/image"
@@ -34,14 +37,27 @@ type RunMonitorFlags struct {
 	DisableMonitorTests []string
 	FromRepository      string
 
+	DisruptionBackendsFile string
+	FailOnRemovedAPIUsage  bool
+	AuditLogDir            string
+	HistoricalDataFiles    []string
+	HistoricalDataFallback []string
+
+	DisruptionEvaluator        string
+	DisruptionFlakeProbability float64
+	DisruptionFailProbability  float64
+
 	genericclioptions.IOStreams
 }
 
 func NewRunMonitorOptions(streams genericclioptions.IOStreams, fromRepository string) *RunMonitorFlags {
 	return &RunMonitorFlags{
-		DisplayFromNow: true,
-		IOStreams:      streams,
-		FromRepository: fromRepository,
+		DisplayFromNow:             true,
+		DisruptionEvaluator:        string(disruptionlibrary.P99Evaluator),
+		DisruptionFlakeProbability: historicaldata.DefaultAnomalyThresholds.FlakeProbability,
+		DisruptionFailProbability:  historicaldata.DefaultAnomalyThresholds.FailProbability,
+		IOStreams:                  streams,
+		FromRepository:             fromRepository,
 	}
 }
 
@@ -90,6 +106,14 @@ func (f *RunMonitorFlags) BindFlags(flags *pflag.FlagSet) {
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&f.DisableMonitorTests, "disable-monitor", f.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
+	flags.StringVar(&f.DisruptionBackendsFile, "disruption-backends", f.DisruptionBackendsFile, "A yaml file describing additional endpoints to monitor for disruption.")
+	flags.BoolVar(&f.FailOnRemovedAPIUsage, "fail-on-removed-api-usage", f.FailOnRemovedAPIUsage, "Fail, instead of flake, when platform components use APIs removed in the next kube release.")
+	flags.StringVar(&f.AuditLogDir, "audit-log-dir", f.AuditLogDir, "A local directory of audit logs for the audit log analyzer to read instead of the logs on the cluster's nodes.")
+	flags.StringSliceVar(&f.HistoricalDataFiles, "historical-data-file", f.HistoricalDataFiles, "Disruption, alert, etcd metric, or machine config rollout historical data to use instead of the data embedded in this binary.  Usually created by the historical-data command.")
+	flags.StringSliceVar(&f.HistoricalDataFallback, "historical-data-fallback", f.HistoricalDataFallback, historicaldataoptions.FallbackFlagUsage())
+	flags.StringVar(&f.DisruptionEvaluator, "disruption-evaluator", f.DisruptionEvaluator, "How disruption is compared to historical data: p99 fails above the historical P99 plus grace, anomaly scores against the whole historical distribution.")
+	flags.Float64Var(&f.DisruptionFlakeProbability, "disruption-flake-probability", f.DisruptionFlakeProbability, "With --disruption-evaluator=anomaly, flake when fewer than this fraction of historical runs saw as much disruption.")
+	flags.Float64Var(&f.DisruptionFailProbability, "disruption-fail-probability", f.DisruptionFailProbability, "With --disruption-evaluator=anomaly, fail when fewer than this fraction of historical runs saw as much disruption.")
 }
 
 func (f *RunMonitorFlags) ToOptions() (*RunMonitorOptions, error) {
@@ -104,6 +128,16 @@ func (f *RunMonitorFlags) ToOptions() (*RunMonitorOptions, error) {
 		}
 	}
 
+	if err := historicaldataoptions.OverrideHistoricalData(f.HistoricalDataFiles); err != nil {
+		return nil, err
+	}
+	if err := historicaldataoptions.SetFallbackStrategies(f.HistoricalDataFallback); err != nil {
+		return nil, err
+	}
+	if err := historicaldataoptions.SetDisruptionEvaluator(f.DisruptionEvaluator, f.DisruptionFlakeProbability, f.DisruptionFailProbability); err != nil {
+		return nil, err
+	}
+
 	monitorTestRegistry, err := f.getMonitorTestRegistry()
 	if err != nil {
 		return nil, err
@@ -123,6 +157,9 @@ func (f *RunMonitorFlags) getMonitorTestRegistry() (monitortestframework.Monitor
 		ClusterStabilityDuringTest: monitortestframework.Stable,
 		ExactMonitorTests:          f.ExactMonitorTests,
 		DisableMonitorTests:        f.DisableMonitorTests,
+		DisruptionBackendsFile:     f.DisruptionBackendsFile,
+		FailOnRemovedAPIUsage:      f.FailOnRemovedAPIUsage,
+		AuditLogDir:                f.AuditLogDir,
 	}
 	return defaultmonitortests.NewMonitorTestsFor(monitorTestInfo)
 }
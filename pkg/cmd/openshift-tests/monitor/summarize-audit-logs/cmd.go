@@ -2,9 +2,15 @@ package summarize_audit_logs
 
 import (
 	"context"
+	"encoding/xml"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	auditloganalyzer2 "github.com/openshift/origin/pkg/monitortests/kubeapiserver/auditloganalyzer"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 
 	"k8s.io/client-go/kubernetes"
 
@@ -15,6 +21,9 @@ import (
 
 type auditLogSummaryOptions struct {
 	ArtifactDir string
+	AuditLogDir string
+
+	FailOnRemovedAPIUsage bool
 
 	ConfigFlags *genericclioptions.ConfigFlags
 	IOStreams   genericclioptions.IOStreams
@@ -32,6 +41,11 @@ func AuditLogSummaryCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "summarize-audit-logs",
 		Short: "Download and inspect audit logs for interesting things.",
+		Long: `Download and inspect audit logs for interesting things.
+
+With --audit-log-dir, no cluster is needed.  The kube-apiserver audit logs in the directory, plain or gzipped, like
+the audit_logs directory of a must-gather, are run through every audit log check and the same artifacts and junit
+produced during a job run are written to --artifact-dir.`,
 
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -41,11 +55,17 @@ func AuditLogSummaryCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&o.ArtifactDir, "artifact-dir", o.ArtifactDir, "The directory where monitor events will be stored.")
+	cmd.Flags().StringVar(&o.AuditLogDir, "audit-log-dir", o.AuditLogDir, "A local directory of audit logs to analyze instead of downloading them from the cluster.")
+	cmd.Flags().BoolVar(&o.FailOnRemovedAPIUsage, "fail-on-removed-api-usage", o.FailOnRemovedAPIUsage, "With --audit-log-dir, fail, instead of flake, when platform components use APIs removed in the next kube release.")
 	o.ConfigFlags.AddFlags(cmd.Flags())
 	return cmd
 }
 
 func (o auditLogSummaryOptions) Run(ctx context.Context) error {
+	if len(o.AuditLogDir) > 0 {
+		return o.runOffline(ctx)
+	}
+
 	restConfig, err := o.ConfigFlags.ToRESTConfig()
 	if err != nil {
 		return err
@@ -67,3 +87,54 @@ func (o auditLogSummaryOptions) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// runOffline runs the audit log analyzer the same way the monitor does during a job run, reading the audit logs from
+// disk instead of the cluster.
+func (o auditLogSummaryOptions) runOffline(ctx context.Context) error {
+	if err := os.MkdirAll(o.ArtifactDir, 0755); err != nil {
+		return err
+	}
+
+	analyzer := auditloganalyzer2.NewOfflineAuditLogAnalyzer(o.AuditLogDir, o.FailOnRemovedAPIUsage)
+	if err := analyzer.StartCollection(ctx, nil, nil); err != nil {
+		return err
+	}
+	intervals, junits, err := analyzer.CollectData(ctx, o.ArtifactDir, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	evaluatedJunits, err := analyzer.EvaluateTestsFromConstructedIntervals(ctx, intervals)
+	if err != nil {
+		return err
+	}
+	junits = append(junits, evaluatedJunits...)
+
+	if err := analyzer.WriteContentToStorage(ctx, o.ArtifactDir, "", intervals, nil); err != nil {
+		return err
+	}
+	if err := monitorserialization.EventsToFile(filepath.Join(o.ArtifactDir, "e2e-events_audit-log-analyzer.json"), intervals); err != nil {
+		return err
+	}
+
+	junitSuite := junitapi.JUnitTestSuite{
+		Name: "audit-log-analyzer",
+	}
+	for _, junit := range junits {
+		junitSuite.NumTests++
+		if junit.FailureOutput != nil {
+			junitSuite.NumFailed++
+		}
+		junitSuite.TestCases = append(junitSuite.TestCases, junit)
+	}
+	out, err := xml.MarshalIndent(junitSuite, "", "    ")
+	if err != nil {
+		return err
+	}
+	junitPath := filepath.Join(o.ArtifactDir, "junit_audit-log-analyzer.xml")
+	if err := os.WriteFile(junitPath, out, 0640); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.IOStreams.Out, "%d of %d audit log tests failed, results written to %s\n", junitSuite.NumFailed, junitSuite.NumTests, junitPath)
+	return nil
+}
@@ -111,6 +111,8 @@ func (o *RunUpgradeSuiteOptions) Run(ctx context.Context) error {
 		UpgradeTargetPayloadImagePullSpec: o.ToImage,
 		ExactMonitorTests:                 o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:               o.GinkgoRunSuiteOptions.DisableMonitorTests,
+		DisruptionBackendsFile:            o.GinkgoRunSuiteOptions.DisruptionBackendsFile,
+		FailOnRemovedAPIUsage:             o.GinkgoRunSuiteOptions.FailOnRemovedAPIUsage,
 	}
 
 	o.GinkgoRunSuiteOptions.CommandEnv = o.TestCommandEnvironment()
@@ -6,6 +6,7 @@ import (
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
 	"github.com/openshift/origin/pkg/clioptions/suiteselection"
+	"github.com/openshift/origin/pkg/test/extensions"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	exutil "github.com/openshift/origin/test/extended/util"
 	"github.com/spf13/pflag"
@@ -20,8 +21,11 @@ type RunSuiteFlags struct {
 	OutputFlags             *iooptions.OutputFlags
 	AvailableSuites         []*testginkgo.TestSuite
 
-	FromRepository     string
-	ProviderTypeOrJSON string
+	FromRepository        string
+	ProviderTypeOrJSON    string
+	ClusterStateFile      string
+	ExtensionBinaries     []string
+	ExtensionBinariesFile string
 
 	// Passed to the test process if set
 	UpgradeSuite string
@@ -65,6 +69,9 @@ func (f *RunSuiteFlags) SuiteWithKubeTestInitializationPreSuite() (*clusterdisco
 func (f *RunSuiteFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 	flags.StringVar(&f.ProviderTypeOrJSON, "provider", f.ProviderTypeOrJSON, "The cluster infrastructure provider. Will automatically default to the correct value.")
+	flags.StringVar(&f.ClusterStateFile, "cluster-state-file", f.ClusterStateFile, "Select tests for the cluster state in this file, like the cluster-state_*.json of a previous run, instead of the cluster. Requires --dry-run.")
+	flags.StringArrayVar(&f.ExtensionBinaries, "extension-binary", f.ExtensionBinaries, "Run the tests of a locally built extension binary, instead of the extension of the release payload reporting the same component. May be repeated.")
+	flags.StringVar(&f.ExtensionBinariesFile, "extension-binaries-file", f.ExtensionBinariesFile, "A yaml file listing locally built extension binaries, with the component each must report, to use like --extension-binary.")
 	f.GinkgoRunSuiteOptions.BindFlags(flags)
 	f.TestSuiteSelectionFlags.BindFlags(flags)
 	f.OutputFlags.BindFlags(flags)
@@ -76,6 +83,17 @@ func (f *RunSuiteFlags) SetIOStreams(streams genericclioptions.IOStreams) {
 }
 
 func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
+	// the cluster state only replaces the cluster for selecting tests, running them needs the cluster
+	if len(f.ClusterStateFile) > 0 && !f.GinkgoRunSuiteOptions.DryRun {
+		return nil, fmt.Errorf("--cluster-state-file requires --dry-run")
+	}
+	if err := clusterdiscovery.ReplayClusterState(f.ClusterStateFile); err != nil {
+		return nil, err
+	}
+	if err := extensions.UseLocalBinaries(f.ExtensionBinaries, f.ExtensionBinariesFile); err != nil {
+		return nil, err
+	}
+
 	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
 	switch {
 	case err != nil && f.GinkgoRunSuiteOptions.DryRun:
@@ -83,6 +83,8 @@ func (o *RunSuiteOptions) Run(ctx context.Context) error {
 		ClusterStabilityDuringTest: monitortestframework.ClusterStabilityDuringTest(stabilitySetting),
 		ExactMonitorTests:          o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:        o.GinkgoRunSuiteOptions.DisableMonitorTests,
+		DisruptionBackendsFile:     o.GinkgoRunSuiteOptions.DisruptionBackendsFile,
+		FailOnRemovedAPIUsage:      o.GinkgoRunSuiteOptions.FailOnRemovedAPIUsage,
 	}
 
 	o.GinkgoRunSuiteOptions.CommandEnv = o.TestCommandEnvironment()
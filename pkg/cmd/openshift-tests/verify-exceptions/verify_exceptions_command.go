@@ -0,0 +1,172 @@
+package verify_exceptions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/cmd"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type VerifyExceptionsFlags struct {
+	ExceptionsFile string
+	ArtifactDirs   []string
+	Now            string
+
+	genericclioptions.IOStreams
+}
+
+func NewVerifyExceptionsFlags(streams genericclioptions.IOStreams) *VerifyExceptionsFlags {
+	return &VerifyExceptionsFlags{
+		IOStreams: streams,
+	}
+}
+
+func NewVerifyExceptionsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewVerifyExceptionsFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "verify-exceptions",
+		Short: "Verify the alert and pathological event exception registry",
+		Long: templates.LongDesc(`
+		Verify the alert and pathological event exception registry
+
+		Fails if any exception has expired.  If artifact directories are given, every e2e-events*.json found in
+		them is read, and exceptions that did not match a single interval in any of those runs are reported so they
+		can be removed.
+		`),
+		PersistentPreRun: cmd.NoPrintVersion,
+		SilenceUsage:     true,
+		SilenceErrors:    true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *VerifyExceptionsFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.ExceptionsFile, "exceptions-file", f.ExceptionsFile, "An exceptions.yaml to verify instead of the registry embedded in this binary.")
+	flags.StringSliceVar(&f.ArtifactDirs, "artifact-dir", f.ArtifactDirs, "A directory of job run artifacts to search for e2e-events*.json.  May be repeated.")
+	flags.StringVar(&f.Now, "now", f.Now, "The date, like 2006-01-02, to check expiry against.  Defaults to today.")
+}
+
+func (f *VerifyExceptionsFlags) ToOptions() (*VerifyExceptionsOptions, error) {
+	registry := exceptionregistry.Default()
+	if len(f.ExceptionsFile) > 0 {
+		content, err := os.ReadFile(f.ExceptionsFile)
+		if err != nil {
+			return nil, err
+		}
+		if registry, err = exceptionregistry.Parse(content); err != nil {
+			return nil, fmt.Errorf("invalid %q: %w", f.ExceptionsFile, err)
+		}
+	}
+
+	now := time.Now()
+	if len(f.Now) > 0 {
+		var err error
+		if now, err = time.Parse(exceptionregistry.ExpiresLayout, f.Now); err != nil {
+			return nil, fmt.Errorf("--now must be a date like 2006-01-02: %w", err)
+		}
+	}
+
+	return &VerifyExceptionsOptions{
+		Registry:     registry,
+		ArtifactDirs: f.ArtifactDirs,
+		Now:          now,
+		IOStreams:    f.IOStreams,
+	}, nil
+}
+
+type VerifyExceptionsOptions struct {
+	Registry     *exceptionregistry.Registry
+	ArtifactDirs []string
+	Now          time.Time
+
+	genericclioptions.IOStreams
+}
+
+func (o *VerifyExceptionsOptions) Run() error {
+	if len(o.ArtifactDirs) > 0 {
+		if err := o.reportUnmatched(); err != nil {
+			return err
+		}
+	}
+
+	expired := o.Registry.Expired(o.Now)
+	for _, exception := range expired {
+		fmt.Fprintf(o.Out, "expired: %s (%s) expired on %s %s\n", exception.Name, exception.Kind, exception.Expires, exception.Bug)
+	}
+	if len(expired) > 0 {
+		return fmt.Errorf("%d exceptions have expired, remove them or renew them if the bug is still open", len(expired))
+	}
+	fmt.Fprintf(o.Out, "%d exceptions verified\n", len(o.Registry.Exceptions))
+	return nil
+}
+
+func (o *VerifyExceptionsOptions) reportUnmatched() error {
+	files, err := findIntervalFiles(o.ArtifactDirs)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no e2e-events*.json found in %s", strings.Join(o.ArtifactDirs, ", "))
+	}
+
+	matched := sets.New[string]()
+	for _, file := range files {
+		intervals, err := monitorserialization.EventsFromFile(file)
+		if err != nil {
+			return fmt.Errorf("unable to read %q: %w", file, err)
+		}
+		matched = matched.Union(o.Registry.MatchedNames(intervals))
+	}
+
+	unmatched := 0
+	for _, exception := range o.Registry.Exceptions {
+		if matched.Has(exception.Name) {
+			continue
+		}
+		unmatched++
+		fmt.Fprintf(o.Out, "unmatched: %s (%s) %s\n", exception.Name, exception.Kind, exception.Description())
+	}
+	fmt.Fprintf(o.Out, "%d of %d exceptions matched nothing across %d runs\n", unmatched, len(o.Registry.Exceptions), len(files))
+	return nil
+}
+
+func findIntervalFiles(dirs []string) ([]string, error) {
+	files := []string{}
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasPrefix(d.Name(), "e2e-events") && strings.HasSuffix(d.Name(), ".json") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -156,6 +156,31 @@ func (b *LocatorBuilder) CloudNodeMetric(nodeName string, metric string) Locator
 		Build()
 }
 
+// EtcdMetric locates a metric reported by the etcd member scraped at instance.
+func (b *LocatorBuilder) EtcdMetric(instance, metric string) Locator {
+	b.targetType = LocatorTypeEtcdMetric
+	b.annotations[LocatorInstanceKey] = instance
+	return b.withMetric(metric).Build()
+}
+
+func (b *LocatorBuilder) Lease(namespace, name string) Locator {
+	b.targetType = LocatorTypeLease
+	b.annotations[LocatorLeaseKey] = name
+	return b.withNamespace(namespace).Build()
+}
+
+func (b *LocatorBuilder) PodDisruptionBudget(namespace, name string) Locator {
+	b.targetType = LocatorTypePodDisruptionBudget
+	b.annotations[LocatorPodDisruptionBudgetKey] = name
+	return b.withNamespace(namespace).Build()
+}
+
+func (b *LocatorBuilder) MachineConfigPool(name string) Locator {
+	b.targetType = LocatorTypeMachineConfigPool
+	b.annotations[LocatorMachineConfigPoolKey] = name
+	return b.Build()
+}
+
 func (b *LocatorBuilder) ClusterVersion(cv *v1.ClusterVersion) Locator {
 	b.targetType = LocatorTypeClusterVersion
 	b.annotations[LocatorClusterVersionKey] = cv.Name
@@ -341,6 +366,20 @@ func (b *LocatorBuilder) LocateDisruptionCheck(backendDisruptionName, thisInstan
 		Build()
 }
 
+// LocateDisruptionCheckBetweenNodes is a disruption check where both the poller and the target are on known nodes.
+func (b *LocatorBuilder) LocateDisruptionCheckBetweenNodes(backendDisruptionName, thisInstanceName string, connectionType BackendConnectionType, sourceNode, targetNode string) Locator {
+	b = b.
+		withDisruptionRequiredOnly(backendDisruptionName, thisInstanceName).
+		withConnectionType(connectionType)
+	if len(sourceNode) > 0 {
+		b.annotations[LocatorSourceNodeKey] = sourceNode
+	}
+	if len(targetNode) > 0 {
+		b.annotations[LocatorTargetNodeKey] = targetNode
+	}
+	return b.Build()
+}
+
 func (b *LocatorBuilder) LocateServer(serverName, nodeName, namespace, podName string) Locator {
 	return b.
 		withServer(serverName).
@@ -451,6 +490,31 @@ func (b *LocatorBuilder) StaticPodInstall(node, podType string) Locator {
 	return b.Build()
 }
 
+// APIClient locates a client of the kube-apiserver by the user and user agent from the audit log.
+func (b *LocatorBuilder) APIClient(user, userAgent string) Locator {
+	b.targetType = LocatorTypeAPIClient
+	b.annotations[LocatorAPIClientUserKey] = user
+	if len(userAgent) > 0 {
+		b.annotations[LocatorAPIClientUserAgentKey] = userAgent
+	}
+	return b.Build()
+}
+
+// APIClientRequest locates the requests one client of the kube-apiserver made with the same verb on the same object.
+// The namespace and name are optional, for cluster scoped resources and collections.
+func (b *LocatorBuilder) APIClientRequest(user, userAgent, verb, resource, namespace, name string) Locator {
+	b.APIClient(user, userAgent)
+	b.annotations[LocatorAPIClientVerbKey] = verb
+	b.annotations[LocatorAPIClientResourceKey] = resource
+	if len(namespace) > 0 {
+		b.withNamespace(namespace)
+	}
+	if len(name) > 0 {
+		b.annotations[LocatorNameKey] = name
+	}
+	return b.Build()
+}
+
 func (b *LocatorBuilder) ContainerFromPod(pod *corev1.Pod, containerName string) Locator {
 	b.PodFromPod(pod)
 	b.targetType = LocatorTypeContainer
@@ -118,6 +118,18 @@ const (
 	LocatorTypeKubeletSyncLoopProbe LocatorType = "KubeletSyncLoopProbe"
 	LocatorTypeKubeletSyncLoopPLEG  LocatorType = "KubeletSyncLoopPLEG"
 	LocatorTypeStaticPodInstall     LocatorType = "StaticPodInstall"
+
+	// LocatorTypeAPIClient is a client of the kube-apiserver, as seen in the audit log.
+	LocatorTypeAPIClient LocatorType = "APIClient"
+
+	// LocatorTypeEtcdMetric is a metric reported by a single etcd member.
+	LocatorTypeEtcdMetric LocatorType = "EtcdMetric"
+
+	LocatorTypeLease LocatorType = "Lease"
+
+	LocatorTypePodDisruptionBudget LocatorType = "PodDisruptionBudget"
+
+	LocatorTypeMachineConfigPool LocatorType = "MachineConfigPool"
 )
 
 type LocatorKey string
@@ -154,6 +166,16 @@ const (
 	LocatorRowKey                   LocatorKey = "row"
 	LocatorServerKey                LocatorKey = "server"
 	LocatorMetricKey                LocatorKey = "metric"
+	LocatorLeaseKey                 LocatorKey = "lease"
+	LocatorPodDisruptionBudgetKey   LocatorKey = "pdb"
+	LocatorMachineConfigPoolKey     LocatorKey = "machineconfigpool"
+	// LocatorSourceNodeKey and LocatorTargetNodeKey identify the nodes on either end of a node to node disruption check.
+	LocatorSourceNodeKey LocatorKey = "source-node"
+	LocatorTargetNodeKey LocatorKey = "target-node"
+	LocatorSourceZoneKey LocatorKey = "source-zone"
+	LocatorTargetZoneKey LocatorKey = "target-zone"
+	// LocatorNetworkPathKey classifies the route between the source and target nodes, like same-node or cross-zone.
+	LocatorNetworkPathKey LocatorKey = "network-path"
 
 	LocatorAPIUnreachableHostKey                  LocatorKey = "host"
 	LocatorOnPremKubeapiUnreachableFromHaproxyKey LocatorKey = "onprem-haproxy"
@@ -161,6 +183,11 @@ const (
 	LocatorTypeKubeletSyncLoopProbeType LocatorKey = "probe"
 	LocatorTypeKubeletSyncLoopPLEGType  LocatorKey = "plegType"
 	LocatorStaticPodInstallType         LocatorKey = "podType"
+
+	LocatorAPIClientUserKey      LocatorKey = "user"
+	LocatorAPIClientUserAgentKey LocatorKey = "user-agent"
+	LocatorAPIClientVerbKey      LocatorKey = "verb"
+	LocatorAPIClientResourceKey  LocatorKey = "resource"
 )
 
 type Locator struct {
@@ -260,10 +287,26 @@ const (
 	ReasonBadOperatorApply  IntervalReason = "BadOperatorApply"
 	ReasonKubeAPIServer500s IntervalReason = "KubeAPIServer500s"
 
+	ReasonAPIClientHotLoop   IntervalReason = "APIClientHotLoop"
+	ReasonAPIClientRateSpike IntervalReason = "APIClientRateSpike"
+
+	ReasonAuthorizationDenials IntervalReason = "AuthorizationDenials"
+
 	ReasonHighGeneration    IntervalReason = "HighGeneration"
 	ReasonInvalidGeneration IntervalReason = "GenerationViolation"
 
 	ReasonEtcdBootstrap IntervalReason = "EtcdBootstrap"
+
+	ReasonEtcdMetricOverThreshold IntervalReason = "EtcdMetricOverThreshold"
+
+	ReasonLeaderless    IntervalReason = "Leaderless"
+	ReasonLeaderChanged IntervalReason = "LeaderChanged"
+
+	ReasonEvictionBlocked   IntervalReason = "EvictionBlocked"
+	ReasonBelowMinAvailable IntervalReason = "BelowMinAvailable"
+	ReasonDrainBlockedByPDB IntervalReason = "DrainBlockedByPDB"
+
+	ReasonMachineConfigPoolRollout IntervalReason = "MachineConfigPoolRollout"
 )
 
 type AnnotationKey string
@@ -297,6 +340,11 @@ const (
 	AnnotationStatus         AnnotationKey = "status"
 	AnnotationCondition      AnnotationKey = "condition"
 	AnnotationPercentage     AnnotationKey = "percentage"
+	AnnotationRatePerMinute  AnnotationKey = "rate-per-minute"
+	AnnotationQuantile       AnnotationKey = "quantile"
+	AnnotationThreshold      AnnotationKey = "threshold"
+	AnnotationHolder         AnnotationKey = "holder"
+	AnnotationPreviousHolder AnnotationKey = "previous-holder"
 )
 
 // ConstructionOwner was originally meant to signify that an interval was derived from other intervals.
@@ -314,6 +362,8 @@ const (
 	ConstructionOwnerEtcdLifecycle    = "etcd-lifecycle-constructor"
 	ConstructionOwnerMachineLifecycle = "machine-lifecycle-constructor"
 	ConstructionOwnerLeaseChecker     = "lease-checker"
+	ConstructionOwnerPDBChecker       = "pdb-checker"
+	ConstructionOwnerPoolRollout      = "pool-rollout-constructor"
 	ConstructionOwnerOnPremHaproxy    = "on-prem-haproxy-constructor"
 )
 
@@ -368,6 +418,14 @@ const (
 	SourceGenerationMonitor IntervalSource = "GenerationMonitor"
 
 	SourceStaticPodInstallMonitor IntervalSource = "StaticPodInstallMonitor"
+
+	SourceEtcdMetrics IntervalSource = "EtcdMetrics"
+
+	SourceLeaseMonitor IntervalSource = "LeaseMonitor"
+
+	SourcePodDisruptionBudgetMonitor IntervalSource = "PodDisruptionBudgetMonitor"
+
+	SourceMachineConfigRollout IntervalSource = "MachineConfigRollout"
 )
 
 type Interval struct {
@@ -34,6 +34,16 @@ type MonitorTestInitializationInfo struct {
 
 	// DisableMonitorTests will remove any monitor tests contained in the provided list
 	DisableMonitorTests []string
+
+	// DisruptionBackendsFile is a yaml file describing additional endpoints to monitor for disruption
+	DisruptionBackendsFile string
+
+	// FailOnRemovedAPIUsage fails, instead of flakes, when platform components use APIs removed in the next release.
+	FailOnRemovedAPIUsage bool
+
+	// AuditLogDir is a local directory of audit logs, like a must-gather, for the audit log analyzer to read instead
+	// of the logs on the cluster's nodes.
+	AuditLogDir string
 }
 
 type MonitorTest interface {
@@ -49,6 +49,11 @@ func (d *etcdRevisionChangeAllowance) FlakeAfter(key historicaldata.AlertDataKey
 	return allowed.P95
 }
 
+func (d *etcdRevisionChangeAllowance) HistoricalDataDetails(key historicaldata.AlertDataKey) string {
+	_, details, _ := getClosestPercentilesValues(key)
+	return details
+}
+
 // GetEstimatedNumberOfRevisionsForEtcdOperator calculates the number of revisions that have occurred between now and duration
 func GetEstimatedNumberOfRevisionsForEtcdOperator(ctx context.Context, kubeClient kubernetes.Interface, duration time.Duration) (int, error) {
 	configMaps, err := kubeClient.CoreV1().ConfigMaps("openshift-etcd").List(ctx, metav1.ListOptions{})
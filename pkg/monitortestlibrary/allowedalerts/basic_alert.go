@@ -239,15 +239,19 @@ func (a *basicAlertTest) failOrFlake(firingIntervals, pendingIntervals monitorap
 		return fail, fmt.Sprintf("unable to calculate allowance for %s which was at %s, err %v\n\n%s", a.AlertName(), a.AlertState(), err, strings.Join(describe, "\n"))
 	}
 	flakeAfter := a.allowanceCalculator.FlakeAfter(dataKey)
+	historicalDetails := ""
+	if detailer, ok := a.allowanceCalculator.(allowanceDetailer); ok {
+		historicalDetails = fmt.Sprintf(" using historical data %s", detailer.HistoricalDataDetails(dataKey))
+	}
 
 	switch {
 	case durationAtOrAboveLevel > failAfter:
-		return fail, fmt.Sprintf("%s was at or above %s for at least %s on %#v (maxAllowed=%s): pending for %s, firing for %s:\n\n%s",
-			a.AlertName(), a.AlertState(), durationAtOrAboveLevel, *a.jobType, failAfter, pendingDuration, firingDuration, strings.Join(describe, "\n"))
+		return fail, fmt.Sprintf("%s was at or above %s for at least %s on %#v (maxAllowed=%s%s): pending for %s, firing for %s:\n\n%s",
+			a.AlertName(), a.AlertState(), durationAtOrAboveLevel, *a.jobType, failAfter, historicalDetails, pendingDuration, firingDuration, strings.Join(describe, "\n"))
 
 	case durationAtOrAboveLevel > flakeAfter:
-		return flake, fmt.Sprintf("%s was at or above %s for at least %s on %#v (maxAllowed=%s): pending for %s, firing for %s:\n\n%s",
-			a.AlertName(), a.AlertState(), durationAtOrAboveLevel, *a.jobType, flakeAfter, pendingDuration, firingDuration, strings.Join(describe, "\n"))
+		return flake, fmt.Sprintf("%s was at or above %s for at least %s on %#v (maxAllowed=%s%s): pending for %s, firing for %s:\n\n%s",
+			a.AlertName(), a.AlertState(), durationAtOrAboveLevel, *a.jobType, flakeAfter, historicalDetails, pendingDuration, firingDuration, strings.Join(describe, "\n"))
 	}
 
 	return pass, ""
@@ -51,6 +51,17 @@ func (d *percentileAllowances) FlakeAfter(key historicaldata2.AlertDataKey) time
 	return allowed.P95
 }
 
+// HistoricalDataDetails describes which historical data, and which fallbacks, were used for the key.
+func (d *percentileAllowances) HistoricalDataDetails(key historicaldata2.AlertDataKey) string {
+	_, details, _ := getClosestPercentilesValues(key)
+	return details
+}
+
+// allowanceDetailer is implemented by calculators whose allowances come from historical data.
+type allowanceDetailer interface {
+	HistoricalDataDetails(key historicaldata2.AlertDataKey) string
+}
+
 // getClosestPercentilesValues uses the backend and information about the cluster to choose the best historical p99 to operate against.
 // We enforce "don't get worse" for disruption by watching the aggregate data in CI over many runs.
 func getClosestPercentilesValues(key historicaldata2.AlertDataKey) (historicaldata2.StatisticalDuration, string, error) {
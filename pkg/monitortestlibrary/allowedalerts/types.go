@@ -35,6 +35,18 @@ func GetHistoricalData() *historicaldata.AlertBestMatcher {
 	return historicalData
 }
 
+// OverrideHistoricalData replaces the embedded historical data with the content of a file in the same format.  It must
+// be called before any monitor tests evaluate alerts.
+func OverrideHistoricalData(historicalJSON []byte) error {
+	matcher, err := historicaldata.NewAlertMatcher(historicalJSON)
+	if err != nil {
+		return err
+	}
+	readResults.Do(func() {})
+	historicalData = matcher
+	return nil
+}
+
 // AllowedAlertNames is a  list of alerts we do not test against.
 var AllowedAlertNames = []string{
 	"Watchdog",
@@ -3,6 +3,7 @@ package allowedbackenddisruption
 import (
 	"time"
 
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 )
 
@@ -20,5 +21,29 @@ const (
 // operate against.
 // We enforce "don't get worse" for disruption by watching the aggregate data in CI over many runs.
 func GetAllowedDisruption(backendName string, jobType platformidentification.JobType) (*time.Duration, string, error) {
-	return GetCurrentResults().BestMatchP99(backendName, jobType)
+	matcher, err := GetCurrentResults()
+	if err != nil {
+		return nil, "", err
+	}
+	return matcher.BestMatchP99(backendName, jobType)
+}
+
+// GetHistoricalDisruption returns the best historical percentiles for the backend, used to score how unusual the
+// observed disruption is.
+func GetHistoricalDisruption(backendName string, jobType platformidentification.JobType) (historicaldata.StatisticalDuration, string, error) {
+	matcher, err := GetCurrentResults()
+	if err != nil {
+		return historicaldata.StatisticalDuration{}, "", err
+	}
+	return matcher.BestMatchDistribution(backendName, jobType)
+}
+
+// ScoreHistoricalDisruption scores the observed disruption for a backend against its historical distribution using the
+// configured anomaly thresholds.  The returned bool is false when there is no usable historical data.
+func ScoreHistoricalDisruption(backendName string, jobType platformidentification.JobType, observed time.Duration) (historicaldata.AnomalyScore, historicaldata.StatisticalDuration, string, bool, error) {
+	historical, details, err := GetHistoricalDisruption(backendName, jobType)
+	if err != nil || historical == (historicaldata.StatisticalDuration{}) {
+		return historicaldata.AnomalyScore{}, historical, details, false, err
+	}
+	return historicaldata.ScoreDisruption(historical, observed, historicaldata.GetAnomalyThresholds()), historical, details, true, nil
 }
@@ -1,6 +1,9 @@
 package allowedbackenddisruption
 
 import (
+	"bytes"
+	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -8,6 +11,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetClosestP95Value(t *testing.T) {
@@ -196,7 +200,8 @@ func TestGetClosestP95Value(t *testing.T) {
 // from bigquery and commit into origin. Test ensures we can parse it and the data looks sane.
 func TestDisruptionDataFileParsing(t *testing.T) {
 
-	disruptionMatcher := GetCurrentResults()
+	disruptionMatcher, err := GetCurrentResults()
+	require.NoError(t, err)
 
 	var dataOver100Runs int
 	var foundAWSOVN bool
@@ -253,3 +258,41 @@ func TestDisruptionDataFileParsing(t *testing.T) {
 	assert.NotEqual(t, percentiles, historicaldata.StatisticalDuration{}, "BestMatchDuration found no match and could not fall back for kube-api-new-connections aws amd64 ovn ha")
 	assert.NoError(t, err)
 }
+
+// TestDisruptionDataFileRoundTrip ensures refreshing query_results.json with the historical-data command keeps every
+// column of every entry.
+func TestDisruptionDataFileRoundTrip(t *testing.T) {
+	disruptionMatcher, err := historicaldata.NewDisruptionMatcher(queryResults)
+	require.NoError(t, err)
+	out := &bytes.Buffer{}
+	require.NoError(t, historicaldata.WriteDisruptionQueryResults(out, disruptionMatcher.HistoricalData))
+
+	assert.Equal(t, normalizedEntries(t, queryResults), normalizedEntries(t, out.Bytes()))
+}
+
+// normalizedEntries decodes query results into entries keyed by their columns, with percentiles parsed so that "0.0"
+// and "0" compare equal, and null columns empty.
+func normalizedEntries(t *testing.T, historicalJSON []byte) map[string]map[string]interface{} {
+	entries := []map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(historicalJSON, &entries))
+	ret := map[string]map[string]interface{}{}
+	for _, entry := range entries {
+		for column, value := range entry {
+			switch column {
+			case "P50", "P75", "P95", "P99":
+				parsed, err := strconv.ParseFloat(value.(string), 64)
+				require.NoError(t, err)
+				entry[column] = parsed
+			default:
+				if value == nil {
+					entry[column] = ""
+				}
+			}
+		}
+		key, err := json.Marshal([]interface{}{entry["BackendName"], entry["Release"], entry["FromRelease"],
+			entry["Platform"], entry["Architecture"], entry["Network"], entry["Topology"]})
+		require.NoError(t, err)
+		ret[string(key)] = entry
+	}
+	return ret
+}
@@ -2,6 +2,7 @@ package allowedbackenddisruption
 
 import (
 	_ "embed"
+	"fmt"
 	"sync"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
@@ -53,19 +54,33 @@ order by
 var queryResults []byte
 
 var (
-	readResults    sync.Once
-	historicalData *historicaldata.DisruptionBestMatcher
+	readResults       sync.Once
+	historicalData    *historicaldata.DisruptionBestMatcher
+	historicalDataErr error
 )
 
-func GetCurrentResults() *historicaldata.DisruptionBestMatcher {
+// GetCurrentResults returns the embedded historical data, or the data set by OverrideCurrentResults.  An error is
+// returned when the embedded data is invalid.
+func GetCurrentResults() (*historicaldata.DisruptionBestMatcher, error) {
 	readResults.Do(
 		func() {
-			var err error
-			historicalData, err = historicaldata.NewDisruptionMatcher(queryResults)
-			if err != nil {
-				panic(err)
+			historicalData, historicalDataErr = historicaldata.NewDisruptionMatcher(queryResults)
+			if historicalDataErr != nil {
+				historicalDataErr = fmt.Errorf("invalid embedded disruption data: %w", historicalDataErr)
 			}
 		})
 
-	return historicalData
+	return historicalData, historicalDataErr
+}
+
+// OverrideCurrentResults replaces the embedded historical data with the content of a file in the same format.  It must
+// be called before any monitor tests evaluate disruption.
+func OverrideCurrentResults(historicalJSON []byte) error {
+	matcher, err := historicaldata.NewDisruptionMatcher(historicalJSON)
+	if err != nil {
+		return err
+	}
+	readResults.Do(func() {})
+	historicalData, historicalDataErr = matcher, nil
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
@@ -19,6 +20,33 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+type Evaluator string
+
+const (
+	// P99Evaluator fails when disruption exceeds the historical P99 plus a grace period.
+	P99Evaluator Evaluator = "p99"
+	// AnomalyEvaluator scores disruption against the full historical distribution and flakes or fails on improbable
+	// observations.
+	AnomalyEvaluator Evaluator = "anomaly"
+)
+
+var evaluator = P99Evaluator
+
+// SetEvaluator chooses how observed disruption is compared to historical data for every Availability.  It must be
+// called before any monitor tests are evaluated.
+func SetEvaluator(in Evaluator, thresholds historicaldata.AnomalyThresholds) error {
+	switch in {
+	case P99Evaluator, AnomalyEvaluator:
+	default:
+		return fmt.Errorf("unknown disruption evaluator %q, expected %q or %q", in, P99Evaluator, AnomalyEvaluator)
+	}
+	if err := historicaldata.SetAnomalyThresholds(thresholds); err != nil {
+		return err
+	}
+	evaluator = in
+	return nil
+}
+
 type Availability struct {
 	newConnectionTestName    string
 	reusedConnectionTestName string
@@ -30,6 +58,10 @@ type Availability struct {
 
 	newConnectionDisruptionSampler    *backenddisruption.BackendSampler
 	reusedConnectionDisruptionSampler *backenddisruption.BackendSampler
+
+	// allowedDisruption, when set, is used instead of historical data to decide how much disruption is tolerated.
+	// This is used by backends that do not have entries in query_results.json.
+	allowedDisruption *time.Duration
 }
 
 func NewAvailabilityInvariant(
@@ -43,6 +75,12 @@ func NewAvailabilityInvariant(
 	}
 }
 
+// WithAllowedDisruption sets a fixed amount of disruption to tolerate instead of looking up historical data.
+func (w *Availability) WithAllowedDisruption(allowedDisruption time.Duration) *Availability {
+	w.allowedDisruption = &allowedDisruption
+	return w
+}
+
 func (w *Availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	if w == nil {
 		return fmt.Errorf("unable to start collection because instance is nil")
@@ -50,11 +88,16 @@ func (w *Availability) StartCollection(ctx context.Context, adminRESTConfig *res
 
 	w.adminRESTConfig = adminRESTConfig
 
-	if err := w.newConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
-		return err
+	// either sampler may be nil when a caller only wishes to monitor one connection type.
+	if w.newConnectionDisruptionSampler != nil {
+		if err := w.newConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
+			return err
+		}
 	}
-	if err := w.reusedConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
-		return err
+	if w.reusedConnectionDisruptionSampler != nil {
+		if err := w.reusedConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -78,7 +121,9 @@ func (w *Availability) CollectData(ctx context.Context) (monitorapi.Intervals, [
 		}()
 
 		defer wg.Done()
-		w.newConnectionDisruptionSampler.Stop()
+		if w.newConnectionDisruptionSampler != nil {
+			w.newConnectionDisruptionSampler.Stop()
+		}
 	}()
 
 	var reusedRecoverErr error
@@ -91,7 +136,9 @@ func (w *Availability) CollectData(ctx context.Context) (monitorapi.Intervals, [
 		}()
 
 		defer wg.Done()
-		w.reusedConnectionDisruptionSampler.Stop()
+		if w.reusedConnectionDisruptionSampler != nil {
+			w.reusedConnectionDisruptionSampler.Stop()
+		}
 	}()
 
 	wg.Wait()
@@ -124,7 +171,7 @@ func createDisruptionJunit(
 		return &junitapi.JUnitTestCase{
 			Name: testName,
 			SkipMessage: &junitapi.SkipMessage{
-				Message: "No historical data to calculate allowedDisruption",
+				Message: fmt.Sprintf("No historical data to calculate allowedDisruption %v", disruptionDetails),
 			},
 		}
 	}
@@ -162,6 +209,8 @@ func createDisruptionJunit(
 	if roundedDisruptionDuration <= finalAllowedDisruption {
 		return &junitapi.JUnitTestCase{
 			Name: testName,
+			SystemOut: fmt.Sprintf("%v was unreachable for %s (maxAllowed=%s) using historical data %v",
+				locator.OldLocator(), roundedDisruptionDuration, finalAllowedDisruption, disruptionDetails),
 		}
 	}
 
@@ -181,40 +230,117 @@ func createDisruptionJunit(
 	}
 }
 
-func (w *Availability) junitForNewConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) (*junitapi.JUnitTestCase, error) {
-	newConnectionAllowed, newConnectionDisruptionDetails, err := historicalAllowedDisruption(ctx, w.newConnectionDisruptionSampler, jobType)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get new allowed disruption: %w", err)
-	}
-	return createDisruptionJunit(
-			w.newConnectionTestName, newConnectionAllowed, newConnectionDisruptionDetails, w.newConnectionDisruptionSampler.GetLocator(),
-			finalIntervals.Filter(
-				monitorapi.And(
-					monitorapi.IsEventForLocator(w.newConnectionDisruptionSampler.GetLocator()),
-					monitorapi.IsErrorEvent,
-				),
-			),
-			jobType,
-		),
-		nil
+// createFixedDisruptionJunit compares the observed disruption against an explicitly configured maximum.  Unlike
+// createDisruptionJunit, no grace is added since the caller chose the value.
+func createFixedDisruptionJunit(
+	testName string,
+	allowedDisruption time.Duration,
+	locator monitorapi.Locator,
+	disruptedIntervals monitorapi.Intervals) *junitapi.JUnitTestCase {
+
+	roundedDisruptionDuration := disruptedIntervals.Duration(1 * time.Second).Round(time.Second)
+	if roundedDisruptionDuration <= allowedDisruption {
+		return &junitapi.JUnitTestCase{
+			Name: testName,
+		}
+	}
+
+	reason := fmt.Sprintf("%v was unreachable during disruption", locator.OldLocator())
+	describe := disruptedIntervals.Strings()
+	failureMessage := fmt.Sprintf("%s for at least %s (maxAllowed=%s from configuration):\n\n%s", reason,
+		roundedDisruptionDuration, allowedDisruption,
+		strings.Join(describe, "\n"))
+
+	return &junitapi.JUnitTestCase{
+		Name: testName,
+		FailureOutput: &junitapi.FailureOutput{
+			Output: failureMessage,
+		},
+		SystemOut: failureMessage,
+	}
 }
 
-func (w *Availability) junitForReusedConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) (*junitapi.JUnitTestCase, error) {
-	reusedConnectionAllowed, reusedConnectionDisruptionDetails, err := historicalAllowedDisruption(ctx, w.reusedConnectionDisruptionSampler, jobType)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get reused allowed disruption: %w", err)
-	}
-	return createDisruptionJunit(
-			w.reusedConnectionTestName, reusedConnectionAllowed, reusedConnectionDisruptionDetails, w.reusedConnectionDisruptionSampler.GetLocator(),
-			finalIntervals.Filter(
-				monitorapi.And(
-					monitorapi.IsEventForLocator(w.reusedConnectionDisruptionSampler.GetLocator()),
-					monitorapi.IsErrorEvent,
-				),
-			),
-			jobType,
+// createAnomalyDisruptionJunit scores the observed disruption against the historical distribution.  Improbable
+// observations produce a failing junit, and for flakes a passing junit of the same name as well.
+func createAnomalyDisruptionJunit(
+	testName string,
+	historical historicaldata.StatisticalDuration,
+	disruptionDetails string,
+	locator monitorapi.Locator,
+	disruptedIntervals monitorapi.Intervals,
+	jobType *platformidentification.JobType) []*junitapi.JUnitTestCase {
+
+	if jobType.Platform == "" {
+		return []*junitapi.JUnitTestCase{{
+			Name: testName,
+			SkipMessage: &junitapi.SkipMessage{
+				Message: "Unknown platform, skipping disruption testing",
+			},
+		}}
+	}
+	if historical == (historicaldata.StatisticalDuration{}) {
+		return []*junitapi.JUnitTestCase{{
+			Name: testName,
+			SkipMessage: &junitapi.SkipMessage{
+				Message: fmt.Sprintf("No historical data to score disruption %v", disruptionDetails),
+			},
+		}}
+	}
+
+	thresholds := historicaldata.GetAnomalyThresholds()
+	score := historicaldata.ScoreDisruption(historical, disruptedIntervals.Duration(1*time.Second), thresholds)
+	summary := fmt.Sprintf("%v was unreachable for %.0fs: %v (flake below %v, fail below %v) using historical data %v",
+		locator.OldLocator(), score.ObservedSeconds, score, thresholds.FlakeProbability, thresholds.FailProbability, disruptionDetails)
+	if score.Classification == historicaldata.AnomalyPass {
+		return []*junitapi.JUnitTestCase{{
+			Name:      testName,
+			SystemOut: summary,
+		}}
+	}
+
+	failureMessage := fmt.Sprintf("%s\n\n%s", summary, strings.Join(disruptedIntervals.Strings(), "\n"))
+	ret := []*junitapi.JUnitTestCase{{
+		Name: testName,
+		FailureOutput: &junitapi.FailureOutput{
+			Output: failureMessage,
+		},
+		SystemOut: failureMessage,
+	}}
+	if score.Classification == historicaldata.AnomalyFlake {
+		ret = append(ret, &junitapi.JUnitTestCase{Name: testName})
+	}
+	return ret
+}
+
+func (w *Availability) junitsForSampler(ctx context.Context, testName string, sampler *backenddisruption.BackendSampler, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) ([]*junitapi.JUnitTestCase, error) {
+	disruptedIntervals := finalIntervals.Filter(
+		monitorapi.And(
+			monitorapi.IsEventForLocator(sampler.GetLocator()),
+			monitorapi.IsErrorEvent,
 		),
-		nil
+	)
+
+	if w.allowedDisruption != nil {
+		return []*junitapi.JUnitTestCase{
+			createFixedDisruptionJunit(testName, *w.allowedDisruption, sampler.GetLocator(), disruptedIntervals),
+		}, nil
+	}
+
+	if evaluator == AnomalyEvaluator {
+		historical, details, err := allowedbackenddisruption.GetHistoricalDisruption(sampler.GetDisruptionBackendName(), *jobType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get historical disruption for %v: %w", sampler.GetDisruptionBackendName(), err)
+		}
+		return createAnomalyDisruptionJunit(testName, historical, details, sampler.GetLocator(), disruptedIntervals, jobType), nil
+	}
+
+	allowed, details, err := historicalAllowedDisruption(ctx, sampler, jobType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get allowed disruption for %v: %w", sampler.GetDisruptionBackendName(), err)
+	}
+	return []*junitapi.JUnitTestCase{
+		createDisruptionJunit(testName, allowed, details, sampler.GetLocator(), disruptedIntervals, jobType),
+	}, nil
 }
 
 func historicalAllowedDisruption(ctx context.Context, backend *backenddisruption.BackendSampler, jobType *platformidentification.JobType) (*time.Duration, string, error) {
@@ -232,15 +358,22 @@ func (w *Availability) EvaluateTestsFromConstructedIntervals(ctx context.Context
 		return nil, err
 	}
 
-	newConnectionJunit, err := w.junitForNewConnections(ctx, finalIntervals, jobType)
-	if err != nil {
-		return nil, err
+	junits := []*junitapi.JUnitTestCase{}
+	if w.newConnectionDisruptionSampler != nil {
+		newConnectionJunits, err := w.junitsForSampler(ctx, w.newConnectionTestName, w.newConnectionDisruptionSampler, finalIntervals, jobType)
+		if err != nil {
+			return nil, err
+		}
+		junits = append(junits, newConnectionJunits...)
 	}
 
-	reusedConnectionJunit, err := w.junitForReusedConnections(ctx, finalIntervals, jobType)
-	if err != nil {
-		return nil, err
+	if w.reusedConnectionDisruptionSampler != nil {
+		reusedConnectionJunits, err := w.junitsForSampler(ctx, w.reusedConnectionTestName, w.reusedConnectionDisruptionSampler, finalIntervals, jobType)
+		if err != nil {
+			return nil, err
+		}
+		junits = append(junits, reusedConnectionJunits...)
 	}
 
-	return []*junitapi.JUnitTestCase{newConnectionJunit, reusedConnectionJunit}, nil
+	return junits, nil
 }
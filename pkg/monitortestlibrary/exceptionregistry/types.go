@@ -0,0 +1,386 @@
+package exceptionregistry
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// CurrentVersion is the only registry version this binary understands.  Bump it when a field changes meaning.
+const CurrentVersion = 1
+
+// ExpiresLayout is the layout of the expires field, a plain date.
+const ExpiresLayout = "2006-01-02"
+
+type Kind string
+
+const (
+	AlertKind             Kind = "Alert"
+	PathologicalEventKind Kind = "PathologicalEvent"
+)
+
+type Suite string
+
+const (
+	// ConformanceSuite covers every job that did not upgrade during collection.
+	ConformanceSuite Suite = "conformance"
+	// UpgradeSuite covers every job that upgraded during collection.
+	UpgradeSuite Suite = "upgrade"
+)
+
+type AlertState string
+
+const (
+	AlertFiring  AlertState = "firing"
+	AlertPending AlertState = "pending"
+)
+
+// Registry is the versioned list of every alert and pathological event we allow.
+type Registry struct {
+	Version    int         `json:"version"`
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// Exception allows a single alert or repeating event in the scope it applies to.
+type Exception struct {
+	// Name is a unique CamelCase name.  Pathological event exceptions are registered under this name.
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	// Reason explains why this is allowed.  Required unless Bug is set.
+	Reason string `json:"reason,omitempty"`
+	// Bug links to the jira tracking the fix.  If set, we consider this a problem that has been reported rather than
+	// expected behavior.
+	Bug string `json:"bug,omitempty"`
+	// Expires is the date after which verify-exceptions fails until the exception is removed or renewed.
+	Expires string `json:"expires,omitempty"`
+
+	Scope Scope         `json:"scope,omitempty"`
+	Alert *AlertMatcher `json:"alert,omitempty"`
+	Event *EventMatcher `json:"event,omitempty"`
+
+	expires time.Time
+	event   *compiledEventMatcher
+}
+
+// Scope limits where an exception applies.  Every empty field matches everything.
+type Scope struct {
+	Suites      []Suite               `json:"suites,omitempty"`
+	FeatureSets []configv1.FeatureSet `json:"featureSets,omitempty"`
+	// Topologies use the job type names: ha, single, or external.
+	Topologies []string `json:"topologies,omitempty"`
+	// Platforms use the job type names: aws, azure, gcp, metal, vsphere, and so on.
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+type AlertMatcher struct {
+	AlertName      string `json:"alertName"`
+	AlertNamespace string `json:"alertNamespace,omitempty"`
+	// States defaults to firing only.
+	States []AlertState `json:"states,omitempty"`
+}
+
+type EventMatcher struct {
+	// LocatorKeyRegexes is a map of locator key, like namespace or pod, to the regex that key must match.
+	LocatorKeyRegexes map[monitorapi.LocatorKey]string `json:"locatorKeyRegexes,omitempty"`
+	ReasonRegex       string                           `json:"reasonRegex,omitempty"`
+	HumanRegex        string                           `json:"humanRegex,omitempty"`
+	// RepeatThresholdOverride allows more than the default number of repeats.
+	RepeatThresholdOverride int `json:"repeatThresholdOverride,omitempty"`
+	// NeverAllow only marks the event as interesting so it gets charted, it never allows it to repeat.
+	NeverAllow bool `json:"neverAllow,omitempty"`
+}
+
+type compiledEventMatcher struct {
+	locatorKeyRegexes map[monitorapi.LocatorKey]*regexp.Regexp
+	reasonRegex       *regexp.Regexp
+	humanRegex        *regexp.Regexp
+}
+
+// Environment describes the cluster under test, it decides which scoped exceptions apply.
+type Environment struct {
+	FeatureSet configv1.FeatureSet
+	Platform   string
+	Topology   string
+}
+
+// NewEnvironment builds an environment from a job type, which may be nil if it could not be determined.
+func NewEnvironment(featureSet configv1.FeatureSet, jobType *platformidentification.JobType) Environment {
+	env := Environment{FeatureSet: featureSet}
+	if jobType != nil {
+		env.Platform = jobType.Platform
+		env.Topology = jobType.Topology
+	}
+	return env
+}
+
+//go:embed exceptions.yaml
+var exceptionsYAML []byte
+
+var defaultRegistry = mustParse(exceptionsYAML)
+
+func mustParse(content []byte) *Registry {
+	registry, err := Parse(content)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded exceptions.yaml: %v", err))
+	}
+	return registry
+}
+
+// Default returns the registry embedded in this binary.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Parse decodes and validates a registry.
+func Parse(content []byte) (*Registry, error) {
+	registry := &Registry{}
+	if err := yaml.UnmarshalStrict(content, registry); err != nil {
+		return nil, err
+	}
+	if registry.Version != CurrentVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", registry.Version, CurrentVersion)
+	}
+
+	names := map[string]bool{}
+	for i := range registry.Exceptions {
+		exception := &registry.Exceptions[i]
+		if len(exception.Name) == 0 {
+			return nil, fmt.Errorf("exception %d has no name", i)
+		}
+		if names[exception.Name] {
+			return nil, fmt.Errorf("exception %q is defined more than once", exception.Name)
+		}
+		names[exception.Name] = true
+		if err := exception.validate(); err != nil {
+			return nil, fmt.Errorf("exception %q: %w", exception.Name, err)
+		}
+	}
+	return registry, nil
+}
+
+func (e *Exception) validate() error {
+	if len(e.Reason) == 0 && len(e.Bug) == 0 {
+		return fmt.Errorf("reason or bug is required")
+	}
+	if len(e.Expires) > 0 {
+		expires, err := time.Parse(ExpiresLayout, e.Expires)
+		if err != nil {
+			return fmt.Errorf("expires must be a date like 2006-01-02: %w", err)
+		}
+		e.expires = expires
+	}
+	for _, suite := range e.Scope.Suites {
+		switch suite {
+		case ConformanceSuite, UpgradeSuite:
+		default:
+			return fmt.Errorf("unknown suite %q", suite)
+		}
+	}
+
+	switch e.Kind {
+	case AlertKind:
+		if e.Alert == nil || e.Event != nil {
+			return fmt.Errorf("alert exceptions must set alert and not event")
+		}
+		if len(e.Alert.AlertName) == 0 {
+			return fmt.Errorf("alertName is required")
+		}
+		for _, state := range e.Alert.States {
+			switch state {
+			case AlertFiring, AlertPending:
+			default:
+				return fmt.Errorf("unknown alert state %q", state)
+			}
+		}
+	case PathologicalEventKind:
+		if e.Event == nil || e.Alert != nil {
+			return fmt.Errorf("pathological event exceptions must set event and not alert")
+		}
+		compiled, err := e.Event.compile()
+		if err != nil {
+			return err
+		}
+		e.event = compiled
+	default:
+		return fmt.Errorf("unknown kind %q", e.Kind)
+	}
+	return nil
+}
+
+func (m *EventMatcher) compile() (*compiledEventMatcher, error) {
+	ret := &compiledEventMatcher{locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{}}
+	for key, expr := range m.LocatorKeyRegexes {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("locator key %s: %w", key, err)
+		}
+		ret.locatorKeyRegexes[key] = r
+	}
+	var err error
+	if len(m.ReasonRegex) > 0 {
+		if ret.reasonRegex, err = regexp.Compile(m.ReasonRegex); err != nil {
+			return nil, fmt.Errorf("reasonRegex: %w", err)
+		}
+	}
+	if len(m.HumanRegex) > 0 {
+		if ret.humanRegex, err = regexp.Compile(m.HumanRegex); err != nil {
+			return nil, fmt.Errorf("humanRegex: %w", err)
+		}
+	}
+	return ret, nil
+}
+
+// LocatorKeyRegexes returns the compiled locator key regexes of a pathological event exception.
+func (e *Exception) LocatorKeyRegexes() map[monitorapi.LocatorKey]*regexp.Regexp {
+	return e.event.locatorKeyRegexes
+}
+
+// ReasonRegex returns the compiled reason regex of a pathological event exception, or nil.
+func (e *Exception) ReasonRegex() *regexp.Regexp {
+	return e.event.reasonRegex
+}
+
+// HumanRegex returns the compiled human message regex of a pathological event exception, or nil.
+func (e *Exception) HumanRegex() *regexp.Regexp {
+	return e.event.humanRegex
+}
+
+// Description is the bug link if there is one, otherwise the reason.
+func (e *Exception) Description() string {
+	if len(e.Bug) > 0 {
+		return e.Bug
+	}
+	return e.Reason
+}
+
+// ExpiredAt returns true if the exception has an expiry date on or before now.
+func (e *Exception) ExpiredAt(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// HasAlertState returns true if an alert exception covers the given state.
+func (e *Exception) HasAlertState(state AlertState) bool {
+	if e.Alert == nil {
+		return false
+	}
+	if len(e.Alert.States) == 0 {
+		return state == AlertFiring
+	}
+	for _, s := range e.Alert.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesTo returns true if the exception is in scope for the suite and environment.  Fields of the environment we
+// could not determine only match exceptions that are not scoped on them.
+func (e *Exception) AppliesTo(suite Suite, env Environment) bool {
+	return matchesScope(e.Scope.Suites, suite) &&
+		matchesScope(e.Scope.FeatureSets, env.FeatureSet) &&
+		matchesScope(e.Scope.Topologies, env.Topology) &&
+		matchesScope(e.Scope.Platforms, env.Platform)
+}
+
+func matchesScope[T comparable](allowed []T, actual T) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == actual {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the interval is one this exception is written for, regardless of scope.
+func (e *Exception) Matches(interval monitorapi.Interval) bool {
+	switch e.Kind {
+	case AlertKind:
+		if interval.Source != monitorapi.SourceAlert {
+			return false
+		}
+		if !e.HasAlertState(AlertState(interval.Message.Annotations[monitorapi.AnnotationAlertState])) {
+			return false
+		}
+		if e.Alert.AlertName != interval.Locator.Keys[monitorapi.LocatorAlertKey] {
+			return false
+		}
+		return len(e.Alert.AlertNamespace) == 0 || e.Alert.AlertNamespace == interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+	case PathologicalEventKind:
+		for key, r := range e.event.locatorKeyRegexes {
+			if !r.MatchString(interval.Locator.Keys[key]) {
+				return false
+			}
+		}
+		if e.event.humanRegex != nil && !e.event.humanRegex.MatchString(interval.Message.HumanMessage) {
+			return false
+		}
+		if e.event.reasonRegex != nil && !e.event.reasonRegex.MatchString(string(interval.Message.Reason)) {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// For returns the exceptions of a kind that apply to the suite and environment.  Expired exceptions no longer apply,
+// so the alert or event they excused fails again until the exception is renewed or the bug is fixed.
+func (r *Registry) For(kind Kind, suite Suite, env Environment) []*Exception {
+	return r.forTime(kind, suite, env, time.Now())
+}
+
+func (r *Registry) forTime(kind Kind, suite Suite, env Environment, now time.Time) []*Exception {
+	ret := []*Exception{}
+	for i := range r.Exceptions {
+		exception := &r.Exceptions[i]
+		if exception.Kind != kind || !exception.AppliesTo(suite, env) {
+			continue
+		}
+		if exception.ExpiredAt(now) {
+			logrus.Warnf("exception %s expired on %s and no longer applies, renew it or remove it", exception.Name, exception.Expires)
+			continue
+		}
+		ret = append(ret, exception)
+	}
+	return ret
+}
+
+// Expired returns every exception that expired on or before now, sorted by name.
+func (r *Registry) Expired(now time.Time) []*Exception {
+	ret := []*Exception{}
+	for i := range r.Exceptions {
+		if r.Exceptions[i].ExpiredAt(now) {
+			ret = append(ret, &r.Exceptions[i])
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// MatchedNames returns the names of every exception that matched at least one of the intervals.
+func (r *Registry) MatchedNames(intervals monitorapi.Intervals) sets.Set[string] {
+	ret := sets.New[string]()
+	for i := range r.Exceptions {
+		exception := &r.Exceptions[i]
+		for _, interval := range intervals {
+			if exception.Matches(interval) {
+				ret.Insert(exception.Name)
+				break
+			}
+		}
+	}
+	return ret
+}
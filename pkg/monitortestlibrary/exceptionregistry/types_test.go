@@ -0,0 +1,187 @@
+package exceptionregistry
+
+import (
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	registry := Default()
+	require.NotEmpty(t, registry.Exceptions)
+
+	names := func(exceptions []*Exception) []string {
+		ret := []string{}
+		for _, e := range exceptions {
+			ret = append(ret, e.Name)
+		}
+		return ret
+	}
+	conformance := names(registry.For(AlertKind, ConformanceSuite, Environment{FeatureSet: configv1.Default}))
+	assert.Contains(t, conformance, "HighOverallControlPlaneCPU")
+	assert.Contains(t, conformance, "LokiTargetDown")
+	assert.NotContains(t, conformance, "EtcdMemberCommunicationSlowDuringUpgrade")
+	assert.NotContains(t, conformance, "TechPreviewNoUpgrade")
+
+	upgrade := names(registry.For(AlertKind, UpgradeSuite, Environment{FeatureSet: configv1.TechPreviewNoUpgrade}))
+	assert.Contains(t, upgrade, "EtcdMemberCommunicationSlowDuringUpgrade")
+	assert.Contains(t, upgrade, "LokiTargetDown")
+	assert.Contains(t, upgrade, "TechPreviewNoUpgrade")
+	assert.NotContains(t, upgrade, "HighOverallControlPlaneCPU")
+
+	stable := names(registry.For(PathologicalEventKind, ConformanceSuite, Environment{}))
+	assert.Contains(t, stable, "E2ELoki")
+	assert.NotContains(t, stable, "NetworkNotReady")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			content: `
+version: 1
+exceptions:
+- name: Loki
+  kind: PathologicalEvent
+  reason: loki
+  expires: 2024-01-31
+  event:
+    locatorKeyRegexes:
+      namespace: '^openshift-e2e-loki$'
+`,
+		},
+		{
+			name:        "wrong version",
+			content:     "version: 2\n",
+			expectedErr: "unsupported version 2",
+		},
+		{
+			name: "duplicate name",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: Alert, reason: a, alert: {alertName: A}}
+- {name: A, kind: Alert, reason: a, alert: {alertName: B}}
+`,
+			expectedErr: `exception "A" is defined more than once`,
+		},
+		{
+			name: "no reason or bug",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: Alert, alert: {alertName: A}}
+`,
+			expectedErr: "reason or bug is required",
+		},
+		{
+			name: "alert with event matcher",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: Alert, reason: a, event: {humanRegex: a}}
+`,
+			expectedErr: "alert exceptions must set alert",
+		},
+		{
+			name: "bad regex",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: PathologicalEvent, reason: a, event: {humanRegex: '('}}
+`,
+			expectedErr: "humanRegex",
+		},
+		{
+			name: "bad date",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: Alert, reason: a, expires: soon, alert: {alertName: A}}
+`,
+			expectedErr: "expires must be a date",
+		},
+		{
+			name: "unknown field",
+			content: `
+version: 1
+exceptions:
+- {name: A, kind: Alert, reason: a, alert: {alertName: A}, owner: me}
+`,
+			expectedErr: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if len(tt.expectedErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestExpiredAndMatched(t *testing.T) {
+	registry, err := Parse([]byte(`
+version: 1
+exceptions:
+- name: Loki
+  kind: PathologicalEvent
+  reason: loki
+  expires: 2024-01-31
+  event:
+    locatorKeyRegexes:
+      namespace: '^openshift-e2e-loki$'
+- name: SlowEtcd
+  kind: Alert
+  bug: https://issues.redhat.com/browse/OCPBUGS-1
+  scope:
+    suites: [upgrade]
+    platforms: [metal]
+  alert:
+    alertName: etcdMemberCommunicationSlow
+    states: [pending]
+`))
+	require.NoError(t, err)
+
+	assert.Empty(t, registry.Expired(time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)))
+	expired := registry.Expired(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	require.Len(t, expired, 1)
+	assert.Equal(t, "Loki", expired[0].Name)
+	assert.Len(t, registry.forTime(PathologicalEventKind, ConformanceSuite, Environment{}, time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)), 1)
+	assert.Empty(t, registry.forTime(PathologicalEventKind, ConformanceSuite, Environment{}, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)),
+		"expired exceptions no longer apply")
+
+	slowEtcd := &registry.Exceptions[1]
+	assert.True(t, slowEtcd.AppliesTo(UpgradeSuite, Environment{Platform: "metal"}))
+	assert.False(t, slowEtcd.AppliesTo(UpgradeSuite, Environment{Platform: "aws"}))
+	assert.False(t, slowEtcd.AppliesTo(UpgradeSuite, Environment{}))
+	assert.False(t, slowEtcd.AppliesTo(ConformanceSuite, Environment{Platform: "metal"}))
+
+	alert := func(name, state string) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceAlert, monitorapi.Warning).
+			Locator(monitorapi.Locator{Keys: map[monitorapi.LocatorKey]string{
+				monitorapi.LocatorAlertKey:     name,
+				monitorapi.LocatorNamespaceKey: "openshift-etcd",
+			}}).
+			Message(monitorapi.NewMessage().HumanMessage("alert").WithAnnotation(monitorapi.AnnotationAlertState, state)).
+			BuildNow()
+	}
+	assert.Empty(t, registry.MatchedNames(monitorapi.Intervals{alert("etcdMemberCommunicationSlow", "firing")}).UnsortedList())
+	assert.Equal(t, []string{"SlowEtcd"}, registry.MatchedNames(monitorapi.Intervals{
+		alert("etcdMemberCommunicationSlow", "pending"),
+		alert("KubePodNotReady", "pending"),
+	}).UnsortedList())
+}
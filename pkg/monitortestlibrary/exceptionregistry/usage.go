@@ -0,0 +1,92 @@
+package exceptionregistry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/openshift/origin/pkg/dataloader"
+)
+
+// UsageHit is how many times an exception excused an alert or repeating event during a run.
+type UsageHit struct {
+	Kind  Kind
+	Name  string
+	Bug   string
+	Count int
+}
+
+// Usage records every time an alert or repeating event was excused, so we can tell when a bug is fixed because its
+// exception stopped matching in CI, and delete stale exceptions safely.  Exceptions written in go are recorded
+// alongside the registry ones.
+type Usage struct {
+	lock sync.Mutex
+	hits map[string]*UsageHit
+}
+
+func NewUsage() *Usage {
+	return &Usage{hits: map[string]*UsageHit{}}
+}
+
+// Record counts one excused alert or event.  A nil Usage records nothing, so callers that do not report usage can
+// pass nil.
+func (u *Usage) Record(kind Kind, name, bug string) {
+	if u == nil {
+		return
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", kind, name)
+	hit, ok := u.hits[key]
+	if !ok {
+		hit = &UsageHit{Kind: kind, Name: name, Bug: bug}
+		u.hits[key] = hit
+	}
+	hit.Count++
+}
+
+// Hits returns every recorded hit sorted by kind and name.
+func (u *Usage) Hits() []UsageHit {
+	if u == nil {
+		return nil
+	}
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	ret := []UsageHit{}
+	for _, hit := range u.hits {
+		ret = append(ret, *hit)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Kind != ret[j].Kind {
+			return ret[i].Kind < ret[j].Kind
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// DataFile returns the hits for upload to bigquery.
+func (u *Usage) DataFile() dataloader.DataFile {
+	rows := []map[string]string{}
+	for _, hit := range u.Hits() {
+		rows = append(rows, map[string]string{
+			"Kind":  string(hit.Kind),
+			"Name":  hit.Name,
+			"Bug":   hit.Bug,
+			"Count": strconv.Itoa(hit.Count),
+		})
+	}
+	return dataloader.DataFile{
+		TableName: "exception_usage",
+		Schema: map[string]dataloader.DataType{
+			"Kind":  dataloader.DataTypeString,
+			"Name":  dataloader.DataTypeString,
+			"Bug":   dataloader.DataTypeString,
+			"Count": dataloader.DataTypeInteger,
+		},
+		Rows: rows,
+	}
+}
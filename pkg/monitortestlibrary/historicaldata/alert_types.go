@@ -87,29 +87,40 @@ func (b *AlertBestMatcher) bestMatch(key AlertDataKey) (AlertStatisticalData, st
 	logrus.WithField("alertName", key.AlertName).WithField("entries", len(b.HistoricalData)).
 		Debugf("searching for best match for %+v", key.JobType)
 
+	path := fallbackPath{}
 	if percentiles, ok := b.HistoricalData[exactMatchKey]; ok {
 		if percentiles.JobRuns >= defaultMinJobRuns {
 			logrus.Infof("found exact match: %+v", percentiles)
-			return percentiles, "", nil
-		}
-		percentiles, matchReason, err := b.evaluateBestGuesser(PreviousReleaseUpgrade, exactMatchKey)
-		if err != nil {
-			return AlertStatisticalData{}, "", err
-		}
-		if percentiles != (AlertStatisticalData{}) {
-			return percentiles, matchReason, nil
+			return percentiles, fmt.Sprintf("(exact match for %v with %d job runs)", alertKeyString(exactMatchKey), percentiles.JobRuns), nil
 		}
+		path = append(path, fmt.Sprintf("exact (%d job runs, need %d)", percentiles.JobRuns, defaultMinJobRuns))
+	} else {
+		path = append(path, "exact (no data)")
 	}
 
-	// tested in TestGetClosestP95Value in allowedbackendisruption.  Should get a local test at some point.
-	for _, nextBestGuesser := range nextBestGuessers {
-		percentiles, matchReason, err := b.evaluateBestGuesser(nextBestGuesser, exactMatchKey)
-		if err != nil {
-			return AlertStatisticalData{}, "", err
+	// tested in TestGetClosestP95Value in allowedbackendisruption and TestFallbackStrategies.
+	candidates := map[platformidentification.JobType]int64{}
+	for curr, percentiles := range b.HistoricalData {
+		if curr.AlertName == key.AlertName && curr.AlertNamespace == key.AlertNamespace && curr.AlertLevel == key.AlertLevel {
+			candidates[curr.JobType] = percentiles.JobRuns
 		}
-		if percentiles != (AlertStatisticalData{}) {
-			return percentiles, matchReason, nil
+	}
+	for _, strategy := range fallbackStrategies {
+		nextBestJobType, ok := bestCandidate(strategy, key.JobType, candidates, defaultMinJobRuns)
+		if !ok {
+			path = append(path, fmt.Sprintf("%s (no match)", strategy.Name))
+			continue
+		}
+		nextBestMatchKey := AlertDataKey{
+			AlertName:      exactMatchKey.AlertName,
+			AlertNamespace: exactMatchKey.AlertNamespace,
+			AlertLevel:     exactMatchKey.AlertLevel,
+
+			JobType: nextBestJobType,
 		}
+		percentiles := b.HistoricalData[nextBestMatchKey]
+		path = append(path, fmt.Sprintf("%s (%d job runs)", strategy.Name, percentiles.JobRuns))
+		return percentiles, fmt.Sprintf("(no exact match for %v, fell back to %v via %v)", alertKeyString(exactMatchKey), alertKeyString(nextBestMatchKey), path), nil
 	}
 
 	// TODO: ensure our core platforms are here, error if not. We need to be sure our aggregated jobs are running this
@@ -119,28 +130,10 @@ func (b *AlertBestMatcher) bestMatch(key AlertDataKey) (AlertStatisticalData, st
 	// determination. If we did not record historical data for this NURP combination, we do not wish to enforce
 	// disruption testing on a per job basis. Return an empty data result to signal we have no data, and skip the test.
 	return AlertStatisticalData{},
-		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v)", key.JobType),
+		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v via %v)", key.JobType, path),
 		nil
 }
 
-func (b *AlertBestMatcher) evaluateBestGuesser(nextBestGuesser NextBestKey, exactMatchKey AlertDataKey) (AlertStatisticalData, string, error) {
-	nextBestJobType, ok := nextBestGuesser(exactMatchKey.JobType)
-	if !ok {
-		return AlertStatisticalData{}, "", nil
-	}
-	nextBestMatchKey := AlertDataKey{
-		AlertName:      exactMatchKey.AlertName,
-		AlertNamespace: exactMatchKey.AlertNamespace,
-		AlertLevel:     exactMatchKey.AlertLevel,
-
-		JobType: nextBestJobType,
-	}
-	if percentiles, ok := b.HistoricalData[nextBestMatchKey]; ok && percentiles.JobRuns >= defaultMinJobRuns {
-		return percentiles, fmt.Sprintf("(no exact match for %#v, fell back to %#v)", exactMatchKey, nextBestMatchKey), nil
-	}
-	return AlertStatisticalData{}, "", nil
-}
-
 // BestMatchDuration returns the best possible match for this historical data.  It attempts an exact match first, then
 // it attempts to match on the most important keys in order, before giving up and returning an empty default,
 // which means to skip testing against this data.
@@ -0,0 +1,155 @@
+package historicaldata
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type AnomalyClassification string
+
+const (
+	AnomalyPass  AnomalyClassification = "Pass"
+	AnomalyFlake AnomalyClassification = "Flake"
+	AnomalyFail  AnomalyClassification = "Fail"
+)
+
+// AnomalyThresholds are the probabilities below which an observation is considered anomalous.  An observation that
+// fewer than FlakeProbability of historical job runs would reach flakes, fewer than FailProbability fails.
+type AnomalyThresholds struct {
+	FlakeProbability float64
+	FailProbability  float64
+}
+
+// DefaultAnomalyThresholds flake on a one in twenty observation and fail on a one in a hundred observation, which
+// lines up with the P95 and P99 used by the threshold based evaluators.
+var DefaultAnomalyThresholds = AnomalyThresholds{
+	FlakeProbability: 0.05,
+	FailProbability:  0.01,
+}
+
+// anomalyThresholds are used by every anomaly score of the run, so the junits and the recorded scores agree.
+var anomalyThresholds = DefaultAnomalyThresholds
+
+// SetAnomalyThresholds replaces the thresholds returned by GetAnomalyThresholds.  It must be called before any monitor
+// tests are evaluated.
+func SetAnomalyThresholds(thresholds AnomalyThresholds) error {
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+	anomalyThresholds = thresholds
+	return nil
+}
+
+// GetAnomalyThresholds returns the thresholds set by SetAnomalyThresholds, or DefaultAnomalyThresholds.
+func GetAnomalyThresholds() AnomalyThresholds {
+	return anomalyThresholds
+}
+
+func (t AnomalyThresholds) Validate() error {
+	if t.FailProbability <= 0 || t.FailProbability >= 1 {
+		return fmt.Errorf("fail probability must be between 0 and 1, got %v", t.FailProbability)
+	}
+	if t.FlakeProbability <= 0 || t.FlakeProbability >= 1 {
+		return fmt.Errorf("flake probability must be between 0 and 1, got %v", t.FlakeProbability)
+	}
+	if t.FailProbability > t.FlakeProbability {
+		return fmt.Errorf("fail probability %v must not be greater than flake probability %v", t.FailProbability, t.FlakeProbability)
+	}
+	return nil
+}
+
+// AnomalyScore describes how unusual an observation is compared to the historical distribution.
+type AnomalyScore struct {
+	ObservedSeconds float64
+	// PercentileRank is the fraction of historical job runs expected to observe less than ObservedSeconds.
+	PercentileRank float64
+	// ProbabilityAtLeast is the fraction of historical job runs expected to observe ObservedSeconds or more.
+	ProbabilityAtLeast float64
+	// ZScore is the standard normal quantile of PercentileRank, so 1.64 is a P95 and 2.33 is a P99.
+	ZScore         float64
+	Classification AnomalyClassification
+}
+
+func (s AnomalyScore) String() string {
+	return fmt.Sprintf("observed=%.0fs percentileRank=%.4f probabilityAtLeast=%.4f zScore=%.2f classification=%s",
+		s.ObservedSeconds, s.PercentileRank, s.ProbabilityAtLeast, s.ZScore, s.Classification)
+}
+
+// ScoreDisruption estimates the probability of observing at least the observed disruption given the historical
+// percentiles.  Between percentiles the distribution is linearly interpolated.  Above the P99 an exponential tail
+// is fitted through the P95 and P99, so the probability keeps falling rather than stopping at one percent.
+//
+// Disruption is measured at one second resolution, so one second is subtracted before scoring, matching the one
+// second the P99 evaluator always allows.  Observations up to anomalyFailFloor flake at most.
+func ScoreDisruption(historical StatisticalDuration, observed time.Duration, thresholds AnomalyThresholds) AnomalyScore {
+	observedSeconds := math.Round(observed.Seconds())
+	scored := math.Max(0, observedSeconds-1)
+
+	rank := percentileRank(historical, scored)
+	ret := AnomalyScore{
+		ObservedSeconds:    observedSeconds,
+		PercentileRank:     rank,
+		ProbabilityAtLeast: 1 - rank,
+		ZScore:             zScore(rank),
+		Classification:     AnomalyPass,
+	}
+	switch {
+	case ret.ProbabilityAtLeast < thresholds.FailProbability && observedSeconds > anomalyFailFloor.Seconds():
+		ret.Classification = AnomalyFail
+	case ret.ProbabilityAtLeast < thresholds.FlakeProbability:
+		ret.Classification = AnomalyFlake
+	}
+	return ret
+}
+
+// anomalyFailFloor is the disruption that never fails, only flakes, however improbable the history makes it.  A P99
+// near zero would otherwise fail a couple of seconds of disruption, which the P99 evaluator allows with its one
+// second minimum and five seconds of grace.
+const anomalyFailFloor = 6 * time.Second
+
+type quantile struct {
+	rank    float64
+	seconds float64
+}
+
+func percentileRank(historical StatisticalDuration, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+
+	// percentiles that were not recorded are zero, force the knots to be non-decreasing so interpolation holds.
+	knots := []quantile{
+		{rank: 0, seconds: 0},
+		{rank: 0.50, seconds: historical.P50.Seconds()},
+		{rank: 0.75, seconds: historical.P75.Seconds()},
+		{rank: 0.95, seconds: historical.P95.Seconds()},
+		{rank: 0.99, seconds: historical.P99.Seconds()},
+	}
+	for i := len(knots) - 2; i >= 0; i-- {
+		knots[i].seconds = math.Min(knots[i].seconds, knots[i+1].seconds)
+	}
+
+	p95, p99 := knots[3].seconds, knots[4].seconds
+	if seconds > p99 {
+		// exponential survival through S(P95)=0.05 and S(P99)=0.01.  With one second resolution a tail falling faster
+		// than one e-fold per second is not meaningful, which happens whenever P95 and P99 are equal.
+		scale := math.Max((p99-p95)/math.Log(5), 1)
+		return 1 - 0.01*math.Exp(-(seconds-p99)/scale)
+	}
+
+	for i := len(knots) - 1; i > 0; i-- {
+		lower, upper := knots[i-1], knots[i]
+		if seconds <= lower.seconds {
+			continue
+		}
+		return lower.rank + (upper.rank-lower.rank)*(seconds-lower.seconds)/(upper.seconds-lower.seconds)
+	}
+	return 0
+}
+
+func zScore(rank float64) float64 {
+	// keep the z-score finite, a rank of exactly 0 or 1 would be infinite.
+	clamped := math.Min(math.Max(rank, 1e-6), 1-1e-6)
+	return math.Sqrt2 * math.Erfinv(2*clamped-1)
+}
@@ -0,0 +1,93 @@
+package historicaldata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreDisruption(t *testing.T) {
+	historical := StatisticalDuration{
+		P50:     0,
+		P75:     2 * time.Second,
+		P95:     6 * time.Second,
+		P99:     10 * time.Second,
+		JobRuns: 500,
+	}
+	neverDisrupted := StatisticalDuration{
+		JobRuns: 500,
+	}
+
+	tests := []struct {
+		name                   string
+		historical             StatisticalDuration
+		observed               time.Duration
+		expectedRank           float64
+		expectedClassification AnomalyClassification
+	}{
+		{
+			name:                   "no disruption",
+			historical:             historical,
+			observed:               0,
+			expectedRank:           0,
+			expectedClassification: AnomalyPass,
+		},
+		{
+			name:                   "between P75 and P95",
+			historical:             historical,
+			observed:               5 * time.Second,
+			expectedRank:           0.85,
+			expectedClassification: AnomalyPass,
+		},
+		{
+			name:                   "between P95 and P99 flakes",
+			historical:             historical,
+			observed:               9 * time.Second,
+			expectedRank:           0.97,
+			expectedClassification: AnomalyFlake,
+		},
+		{
+			name:                   "above P99 fails",
+			historical:             historical,
+			observed:               13 * time.Second,
+			expectedRank:           0.9955,
+			expectedClassification: AnomalyFail,
+		},
+		{
+			name:                   "one second is always allowed",
+			historical:             neverDisrupted,
+			observed:               1 * time.Second,
+			expectedRank:           0,
+			expectedClassification: AnomalyPass,
+		},
+		{
+			name:                   "never disrupted only flakes up to the floor",
+			historical:             neverDisrupted,
+			observed:               6 * time.Second,
+			expectedRank:           0.99993,
+			expectedClassification: AnomalyFlake,
+		},
+		{
+			name:                   "never disrupted fails above the floor",
+			historical:             neverDisrupted,
+			observed:               7 * time.Second,
+			expectedRank:           0.99998,
+			expectedClassification: AnomalyFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := ScoreDisruption(tt.historical, tt.observed, DefaultAnomalyThresholds)
+			assert.InDelta(t, tt.expectedRank, actual.PercentileRank, 0.0001, actual.String())
+			assert.InDelta(t, 1-tt.expectedRank, actual.ProbabilityAtLeast, 0.0001)
+			assert.Equal(t, tt.expectedClassification, actual.Classification, actual.String())
+		})
+	}
+}
+
+func TestAnomalyThresholdsValidate(t *testing.T) {
+	assert.NoError(t, DefaultAnomalyThresholds.Validate())
+	assert.Error(t, AnomalyThresholds{FlakeProbability: 0.01, FailProbability: 0.05}.Validate())
+	assert.Error(t, AnomalyThresholds{FlakeProbability: 1.5, FailProbability: 0.01}.Validate())
+}
@@ -30,14 +30,16 @@ type StatisticalDuration struct {
 }
 
 type DisruptionStatisticalData struct {
-	DataKey       `json:",inline"`
-	P50           float64
-	P75           float64
-	P95           float64
-	P99           float64
-	FirstObserved time.Time
-	LastObserved  time.Time
-	JobRuns       int64
+	DataKey `json:",inline"`
+	// MasterNodesUpdated is not part of the key, it is carried through so refreshes of the embedded data keep it.
+	MasterNodesUpdated string
+	P50                float64
+	P75                float64
+	P95                float64
+	P99                float64
+	FirstObserved      time.Time
+	LastObserved       time.Time
+	JobRuns            int64
 }
 
 type DataKey struct {
@@ -57,10 +59,13 @@ func NewDisruptionMatcher(historicalJSON []byte) (*DisruptionBestMatcher, error)
 	jsonDecoder := json.NewDecoder(inFile)
 
 	type DecodingPercentile struct {
-		DataKey `json:",inline"`
-		P95     string
-		P99     string
-		JobRuns int64
+		DataKey            `json:",inline"`
+		MasterNodesUpdated string
+		P50                string
+		P75                string
+		P95                string
+		P99                string
+		JobRuns            int64
 	}
 	decodingPercentilesList := []DecodingPercentile{}
 
@@ -77,11 +82,23 @@ func NewDisruptionMatcher(historicalJSON []byte) (*DisruptionBestMatcher, error)
 		if err != nil {
 			return nil, err
 		}
+		// P50 and P75 are only used for anomaly scoring and older data may not have them.
+		p50, err := parseOptionalFloat(currDecoded.P50)
+		if err != nil {
+			return nil, err
+		}
+		p75, err := parseOptionalFloat(currDecoded.P75)
+		if err != nil {
+			return nil, err
+		}
 		curr := DisruptionStatisticalData{
-			DataKey: currDecoded.DataKey,
-			P95:     p95,
-			P99:     p99,
-			JobRuns: currDecoded.JobRuns,
+			DataKey:            currDecoded.DataKey,
+			MasterNodesUpdated: currDecoded.MasterNodesUpdated,
+			P50:                p50,
+			P75:                p75,
+			P95:                p95,
+			P99:                p99,
+			JobRuns:            currDecoded.JobRuns,
 		}
 		historicalData[curr.DataKey] = curr
 	}
@@ -104,29 +121,39 @@ func (b *DisruptionBestMatcher) bestMatch(name string, jobType platformidentific
 	}
 	logrus.WithField("backend", name).Infof("searching for bestMatch for %+v", jobType)
 	logrus.Infof("historicalData has %d entries", len(b.HistoricalData))
+	path := fallbackPath{}
 	if percentiles, ok := b.HistoricalData[exactMatchKey]; ok {
 		if percentiles.JobRuns >= int64(minJobRuns) {
 			logrus.Infof("found exact match: %+v", percentiles)
-			return percentiles, "", nil
+			return percentiles, fmt.Sprintf("(exact match for %v with %d job runs)", disruptionKeyString(exactMatchKey), percentiles.JobRuns), nil
 		}
+		path = append(path, fmt.Sprintf("exact (%d job runs, need %d)", percentiles.JobRuns, minJobRuns))
+	} else {
+		path = append(path, "exact (no data)")
+	}
 
-		percentiles, matchReason, err := b.evaluateBestGuesser(name, PreviousReleaseUpgrade, exactMatchKey, jobType)
-		if err != nil {
-			return DisruptionStatisticalData{}, "", err
-		}
-		if percentiles != (DisruptionStatisticalData{}) {
-			return percentiles, matchReason, nil
+	// tested in TestGetClosestP99Value in allowedbackendisruption and TestFallbackStrategies.
+	candidates := map[platformidentification.JobType]int64{}
+	for key, percentiles := range b.HistoricalData {
+		if key.BackendName == name {
+			candidates[key.JobType] = percentiles.JobRuns
 		}
 	}
-	// tested in TestGetClosestP99Value in allowedbackendisruption.  Should get a local test at some point.
-	for _, nextBestGuesser := range nextBestGuessers {
-		percentiles, matchReason, err := b.evaluateBestGuesser(name, nextBestGuesser, exactMatchKey, jobType)
-		if err != nil {
-			return DisruptionStatisticalData{}, "", err
+	for _, strategy := range fallbackStrategies {
+		nextBestJobType, ok := bestCandidate(strategy, jobType, candidates, defaultMinJobRuns)
+		if !ok {
+			path = append(path, fmt.Sprintf("%s (no match)", strategy.Name))
+			continue
 		}
-		if percentiles != (DisruptionStatisticalData{}) {
-			return percentiles, matchReason, nil
+		nextBestMatchKey := DataKey{
+			BackendName: name,
+			JobType:     nextBestJobType,
 		}
+		percentiles := b.HistoricalData[nextBestMatchKey]
+		path = append(path, fmt.Sprintf("%s (%d job runs)", strategy.Name, percentiles.JobRuns))
+		logrus.Infof("no exact match fell back to %#v", nextBestMatchKey)
+		logrus.Infof("found inexact match: %+v", percentiles)
+		return percentiles, fmt.Sprintf("(no exact match for %v, fell back to %v via %v)", disruptionKeyString(exactMatchKey), disruptionKeyString(nextBestMatchKey), path), nil
 	}
 
 	logrus.Warn("no exact or fuzzy match, no results will be returned, test will be skipped")
@@ -138,27 +165,10 @@ func (b *DisruptionBestMatcher) bestMatch(name string, jobType platformidentific
 	// determination. If we did not record historical data for this NURP combination, we do not wish to enforce
 	// disruption testing on a per job basis. Return an empty data result to signal we have no data, and skip the test.
 	return DisruptionStatisticalData{},
-		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v)", jobType),
+		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v via %v)", jobType, path),
 		nil
 }
 
-func (b *DisruptionBestMatcher) evaluateBestGuesser(name string, nextBestGuesser NextBestKey, exactMatchKey DataKey, jobType platformidentification.JobType) (DisruptionStatisticalData, string, error) {
-	nextBestJobType, ok := nextBestGuesser(jobType)
-	if !ok {
-		return DisruptionStatisticalData{}, "", nil
-	}
-	nextBestMatchKey := DataKey{
-		BackendName: name,
-		JobType:     nextBestJobType,
-	}
-	if percentiles, ok := b.HistoricalData[nextBestMatchKey]; ok && percentiles.JobRuns > defaultMinJobRuns {
-		logrus.Infof("no exact match fell back to %#v", nextBestMatchKey)
-		logrus.Infof("found inexact match: %+v", percentiles)
-		return percentiles, fmt.Sprintf("(no exact match for %#v, fell back to %#v)", exactMatchKey, nextBestMatchKey), nil
-	}
-	return DisruptionStatisticalData{}, "", nil
-}
-
 // BestMatchDuration returns the best possible match for this historical data.  It attempts an exact match first, then
 // it attempts to match on the most important keys in order, before giving up and returning an empty default,
 // which means to skip testing against this data.
@@ -179,6 +189,12 @@ func (b *DisruptionBestMatcher) BestMatchP99(name string, jobType platformidenti
 	return &rawData.P99, details, err
 }
 
+// BestMatchDistribution returns the full set of percentiles for the best match, requiring the default number of job
+// runs.  It is empty when there is no sufficient match.
+func (b *DisruptionBestMatcher) BestMatchDistribution(name string, jobType platformidentification.JobType) (StatisticalDuration, string, error) {
+	return b.BestMatchDuration(name, jobType, defaultMinJobRuns)
+}
+
 func toStatisticalDuration(in DisruptionStatisticalData) StatisticalDuration {
 	return StatisticalDuration{
 		JobType:       in.DataKey.JobType,
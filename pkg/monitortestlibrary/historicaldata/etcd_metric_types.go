@@ -0,0 +1,51 @@
+package historicaldata
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// EtcdMetricDataKey identifies the percentiles, across job runs, of the worst P99 latency a single run saw for an etcd
+// metric.
+type EtcdMetricDataKey struct {
+	MetricName string
+
+	platformidentification.JobType `json:",inline"`
+}
+
+func (k EtcdMetricDataKey) String() string {
+	return fmt.Sprintf("metric=%s release=%s from=%s platform=%s arch=%s network=%s topology=%s",
+		k.MetricName, k.Release, k.FromRelease, k.Platform, k.Architecture, k.Network, k.Topology)
+}
+
+func (k EtcdMetricDataKey) jobType() platformidentification.JobType {
+	return k.JobType
+}
+
+func (k EtcdMetricDataKey) withJobType(jobType platformidentification.JobType) EtcdMetricDataKey {
+	k.JobType = jobType
+	return k
+}
+
+type EtcdMetricBestMatcher = PercentileBestMatcher[EtcdMetricDataKey]
+
+func NewEtcdMetricMatcher(historicalJSON []byte) (*EtcdMetricBestMatcher, error) {
+	return newPercentileMatcher[EtcdMetricDataKey](historicalJSON)
+}
+
+func NewEtcdMetricMatcherWithHistoricalData(data map[EtcdMetricDataKey]Percentiles) *EtcdMetricBestMatcher {
+	return &EtcdMetricBestMatcher{
+		HistoricalData: data,
+	}
+}
+
+// IngestEtcdMetricData validates exported rows and returns the statistical data for each etcd metric and job type.
+func IngestEtcdMetricData(rows []ExportedRow) (map[EtcdMetricDataKey]Percentiles, error) {
+	return ingestPercentiles(EtcdMetricDataKind, rows, func(row ExportedRow) EtcdMetricDataKey {
+		return EtcdMetricDataKey{
+			MetricName: row["MetricName"],
+			JobType:    jobTypeFromRow(row),
+		}
+	})
+}
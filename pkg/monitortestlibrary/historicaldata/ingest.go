@@ -0,0 +1,368 @@
+package historicaldata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// DataKind identifies which embedded query_results.json a set of historical data belongs to.
+type DataKind string
+
+const (
+	DisruptionDataKind DataKind = "disruption"
+	AlertDataKind      DataKind = "alerts"
+	EtcdMetricDataKind DataKind = "etcd-metrics"
+
+	MachineConfigRolloutDataKind DataKind = "machine-config-rollout"
+)
+
+// ExportedRow is a single row of a BigQuery export, keyed by column name.
+type ExportedRow map[string]string
+
+var (
+	releaseRegex = regexp.MustCompile(`^\d+\.\d+$`)
+
+	jobTypeColumns = []string{"Release", "FromRelease", "Platform", "Architecture", "Network", "Topology"}
+
+	disruptionKeyColumns = append([]string{"BackendName"}, jobTypeColumns...)
+	alertKeyColumns      = append([]string{"AlertName", "AlertNamespace", "AlertLevel"}, jobTypeColumns...)
+	etcdMetricKeyColumns = append([]string{"MetricName"}, jobTypeColumns...)
+	rolloutKeyColumns    = append([]string{"PoolName"}, jobTypeColumns...)
+
+	// optionalKeyColumns may be empty.  FromRelease is empty for jobs that do not upgrade.
+	optionalKeyColumns = map[string]bool{
+		"FromRelease":    true,
+		"AlertNamespace": true,
+	}
+)
+
+// observationColumn returns the column holding one observation per job run for raw (not yet aggregated) exports.
+func (k DataKind) observationColumn() string {
+	switch k {
+	case AlertDataKind:
+		return "AlertSeconds"
+	case EtcdMetricDataKind:
+		return "WorstP99Seconds"
+	case MachineConfigRolloutDataKind:
+		return "NodeRolloutSeconds"
+	default:
+		return "DisruptionSeconds"
+	}
+}
+
+func (k DataKind) keyColumns() []string {
+	switch k {
+	case AlertDataKind:
+		return alertKeyColumns
+	case EtcdMetricDataKind:
+		return etcdMetricKeyColumns
+	case MachineConfigRolloutDataKind:
+		return rolloutKeyColumns
+	default:
+		return disruptionKeyColumns
+	}
+}
+
+// ReadExportedRows reads a BigQuery export in either csv (with a header row) or json (an array of objects) format.
+func ReadExportedRows(in io.Reader, format string) ([]ExportedRow, error) {
+	switch format {
+	case "csv":
+		reader := csv.NewReader(in)
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, fmt.Errorf("missing csv header")
+		}
+		header := records[0]
+		rows := []ExportedRow{}
+		for _, record := range records[1:] {
+			row := ExportedRow{}
+			for i, column := range header {
+				row[column] = strings.TrimSpace(record[i])
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+
+	case "json":
+		decoded := []map[string]interface{}{}
+		if err := json.NewDecoder(in).Decode(&decoded); err != nil {
+			return nil, err
+		}
+		rows := []ExportedRow{}
+		for _, curr := range decoded {
+			row := ExportedRow{}
+			for column, value := range curr {
+				switch v := value.(type) {
+				case nil:
+					row[column] = ""
+				case string:
+					row[column] = strings.TrimSpace(v)
+				case float64:
+					row[column] = strconv.FormatFloat(v, 'f', -1, 64)
+				default:
+					row[column] = fmt.Sprintf("%v", v)
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+}
+
+// Percentiles is the aggregated data for a single key.
+type Percentiles struct {
+	P50     float64
+	P75     float64
+	P95     float64
+	P99     float64
+	JobRuns int64
+}
+
+// aggregatedRows holds the percentiles for each key along with the first row seen, which is used to construct the key.
+type aggregatedRows struct {
+	keyRow      map[string]ExportedRow
+	percentiles map[string]Percentiles
+}
+
+// aggregate validates the rows against the schema for the kind and computes percentiles.  Rows either carry
+// pre-computed percentiles (P95, P99, and JobRuns columns) or one observation per job run, in which case the
+// percentiles are calculated here the same way BigQuery's PERCENTILE_CONT does.
+func aggregate(kind DataKind, rows []ExportedRow) (*aggregatedRows, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no rows found")
+	}
+
+	_, preAggregated := rows[0]["P99"]
+	requiredColumns := append([]string{}, kind.keyColumns()...)
+	if preAggregated {
+		requiredColumns = append(requiredColumns, "P95", "P99", "JobRuns")
+	} else {
+		requiredColumns = append(requiredColumns, kind.observationColumn())
+	}
+
+	errs := []string{}
+	ret := &aggregatedRows{
+		keyRow:      map[string]ExportedRow{},
+		percentiles: map[string]Percentiles{},
+	}
+	observations := map[string][]float64{}
+	for i, row := range rows {
+		if rowErrs := validateRow(row, requiredColumns); len(rowErrs) > 0 {
+			for _, err := range rowErrs {
+				errs = append(errs, fmt.Sprintf("row %d: %s", i+1, err))
+			}
+			continue
+		}
+
+		key := rowKey(kind, row)
+		if _, ok := ret.keyRow[key]; !ok {
+			ret.keyRow[key] = row
+		}
+
+		if !preAggregated {
+			value, _ := strconv.ParseFloat(row[kind.observationColumn()], 64)
+			observations[key] = append(observations[key], value)
+			continue
+		}
+
+		if _, ok := ret.percentiles[key]; ok {
+			errs = append(errs, fmt.Sprintf("row %d: duplicate key %s", i+1, key))
+			continue
+		}
+		curr := Percentiles{}
+		curr.P50, _ = parseOptionalFloat(row["P50"])
+		curr.P75, _ = parseOptionalFloat(row["P75"])
+		curr.P95, _ = strconv.ParseFloat(row["P95"], 64)
+		curr.P99, _ = strconv.ParseFloat(row["P99"], 64)
+		curr.JobRuns, _ = strconv.ParseInt(row["JobRuns"], 10, 64)
+		ret.percentiles[key] = curr
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %s data:\n%s", kind, strings.Join(errs, "\n"))
+	}
+
+	for key, values := range observations {
+		sort.Float64s(values)
+		ret.percentiles[key] = Percentiles{
+			P50:     percentileCont(values, 0.50),
+			P75:     percentileCont(values, 0.75),
+			P95:     percentileCont(values, 0.95),
+			P99:     percentileCont(values, 0.99),
+			JobRuns: int64(len(values)),
+		}
+	}
+
+	return ret, nil
+}
+
+func validateRow(row ExportedRow, requiredColumns []string) []string {
+	errs := []string{}
+	for _, column := range requiredColumns {
+		value, ok := row[column]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("missing column %q", column))
+		case len(value) == 0 && !optionalKeyColumns[column]:
+			errs = append(errs, fmt.Sprintf("empty value for %q", column))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if !releaseRegex.MatchString(row["Release"]) {
+		errs = append(errs, fmt.Sprintf("Release %q is not of the form X.Y", row["Release"]))
+	}
+	if len(row["FromRelease"]) > 0 && !releaseRegex.MatchString(row["FromRelease"]) {
+		errs = append(errs, fmt.Sprintf("FromRelease %q is not of the form X.Y", row["FromRelease"]))
+	}
+	for _, column := range []string{"P50", "P75", "P95", "P99", "DisruptionSeconds", "AlertSeconds", "WorstP99Seconds", "NodeRolloutSeconds"} {
+		if _, err := parseOptionalFloat(row[column]); err != nil {
+			errs = append(errs, fmt.Sprintf("%s %q is not a number", column, row[column]))
+		}
+	}
+	if jobRuns, ok := row["JobRuns"]; ok {
+		if _, err := strconv.ParseInt(jobRuns, 10, 64); err != nil {
+			errs = append(errs, fmt.Sprintf("JobRuns %q is not an integer", jobRuns))
+		}
+	}
+	return errs
+}
+
+func parseOptionalFloat(in string) (float64, error) {
+	if len(in) == 0 {
+		return 0, nil
+	}
+	ret, err := strconv.ParseFloat(in, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(ret) || math.IsInf(ret, 0) || ret < 0 {
+		return 0, fmt.Errorf("%v is not a valid duration in seconds", ret)
+	}
+	return ret, nil
+}
+
+func rowKey(kind DataKind, row ExportedRow) string {
+	values := []string{}
+	for _, column := range kind.keyColumns() {
+		values = append(values, fmt.Sprintf("%s=%s", column, row[column]))
+	}
+	return strings.Join(values, ",")
+}
+
+// percentileCont returns the linearly interpolated percentile of sorted values, matching BigQuery's PERCENTILE_CONT.
+func percentileCont(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := percentile * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func jobTypeFromRow(row ExportedRow) platformidentification.JobType {
+	return platformidentification.JobType{
+		Release:      row["Release"],
+		FromRelease:  row["FromRelease"],
+		Platform:     row["Platform"],
+		Architecture: row["Architecture"],
+		Network:      row["Network"],
+		Topology:     row["Topology"],
+	}
+}
+
+// IngestDisruptionData validates exported rows and returns the statistical data for each backend and job type.
+func IngestDisruptionData(rows []ExportedRow) (map[DataKey]DisruptionStatisticalData, error) {
+	aggregated, err := aggregate(DisruptionDataKind, rows)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[DataKey]DisruptionStatisticalData{}
+	for key, percentiles := range aggregated.percentiles {
+		row := aggregated.keyRow[key]
+		dataKey := DataKey{
+			BackendName: row["BackendName"],
+			JobType:     jobTypeFromRow(row),
+		}
+		ret[dataKey] = DisruptionStatisticalData{
+			DataKey:            dataKey,
+			MasterNodesUpdated: row["MasterNodesUpdated"],
+			P50:                percentiles.P50,
+			P75:                percentiles.P75,
+			P95:                percentiles.P95,
+			P99:                percentiles.P99,
+			JobRuns:            percentiles.JobRuns,
+		}
+	}
+	return ret, nil
+}
+
+// IngestAlertData validates exported rows and returns the statistical data for each alert and job type.
+func IngestAlertData(rows []ExportedRow) (map[AlertDataKey]AlertStatisticalData, error) {
+	aggregated, err := aggregate(AlertDataKind, rows)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[AlertDataKey]AlertStatisticalData{}
+	for key, percentiles := range aggregated.percentiles {
+		row := aggregated.keyRow[key]
+		dataKey := AlertDataKey{
+			AlertName:      row["AlertName"],
+			AlertNamespace: row["AlertNamespace"],
+			AlertLevel:     row["AlertLevel"],
+			JobType:        jobTypeFromRow(row),
+		}
+		ret[dataKey] = AlertStatisticalData{
+			AlertDataKey: dataKey,
+			P50:          percentiles.P50,
+			P75:          percentiles.P75,
+			P95:          percentiles.P95,
+			P99:          percentiles.P99,
+			JobRuns:      percentiles.JobRuns,
+		}
+	}
+	return ret, nil
+}
+
+// DetectDataKind inspects a query_results.json and reports which kind of historical data it contains.
+func DetectDataKind(historicalJSON []byte) (DataKind, error) {
+	entries := []map[string]interface{}{}
+	if err := json.Unmarshal(historicalJSON, &entries); err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no entries found")
+	}
+	switch {
+	case entries[0]["BackendName"] != nil:
+		return DisruptionDataKind, nil
+	case entries[0]["AlertName"] != nil:
+		return AlertDataKind, nil
+	case entries[0]["MetricName"] != nil:
+		return EtcdMetricDataKind, nil
+	case entries[0]["PoolName"] != nil:
+		return MachineConfigRolloutDataKind, nil
+	default:
+		return "", fmt.Errorf("entries have none of BackendName, AlertName, MetricName, or PoolName")
+	}
+}
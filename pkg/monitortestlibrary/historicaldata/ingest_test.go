@@ -0,0 +1,154 @@
+package historicaldata
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestDisruptionDataFromObservations(t *testing.T) {
+	csvData := `BackendName,Release,FromRelease,Platform,Architecture,Network,Topology,DisruptionSeconds
+kube-api-new-connections,4.18,4.17,aws,amd64,ovn,ha,0
+kube-api-new-connections,4.18,4.17,aws,amd64,ovn,ha,1
+kube-api-new-connections,4.18,4.17,aws,amd64,ovn,ha,2
+kube-api-new-connections,4.18,4.17,aws,amd64,ovn,ha,10
+kube-api-new-connections,4.18,,gcp,amd64,ovn,ha,3
+`
+	rows, err := ReadExportedRows(strings.NewReader(csvData), "csv")
+	require.NoError(t, err)
+
+	data, err := IngestDisruptionData(rows)
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	aws := data[DataKey{
+		BackendName: "kube-api-new-connections",
+		JobType: platformidentification.JobType{
+			Release: "4.18", FromRelease: "4.17", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha",
+		},
+	}]
+	assert.Equal(t, int64(4), aws.JobRuns)
+	assert.InDelta(t, 1.5, aws.P50, 0.0001)
+	assert.InDelta(t, 4.0, aws.P75, 0.0001)
+	assert.InDelta(t, 8.8, aws.P95, 0.0001)
+	assert.InDelta(t, 9.76, aws.P99, 0.0001)
+}
+
+func TestIngestRejectsInvalidRows(t *testing.T) {
+	jsonData := `[
+  {"AlertName": "KubeAPIErrorBudgetBurn", "AlertNamespace": "", "AlertLevel": "critical", "Release": "4.18", "FromRelease": "", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": 1, "P99": "2", "JobRuns": 200},
+  {"AlertName": "KubeAPIErrorBudgetBurn", "AlertNamespace": "", "AlertLevel": "critical", "Release": "latest", "FromRelease": "", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": 1, "P99": "fast", "JobRuns": 200},
+  {"AlertName": "", "AlertNamespace": "", "AlertLevel": "critical", "Release": "4.18", "FromRelease": "", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": 1, "P99": 2, "JobRuns": 200}
+]`
+	rows, err := ReadExportedRows(strings.NewReader(jsonData), "json")
+	require.NoError(t, err)
+
+	_, err = IngestAlertData(rows)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `row 2: Release "latest" is not of the form X.Y`)
+	assert.Contains(t, err.Error(), `row 2: P99 "fast" is not a number`)
+	assert.Contains(t, err.Error(), `row 3: empty value for "AlertName"`)
+}
+
+func TestDisruptionDataRoundTrip(t *testing.T) {
+	jobType := platformidentification.JobType{Release: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	unchanged := DataKey{BackendName: "ingress-to-console-new-connections", JobType: jobType}
+	updated := DataKey{BackendName: "kube-api-new-connections", JobType: jobType}
+	removed := DataKey{BackendName: "cache-kube-api-new-connections", JobType: jobType}
+	added := DataKey{BackendName: "openshift-api-new-connections", JobType: jobType}
+
+	oldData := map[DataKey]DisruptionStatisticalData{
+		unchanged: {DataKey: unchanged, P95: 1, P99: 2, JobRuns: 100},
+		updated:   {DataKey: updated, P95: 1, P99: 2, JobRuns: 100},
+		removed:   {DataKey: removed, P95: 1, P99: 2, JobRuns: 100},
+	}
+	newData := map[DataKey]DisruptionStatisticalData{
+		unchanged: {DataKey: unchanged, P95: 1, P99: 2, JobRuns: 100},
+		updated:   {DataKey: updated, MasterNodesUpdated: "Y", P95: 1.5, P99: 3, JobRuns: 120},
+		added:     {DataKey: added, P95: 0.5, P99: 1, JobRuns: 150},
+	}
+
+	changes := DiffDisruptionData(oldData, newData)
+	require.Len(t, changes, 3)
+	assert.Equal(t, ChangeRemoved, changes[0].Change)
+	assert.Equal(t, ChangeUpdated, changes[1].Change)
+	assert.Equal(t, ChangeAdded, changes[2].Change)
+	assert.Equal(t, "1 added, 1 removed, 1 updated", SummarizeChanges(changes))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, WriteDisruptionQueryResults(out, newData))
+	kind, err := DetectDataKind(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, DisruptionDataKind, kind)
+
+	matcher, err := NewDisruptionMatcher(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, matcher.HistoricalData[updated].P99)
+	assert.Equal(t, int64(120), matcher.HistoricalData[updated].JobRuns)
+	assert.Equal(t, "Y", matcher.HistoricalData[updated].MasterNodesUpdated)
+}
+
+func TestEtcdMetricDataRoundTrip(t *testing.T) {
+	csvData := `MetricName,Release,FromRelease,Platform,Architecture,Network,Topology,WorstP99Seconds
+etcd_disk_wal_fsync_duration_seconds,4.18,,azure,amd64,ovn,ha,0.008
+etcd_disk_wal_fsync_duration_seconds,4.18,,azure,amd64,ovn,ha,0.012
+etcd_disk_wal_fsync_duration_seconds,4.18,,azure,amd64,ovn,ha,0.04
+`
+	rows, err := ReadExportedRows(strings.NewReader(csvData), "csv")
+	require.NoError(t, err)
+	data, err := IngestEtcdMetricData(rows)
+	require.NoError(t, err)
+	key := EtcdMetricDataKey{
+		MetricName: "etcd_disk_wal_fsync_duration_seconds",
+		JobType:    platformidentification.JobType{Release: "4.18", Platform: "azure", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+	}
+	require.Contains(t, data, key)
+	assert.Equal(t, int64(3), data[key].JobRuns)
+	assert.InDelta(t, 0.012, data[key].P50, 0.0001)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, WritePercentileQueryResults(out, data))
+	kind, err := DetectDataKind(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, EtcdMetricDataKind, kind)
+
+	matcher, err := NewEtcdMetricMatcher(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, data[key], matcher.HistoricalData[key])
+	assert.Empty(t, DiffPercentileData(matcher.HistoricalData, data))
+}
+
+// A pool is its own series: a custom pool never borrows the durations of the worker pool it was copied from.
+func TestMachineConfigRolloutDataPerPool(t *testing.T) {
+	jsonData := `[
+  {"PoolName": "worker", "Release": "4.18", "FromRelease": "4.17", "Platform": "metal", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "900", "P99": "1500", "JobRuns": 400},
+  {"PoolName": "infra", "Release": "4.17", "FromRelease": "4.16", "Platform": "metal", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P95": "600", "P99": "700", "JobRuns": 30}
+]`
+	rows, err := ReadExportedRows(strings.NewReader(jsonData), "json")
+	require.NoError(t, err)
+	data, err := IngestMachineConfigRolloutData(rows)
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, WritePercentileQueryResults(out, data))
+	assert.Less(t, strings.Index(out.String(), `"PoolName": "infra"`), strings.Index(out.String(), `"PoolName": "worker"`), "entries are sorted by key")
+	kind, err := DetectDataKind(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, MachineConfigRolloutDataKind, kind)
+
+	matcher, err := NewMachineConfigRolloutMatcher(out.Bytes())
+	require.NoError(t, err)
+	upgrade := platformidentification.JobType{Release: "4.18", FromRelease: "4.17", Platform: "metal", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	worker, _, err := matcher.BestMatch(MachineConfigRolloutDataKey{PoolName: "worker", JobType: upgrade})
+	require.NoError(t, err)
+	assert.Equal(t, float64(1500), worker.P99)
+
+	infra, details, err := matcher.BestMatch(MachineConfigRolloutDataKey{PoolName: "infra", JobType: upgrade})
+	require.NoError(t, err)
+	assert.Zero(t, infra, "too few infra job runs, and the worker pool is not a fallback")
+	assert.Contains(t, details, "no exact or fuzzy match for pool=infra")
+}
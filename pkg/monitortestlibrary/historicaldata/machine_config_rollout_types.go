@@ -0,0 +1,51 @@
+package historicaldata
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// MachineConfigRolloutDataKey identifies the percentiles of how long a single node in a pool took to roll out a
+// machine config, from MachineConfigChange to MachineConfigReached.
+type MachineConfigRolloutDataKey struct {
+	PoolName string
+
+	platformidentification.JobType `json:",inline"`
+}
+
+func (k MachineConfigRolloutDataKey) String() string {
+	return fmt.Sprintf("pool=%s release=%s from=%s platform=%s arch=%s network=%s topology=%s",
+		k.PoolName, k.Release, k.FromRelease, k.Platform, k.Architecture, k.Network, k.Topology)
+}
+
+func (k MachineConfigRolloutDataKey) jobType() platformidentification.JobType {
+	return k.JobType
+}
+
+func (k MachineConfigRolloutDataKey) withJobType(jobType platformidentification.JobType) MachineConfigRolloutDataKey {
+	k.JobType = jobType
+	return k
+}
+
+type MachineConfigRolloutBestMatcher = PercentileBestMatcher[MachineConfigRolloutDataKey]
+
+func NewMachineConfigRolloutMatcher(historicalJSON []byte) (*MachineConfigRolloutBestMatcher, error) {
+	return newPercentileMatcher[MachineConfigRolloutDataKey](historicalJSON)
+}
+
+func NewMachineConfigRolloutMatcherWithHistoricalData(data map[MachineConfigRolloutDataKey]Percentiles) *MachineConfigRolloutBestMatcher {
+	return &MachineConfigRolloutBestMatcher{
+		HistoricalData: data,
+	}
+}
+
+// IngestMachineConfigRolloutData validates exported rows and returns the statistical data for each pool and job type.
+func IngestMachineConfigRolloutData(rows []ExportedRow) (map[MachineConfigRolloutDataKey]Percentiles, error) {
+	return ingestPercentiles(MachineConfigRolloutDataKind, rows, func(row ExportedRow) MachineConfigRolloutDataKey {
+		return MachineConfigRolloutDataKey{
+			PoolName: row["PoolName"],
+			JobType:  jobTypeFromRow(row),
+		}
+	})
+}
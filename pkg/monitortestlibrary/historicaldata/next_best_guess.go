@@ -9,12 +9,137 @@ import (
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 )
 
-// nextBestGuessers is the order in which to attempt to lookup other alternative matches that are close to this job type.
-var nextBestGuessers = []NextBestKey{
+// fallbackStrategies is the order in which to attempt to lookup other alternative matches that are close to this job type.
+// It defaults to falling back to the previous release only and may be changed with SetFallbackStrategies.
+var fallbackStrategies = DefaultFallbackStrategies()
+
+// DefaultFallbackStrategies returns the fallbacks used unless others are configured.
+func DefaultFallbackStrategies() []FallbackStrategy {
 	// The only guesser we try not is falling back to previous release. Otherwise if we don't have enough data, we don't
 	// run the test. This was implemented after finding that we fail every attempt at a fallback.
 	// Continuing with previous release helps us in the transition between major releases, so we kept this fallback.
-	PreviousReleaseUpgrade,
+	return []FallbackStrategy{
+		PreviousReleaseFallback,
+	}
+}
+
+// FallbackStrategy is one step in the chain of next best guesses tried when the exact JobType has too few job runs.
+type FallbackStrategy struct {
+	// Name identifies the strategy in --historical-data-fallback and in the fallback path reported in junit.
+	Name string
+	// IsCandidate returns true if historical data recorded for candidate may be used in place of in.
+	IsCandidate func(in, candidate platformidentification.JobType) bool
+}
+
+var (
+	// PreviousReleaseFallback uses the congruent job on the prior release, see PreviousReleaseUpgrade.
+	PreviousReleaseFallback = FallbackFromNextBestKey("previous-release", PreviousReleaseUpgrade)
+
+	// DifferentNetworkFallback uses the same platform and topology with any other network.
+	DifferentNetworkFallback = FallbackStrategy{
+		Name: "different-network",
+		IsCandidate: func(in, candidate platformidentification.JobType) bool {
+			relaxed := platformidentification.CloneJobType(in)
+			relaxed.Network = candidate.Network
+			return candidate.Network != in.Network && relaxed == candidate
+		},
+	}
+
+	// AnyPlatformFallback uses the same topology on any other platform.  This gives new platforms like external and
+	// nutanix reasonable thresholds until they have enough job runs of their own.
+	AnyPlatformFallback = FallbackStrategy{
+		Name: "any-platform",
+		IsCandidate: func(in, candidate platformidentification.JobType) bool {
+			relaxed := platformidentification.CloneJobType(in)
+			relaxed.Platform = candidate.Platform
+			return candidate.Platform != in.Platform && relaxed == candidate
+		},
+	}
+
+	// RelaxArchitectureFallback uses the same job on any other architecture.
+	RelaxArchitectureFallback = FallbackStrategy{
+		Name: "relax-architecture",
+		IsCandidate: func(in, candidate platformidentification.JobType) bool {
+			relaxed := platformidentification.CloneJobType(in)
+			relaxed.Architecture = candidate.Architecture
+			return candidate.Architecture != in.Architecture && relaxed == candidate
+		},
+	}
+
+	// AllFallbackStrategies is every known strategy, in the order they are most useful when all are enabled.
+	AllFallbackStrategies = []FallbackStrategy{
+		DifferentNetworkFallback,
+		AnyPlatformFallback,
+		PreviousReleaseFallback,
+		RelaxArchitectureFallback,
+	}
+)
+
+// FallbackFromNextBestKey adapts a NextBestKey to a FallbackStrategy.
+func FallbackFromNextBestKey(name string, nextBestKey NextBestKey) FallbackStrategy {
+	return FallbackStrategy{
+		Name: name,
+		IsCandidate: func(in, candidate platformidentification.JobType) bool {
+			nextBestJobType, ok := nextBestKey(in)
+			return ok && nextBestJobType == candidate
+		},
+	}
+}
+
+// SetFallbackStrategies replaces the fallback chain with the named strategies, tried in the order given.  It must be
+// called before any historical data is matched.
+func SetFallbackStrategies(names []string) error {
+	known := map[string]FallbackStrategy{}
+	for _, strategy := range AllFallbackStrategies {
+		known[strategy.Name] = strategy
+	}
+
+	strategies := []FallbackStrategy{}
+	for _, name := range names {
+		strategy, ok := known[name]
+		if !ok {
+			return fmt.Errorf("unknown historical data fallback %q, expected one of %s", name, strings.Join(FallbackStrategyNames(), ", "))
+		}
+		strategies = append(strategies, strategy)
+	}
+	fallbackStrategies = strategies
+	return nil
+}
+
+// FallbackStrategyNames returns the names of every known strategy.
+func FallbackStrategyNames() []string {
+	names := []string{}
+	for _, strategy := range AllFallbackStrategies {
+		names = append(names, strategy.Name)
+	}
+	return names
+}
+
+// bestCandidate returns the candidate accepted by the strategy with the most job runs.  candidates maps every JobType
+// with historical data for the backend or alert being matched to its number of job runs.
+func bestCandidate(strategy FallbackStrategy, in platformidentification.JobType, candidates map[platformidentification.JobType]int64, minJobRuns int64) (platformidentification.JobType, bool) {
+	var best platformidentification.JobType
+	var bestJobRuns int64
+	found := false
+	for candidate, jobRuns := range candidates {
+		if jobRuns < minJobRuns || !strategy.IsCandidate(in, candidate) {
+			continue
+		}
+		// ties are broken on the string form so repeated runs pick the same fallback.
+		if !found || jobRuns > bestJobRuns || (jobRuns == bestJobRuns && fmt.Sprintf("%v", candidate) < fmt.Sprintf("%v", best)) {
+			best = candidate
+			bestJobRuns = jobRuns
+			found = true
+		}
+	}
+	return best, found
+}
+
+// fallbackPath records each step taken while looking for a match so it can be reported in junit.
+type fallbackPath []string
+
+func (p fallbackPath) String() string {
+	return strings.Join(p, " -> ")
 }
 
 // NextBestKey returns the next best key in the query_results.json generated from BigQuery and a bool indicating whether this guesser has an opinion.
@@ -1,6 +1,13 @@
 package historicaldata
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func TestCurrentReleaseFromMap(t *testing.T) {
 	// Test case: Empty input map
@@ -42,3 +49,80 @@ func TestCompareReleaseString(t *testing.T) {
 		t.Errorf("Expected true, but got false")
 	}
 }
+
+func TestFallbackStrategies(t *testing.T) {
+	defer func() {
+		fallbackStrategies = DefaultFallbackStrategies()
+	}()
+
+	nutanix := platformidentification.JobType{
+		Release: "4.18", FromRelease: "4.17", Platform: "nutanix", Architecture: "amd64", Network: "ovn", Topology: "ha",
+	}
+	withPlatform := func(platform string) platformidentification.JobType {
+		ret := platformidentification.CloneJobType(nutanix)
+		ret.Platform = platform
+		return ret
+	}
+	withNetwork := func(network string) platformidentification.JobType {
+		ret := platformidentification.CloneJobType(nutanix)
+		ret.Network = network
+		return ret
+	}
+	matcher := NewDisruptionMatcherWithHistoricalData(map[DataKey]DisruptionStatisticalData{})
+	addData := func(jobType platformidentification.JobType, jobRuns int64, p99 float64) {
+		key := DataKey{BackendName: "kube-api-new-connections", JobType: jobType}
+		matcher.HistoricalData[key] = DisruptionStatisticalData{DataKey: key, P99: p99, JobRuns: jobRuns}
+	}
+	addData(nutanix, 12, 1)
+	addData(withPlatform("aws"), 500, 2)
+	addData(withPlatform("gcp"), 300, 3)
+	addData(withPlatform("azure"), 500, 4)
+	addData(withNetwork("sdn"), 50, 5)
+
+	tests := []struct {
+		name            string
+		strategies      []string
+		expectedP99     float64
+		expectedDetails []string
+	}{
+		{
+			name:            "default has no data",
+			expectedDetails: []string{"exact (12 job runs, need 100) -> previous-release (no match)"},
+		},
+		{
+			name:            "network has too few runs",
+			strategies:      []string{"different-network"},
+			expectedDetails: []string{"different-network (no match)"},
+		},
+		{
+			name:        "any platform picks the most job runs, ties broken by key",
+			strategies:  []string{"different-network", "previous-release", "any-platform"},
+			expectedP99: 2,
+			expectedDetails: []string{
+				"platform=aws",
+				"exact (12 job runs, need 100) -> different-network (no match) -> previous-release (no match) -> any-platform (500 job runs)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallbackStrategies = DefaultFallbackStrategies()
+			if tt.strategies != nil {
+				require.NoError(t, SetFallbackStrategies(tt.strategies))
+			}
+			actual, details, err := matcher.BestMatchP99("kube-api-new-connections", nutanix)
+			require.NoError(t, err)
+			if tt.expectedP99 == 0 {
+				assert.Nil(t, actual)
+			} else {
+				require.NotNil(t, actual)
+				assert.Equal(t, DurationOrDie(tt.expectedP99), *actual)
+			}
+			for _, expected := range tt.expectedDetails {
+				assert.True(t, strings.Contains(details, expected), "expected %q in %q", expected, details)
+			}
+		})
+	}
+
+	assert.Error(t, SetFallbackStrategies([]string{"any-cloud"}))
+}
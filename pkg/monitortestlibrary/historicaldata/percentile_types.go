@@ -0,0 +1,182 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
+)
+
+// percentileKey is the key of historical data made of a single series, such as an etcd metric or a machine config
+// pool, and a job type.  Keys are decoded from, and written as, the columns of a query_results.json entry.
+type percentileKey[K any] interface {
+	comparable
+	fmt.Stringer
+
+	jobType() platformidentification.JobType
+	// withJobType returns the key of the same series for another job type.
+	withJobType(platformidentification.JobType) K
+}
+
+// PercentileBestMatcher finds the historical percentiles of a series for a job type.  Values are in seconds.
+type PercentileBestMatcher[K percentileKey[K]] struct {
+	HistoricalData map[K]Percentiles
+}
+
+func newPercentileMatcher[K percentileKey[K]](historicalJSON []byte) (*PercentileBestMatcher[K], error) {
+	entries := []json.RawMessage{}
+	if err := json.Unmarshal(historicalJSON, &entries); err != nil {
+		return nil, err
+	}
+
+	type DecodingPercentile struct {
+		P50     string
+		P75     string
+		P95     string
+		P99     string
+		JobRuns int64
+	}
+	historicalData := map[K]Percentiles{}
+	for _, entry := range entries {
+		var key K
+		if err := json.Unmarshal(entry, &key); err != nil {
+			return nil, err
+		}
+		currDecoded := DecodingPercentile{}
+		if err := json.Unmarshal(entry, &currDecoded); err != nil {
+			return nil, fmt.Errorf("%v: %w", key, err)
+		}
+
+		curr := Percentiles{JobRuns: currDecoded.JobRuns}
+		for _, percentile := range []struct {
+			name  string
+			value string
+			into  *float64
+		}{
+			{name: "P50", value: currDecoded.P50, into: &curr.P50},
+			{name: "P75", value: currDecoded.P75, into: &curr.P75},
+			{name: "P95", value: currDecoded.P95, into: &curr.P95},
+			{name: "P99", value: currDecoded.P99, into: &curr.P99},
+		} {
+			parsed, err := strconv.ParseFloat(percentile.value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s of %v: %w", percentile.name, key, err)
+			}
+			*percentile.into = parsed
+		}
+		historicalData[key] = curr
+	}
+
+	return &PercentileBestMatcher[K]{
+		HistoricalData: historicalData,
+	}, nil
+}
+
+// BestMatch returns the best possible match for this historical data.  It attempts an exact match first, then
+// falls back through the same strategies as disruption and alerts, before giving up and returning an empty default,
+// which means to skip testing against this data.
+func (b *PercentileBestMatcher[K]) BestMatch(key K) (Percentiles, string, error) {
+	logrus.WithField("key", key.String()).WithField("entries", len(b.HistoricalData)).
+		Debug("searching for best match")
+
+	path := fallbackPath{}
+	if percentiles, ok := b.HistoricalData[key]; ok {
+		if percentiles.JobRuns >= defaultMinJobRuns {
+			return percentiles, fmt.Sprintf("(exact match for %v with %d job runs)", key, percentiles.JobRuns), nil
+		}
+		path = append(path, fmt.Sprintf("exact (%d job runs, need %d)", percentiles.JobRuns, defaultMinJobRuns))
+	} else {
+		path = append(path, "exact (no data)")
+	}
+
+	candidates := map[platformidentification.JobType]int64{}
+	for curr, percentiles := range b.HistoricalData {
+		if curr.withJobType(key.jobType()) == key {
+			candidates[curr.jobType()] = percentiles.JobRuns
+		}
+	}
+	for _, strategy := range fallbackStrategies {
+		nextBestJobType, ok := bestCandidate(strategy, key.jobType(), candidates, defaultMinJobRuns)
+		if !ok {
+			path = append(path, fmt.Sprintf("%s (no match)", strategy.Name))
+			continue
+		}
+		nextBestMatchKey := key.withJobType(nextBestJobType)
+		percentiles := b.HistoricalData[nextBestMatchKey]
+		path = append(path, fmt.Sprintf("%s (%d job runs)", strategy.Name, percentiles.JobRuns))
+		return percentiles, fmt.Sprintf("(no exact match for %v, fell back to %v via %v)", key, nextBestMatchKey, path), nil
+	}
+
+	return Percentiles{}, fmt.Sprintf("(no exact or fuzzy match for %v via %v)", key, path), nil
+}
+
+// ingestPercentiles validates exported rows and returns the percentiles of each key, built from its first row.
+func ingestPercentiles[K percentileKey[K]](kind DataKind, rows []ExportedRow, keyFromRow func(ExportedRow) K) (map[K]Percentiles, error) {
+	aggregated, err := aggregate(kind, rows)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[K]Percentiles{}
+	for key, percentiles := range aggregated.percentiles {
+		ret[keyFromRow(aggregated.keyRow[key])] = percentiles
+	}
+	return ret, nil
+}
+
+// WritePercentileQueryResults writes data in the format of the embedded query_results.json, with the columns of the
+// key followed by the percentiles, sorted by key so that refreshes produce minimal diffs.
+func WritePercentileQueryResults[K percentileKey[K]](out io.Writer, data map[K]Percentiles) error {
+	keys := make([]K, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	results := []json.RawMessage{}
+	for _, key := range keys {
+		curr := data[key]
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		percentilesJSON, err := json.Marshal(struct {
+			JobRuns int64
+			P95     string
+			P99     string
+			P75     string
+			P50     string
+		}{
+			JobRuns: curr.JobRuns,
+			P95:     formatSeconds(curr.P95),
+			P99:     formatSeconds(curr.P99),
+			P75:     formatSeconds(curr.P75),
+			P50:     formatSeconds(curr.P50),
+		})
+		if err != nil {
+			return err
+		}
+		// both are objects, so joining them gives a single entry with every column
+		result := append(keyJSON[:len(keyJSON)-1], ',')
+		results = append(results, append(result, percentilesJSON[1:]...))
+	}
+	return writeIndentedJSON(out, results)
+}
+
+// DiffPercentileData reports every key that was added, removed, or whose P95, P99, or job run count changed.
+func DiffPercentileData[K percentileKey[K]](oldData, newData map[K]Percentiles) []DataChange {
+	oldPercentiles := map[string]Percentiles{}
+	for key, curr := range oldData {
+		oldPercentiles[key.String()] = curr
+	}
+	newPercentiles := map[string]Percentiles{}
+	for key, curr := range newData {
+		newPercentiles[key.String()] = curr
+	}
+	return diffPercentiles(oldPercentiles, newPercentiles)
+}
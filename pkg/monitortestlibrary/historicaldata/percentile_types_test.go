@@ -0,0 +1,42 @@
+package historicaldata
+
+import (
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileBestMatcher(t *testing.T) {
+	matcher, err := NewEtcdMetricMatcher([]byte(`[
+	{"MetricName": "etcd_disk_wal_fsync_duration_seconds", "Release": "4.18", "FromRelease": "", "Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P50": "0.004", "P75": "0.006", "P95": "0.009", "P99": "0.012", "JobRuns": 250},
+	{"MetricName": "etcd_disk_wal_fsync_duration_seconds", "Release": "4.17", "FromRelease": "", "Platform": "gcp", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P50": "0.005", "P75": "0.007", "P95": "0.01", "P99": "0.02", "JobRuns": 150},
+	{"MetricName": "etcd_disk_backend_commit_duration_seconds", "Release": "4.18", "FromRelease": "", "Platform": "gcp", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "P50": "0.01", "P75": "0.015", "P95": "0.02", "P99": "0.03", "JobRuns": 20}
+]`))
+	require.NoError(t, err)
+	require.Len(t, matcher.HistoricalData, 3)
+
+	aws := platformidentification.JobType{Release: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	gcp := platformidentification.CloneJobType(aws)
+	gcp.Platform = "gcp"
+
+	data, details, err := matcher.BestMatch(EtcdMetricDataKey{MetricName: "etcd_disk_wal_fsync_duration_seconds", JobType: aws})
+	require.NoError(t, err)
+	assert.Equal(t, 0.012, data.P99)
+	assert.Equal(t, 0.004, data.P50)
+	assert.Contains(t, details, "exact match")
+
+	data, details, err = matcher.BestMatch(EtcdMetricDataKey{MetricName: "etcd_disk_wal_fsync_duration_seconds", JobType: gcp})
+	require.NoError(t, err)
+	assert.Equal(t, 0.02, data.P99)
+	assert.Contains(t, details, "previous-release (150 job runs)")
+
+	data, details, err = matcher.BestMatch(EtcdMetricDataKey{MetricName: "etcd_disk_backend_commit_duration_seconds", JobType: gcp})
+	require.NoError(t, err)
+	assert.Equal(t, Percentiles{}, data)
+	assert.Contains(t, details, "exact (20 job runs, need 100)")
+
+	_, err = NewEtcdMetricMatcher([]byte(`[{"MetricName": "etcd_disk_wal_fsync_duration_seconds", "P50": "fast"}]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// disruptionQueryResult is a single entry of allowedbackenddisruption/query_results.json.  Percentiles are strings
+// to match what the BigQuery export produces and what NewDisruptionMatcher decodes.
+type disruptionQueryResult struct {
+	DataKey            `json:",inline"`
+	MasterNodesUpdated string
+	JobRuns            int64
+	P95                string
+	P99                string
+	P75                string
+	P50                string
+}
+
+// alertQueryResult is a single entry of allowedalerts/query_results.json.
+type alertQueryResult struct {
+	AlertDataKey `json:",inline"`
+	JobRuns      int64
+	P95          string
+	P99          string
+	P75          string
+	P50          string
+}
+
+func formatSeconds(in float64) string {
+	return strconv.FormatFloat(in, 'f', -1, 64)
+}
+
+// WriteDisruptionQueryResults writes data in the format of the embedded query_results.json, sorted by key so that
+// refreshes produce minimal diffs.
+func WriteDisruptionQueryResults(out io.Writer, data map[DataKey]DisruptionStatisticalData) error {
+	results := []disruptionQueryResult{}
+	for _, curr := range data {
+		results = append(results, disruptionQueryResult{
+			DataKey:            curr.DataKey,
+			MasterNodesUpdated: curr.MasterNodesUpdated,
+			JobRuns:            curr.JobRuns,
+			P95:                formatSeconds(curr.P95),
+			P99:                formatSeconds(curr.P99),
+			P75:                formatSeconds(curr.P75),
+			P50:                formatSeconds(curr.P50),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return disruptionKeyString(results[i].DataKey) < disruptionKeyString(results[j].DataKey)
+	})
+	return writeIndentedJSON(out, results)
+}
+
+// WriteAlertQueryResults writes data in the format of the embedded query_results.json, sorted by key so that
+// refreshes produce minimal diffs.
+func WriteAlertQueryResults(out io.Writer, data map[AlertDataKey]AlertStatisticalData) error {
+	results := []alertQueryResult{}
+	for _, curr := range data {
+		results = append(results, alertQueryResult{
+			AlertDataKey: curr.AlertDataKey,
+			JobRuns:      curr.JobRuns,
+			P95:          formatSeconds(curr.P95),
+			P99:          formatSeconds(curr.P99),
+			P75:          formatSeconds(curr.P75),
+			P50:          formatSeconds(curr.P50),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return alertKeyString(results[i].AlertDataKey) < alertKeyString(results[j].AlertDataKey)
+	})
+	return writeIndentedJSON(out, results)
+}
+
+func writeIndentedJSON(out io.Writer, obj interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(obj)
+}
+
+func disruptionKeyString(key DataKey) string {
+	return fmt.Sprintf("backend=%s release=%s from=%s platform=%s arch=%s network=%s topology=%s",
+		key.BackendName, key.Release, key.FromRelease, key.Platform, key.Architecture, key.Network, key.Topology)
+}
+
+func alertKeyString(key AlertDataKey) string {
+	return fmt.Sprintf("alert=%s namespace=%s level=%s release=%s from=%s platform=%s arch=%s network=%s topology=%s",
+		key.AlertName, key.AlertNamespace, key.AlertLevel,
+		key.Release, key.FromRelease, key.Platform, key.Architecture, key.Network, key.Topology)
+}
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "Added"
+	ChangeRemoved ChangeType = "Removed"
+	ChangeUpdated ChangeType = "Updated"
+)
+
+// DataChange describes how the historical data for a single key changed between two data sets.
+type DataChange struct {
+	Key    string
+	Change ChangeType
+
+	Old *Percentiles `json:",omitempty"`
+	New *Percentiles `json:",omitempty"`
+}
+
+func (c DataChange) String() string {
+	switch c.Change {
+	case ChangeAdded:
+		return fmt.Sprintf("%s %s: P95=%s P99=%s JobRuns=%d", c.Change, c.Key, formatSeconds(c.New.P95), formatSeconds(c.New.P99), c.New.JobRuns)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s %s: P95=%s P99=%s JobRuns=%d", c.Change, c.Key, formatSeconds(c.Old.P95), formatSeconds(c.Old.P99), c.Old.JobRuns)
+	default:
+		return fmt.Sprintf("%s %s: P95=%s->%s P99=%s->%s JobRuns=%d->%d", c.Change, c.Key,
+			formatSeconds(c.Old.P95), formatSeconds(c.New.P95),
+			formatSeconds(c.Old.P99), formatSeconds(c.New.P99),
+			c.Old.JobRuns, c.New.JobRuns)
+	}
+}
+
+// DiffDisruptionData reports every key that was added, removed, or whose P95, P99, or job run count changed.
+func DiffDisruptionData(oldData, newData map[DataKey]DisruptionStatisticalData) []DataChange {
+	oldPercentiles := map[string]Percentiles{}
+	for key, curr := range oldData {
+		oldPercentiles[disruptionKeyString(key)] = Percentiles{P50: curr.P50, P75: curr.P75, P95: curr.P95, P99: curr.P99, JobRuns: curr.JobRuns}
+	}
+	newPercentiles := map[string]Percentiles{}
+	for key, curr := range newData {
+		newPercentiles[disruptionKeyString(key)] = Percentiles{P50: curr.P50, P75: curr.P75, P95: curr.P95, P99: curr.P99, JobRuns: curr.JobRuns}
+	}
+	return diffPercentiles(oldPercentiles, newPercentiles)
+}
+
+// DiffAlertData reports every key that was added, removed, or whose P95, P99, or job run count changed.
+func DiffAlertData(oldData, newData map[AlertDataKey]AlertStatisticalData) []DataChange {
+	oldPercentiles := map[string]Percentiles{}
+	for key, curr := range oldData {
+		oldPercentiles[alertKeyString(key)] = Percentiles{P50: curr.P50, P75: curr.P75, P95: curr.P95, P99: curr.P99, JobRuns: curr.JobRuns}
+	}
+	newPercentiles := map[string]Percentiles{}
+	for key, curr := range newData {
+		newPercentiles[alertKeyString(key)] = Percentiles{P50: curr.P50, P75: curr.P75, P95: curr.P95, P99: curr.P99, JobRuns: curr.JobRuns}
+	}
+	return diffPercentiles(oldPercentiles, newPercentiles)
+}
+
+func diffPercentiles(oldPercentiles, newPercentiles map[string]Percentiles) []DataChange {
+	changes := []DataChange{}
+	for key, oldCurr := range oldPercentiles {
+		oldCurr := oldCurr
+		newCurr, ok := newPercentiles[key]
+		switch {
+		case !ok:
+			changes = append(changes, DataChange{Key: key, Change: ChangeRemoved, Old: &oldCurr})
+		// P50 and P75 are not decoded from the embedded alert data, so only compare what the matchers use.
+		case oldCurr.P95 != newCurr.P95 || oldCurr.P99 != newCurr.P99 || oldCurr.JobRuns != newCurr.JobRuns:
+			changes = append(changes, DataChange{Key: key, Change: ChangeUpdated, Old: &oldCurr, New: &newCurr})
+		}
+	}
+	for key, newCurr := range newPercentiles {
+		newCurr := newCurr
+		if _, ok := oldPercentiles[key]; !ok {
+			changes = append(changes, DataChange{Key: key, Change: ChangeAdded, New: &newCurr})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+// SummarizeChanges returns a one line count of each type of change.
+func SummarizeChanges(changes []DataChange) string {
+	counts := map[ChangeType]int{}
+	for _, change := range changes {
+		counts[change.Change]++
+	}
+	parts := []string{}
+	for _, changeType := range []ChangeType{ChangeAdded, ChangeRemoved, ChangeUpdated} {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[changeType], strings.ToLower(string(changeType))))
+	}
+	return strings.Join(parts, ", ")
+}
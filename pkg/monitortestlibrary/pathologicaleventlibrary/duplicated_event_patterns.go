@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	v1 "github.com/openshift/api/config/v1"
@@ -17,6 +18,8 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 )
 
 const (
@@ -129,6 +132,61 @@ func (ade *SimplePathologicalEventMatcher) Allows(i monitorapi.Interval, topolog
 	return true
 }
 
+func newSimplePathologicalEventMatcherFromException(exception *exceptionregistry.Exception) *SimplePathologicalEventMatcher {
+	return &SimplePathologicalEventMatcher{
+		name:                    exception.Name,
+		locatorKeyRegexes:       exception.LocatorKeyRegexes(),
+		messageReasonRegex:      exception.ReasonRegex(),
+		messageHumanRegex:       exception.HumanRegex(),
+		jira:                    exception.Bug,
+		repeatThresholdOverride: exception.Event.RepeatThresholdOverride,
+		neverAllow:              exception.Event.NeverAllow,
+	}
+}
+
+var (
+	clusterEnvironmentsLock sync.Mutex
+	// clusterEnvironments caches the environment of each cluster by API server, the registry is built by several
+	// monitor tests and the cluster does not change which exceptions apply to it during a run.
+	clusterEnvironments = map[string]exceptionregistry.Environment{}
+)
+
+// clusterEnvironment determines which scoped exceptions apply to the cluster.  Without a cluster, only exceptions
+// that are not scoped on feature set, platform, or topology apply.
+func clusterEnvironment(kubeConfig *rest.Config) exceptionregistry.Environment {
+	if kubeConfig == nil {
+		return exceptionregistry.Environment{}
+	}
+	clusterEnvironmentsLock.Lock()
+	defer clusterEnvironmentsLock.Unlock()
+	if env, ok := clusterEnvironments[kubeConfig.Host]; ok {
+		return env
+	}
+	env := lookupClusterEnvironment(kubeConfig)
+	clusterEnvironments[kubeConfig.Host] = env
+	return env
+}
+
+func lookupClusterEnvironment(kubeConfig *rest.Config) exceptionregistry.Environment {
+	jobType, err := platformidentification.GetJobType(context.TODO(), kubeConfig)
+	if err != nil {
+		logrus.WithError(err).Warning("unable to determine job type for pathological event exceptions")
+	}
+	featureSet := v1.Default
+	configClient, err := configclient.NewForConfig(kubeConfig)
+	if err != nil {
+		logrus.WithError(err).Warning("unable to determine feature set for pathological event exceptions")
+		return exceptionregistry.NewEnvironment(featureSet, jobType)
+	}
+	featureGate, err := configClient.ConfigV1().FeatureGates().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		logrus.WithError(err).Warning("unable to determine feature set for pathological event exceptions")
+	} else {
+		featureSet = featureGate.Spec.FeatureSet
+	}
+	return exceptionregistry.NewEnvironment(featureSet, jobType)
+}
+
 type AllowedPathologicalEventRegistry struct {
 	matchers map[string]EventMatcher
 }
@@ -194,11 +252,23 @@ func (r *AllowedPathologicalEventRegistry) GetMatcherByName(name string) (EventM
 	return matcher, nil
 }
 
-// AllowedPathologicalEvents is the list of all allowed duplicate events on all jobs. Upgrade has an additional
-// list which is combined with this one.
+// NewUniversalPathologicalEventMatchers creates the registry for allowed events on jobs that did not upgrade. Upgrade
+// has an additional list which is combined with this one.
 func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalIntervals monitorapi.Intervals) *AllowedPathologicalEventRegistry {
+	return newPathologicalEventMatchers(kubeConfig, finalIntervals, exceptionregistry.ConformanceSuite)
+}
+
+// newPathologicalEventMatchers registers the pathological event exceptions in scope for the suite, followed by the
+// matchers written in go because they need the cluster or other intervals.
+func newPathologicalEventMatchers(kubeConfig *rest.Config, finalIntervals monitorapi.Intervals, suite exceptionregistry.Suite) *AllowedPathologicalEventRegistry {
 	registry := &AllowedPathologicalEventRegistry{matchers: map[string]EventMatcher{}}
 
+	// Most simple matchers are declared in the exception registry, which is shared with the allowed alerts.
+	env := clusterEnvironment(kubeConfig)
+	for _, exception := range exceptionregistry.Default().For(exceptionregistry.PathologicalEventKind, suite, env) {
+		registry.AddPathologicalEventMatcherOrDie(newSimplePathologicalEventMatcherFromException(exception))
+	}
+
 	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should not deadlock when a pod's predecessor fails [Suite:openshift/conformance/parallel] [Suite:k8s]
 	// PauseNewPods intentionally causes readiness probe to fail.
 	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should perform rolling updates and roll backs of template modifications [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]
@@ -269,19 +339,6 @@ func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalInterva
 		})
 	*/
 
-	// Security Context ** should not run with an explicit root user ID
-	// Security Context ** should not run without a specified user ID
-	// This container should never run
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2ESecurityContextBreaksNonRootPolicy",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-security-context-test-[0-9]+`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`.*-root-uid`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^Failed$`),
-		messageHumanRegex:  regexp.MustCompile(`Error: container's runAsUser breaks non-root policy.*`),
-	})
-
 	// PersistentVolumes-local tests should not run the pod when there is a volume node
 	// affinity and node selector conflicts.
 	/*
@@ -297,66 +354,6 @@ func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalInterva
 		})
 	*/
 
-	// various DeploymentConfig tests trigger this by cancelling multiple rollouts
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "DeploymentAwaitingCancellation",
-		messageReasonRegex: regexp.MustCompile(`^DeploymentAwaitingCancellation$`),
-		messageHumanRegex:  regexp.MustCompile(`Deployment of version [0-9]+ awaiting cancellation of older running deployments`),
-	})
-
-	// If image pulls in e2e namespaces fail catastrophically we'd expect them to lead to test failures
-	// We are deliberately not ignoring image pull failures for core component namespaces
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2EImagePullBackOff",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^e2e-.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^BackOff$`),
-		messageHumanRegex:  regexp.MustCompile(`Back-off pulling image`),
-	})
-
-	// Several allowances were related to Loki, I think we can generally ignore any repeating event
-	// from the Loki NS, this should not fail tests.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2ELoki",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^openshift-e2e-loki$`),
-		},
-	})
-
-	// kube apiserver, controller-manager and scheduler guard pod probes can fail due to operands getting rolled out
-	// multiple times during the bootstrapping phase of a cluster installation
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "KubeAPIReadinessProbeError",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-kube-*`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`kube.*guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ProbeError$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe error`),
-	})
-
-	// this is the less specific even sent by the kubelet when a probe was executed successfully but returned false
-	// we ignore this event because openshift has a patch in patch_prober that sends a more specific event about
-	// readiness failures in openshift-* namespaces.  We will catch the more specific ProbeError events.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "KubeletUnhealthyReadinessProbeFailed",
-		messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe failed`),
-	})
-
-	// Managed services osd-cluster-ready will fail until the OSD operators are ready, this triggers pathological events
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "OSDClusterReadyRestart",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^openshift-monitoring`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`.*osd-cluster-ready.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^BackOff$`),
-		messageHumanRegex:  regexp.MustCompile(`Back-off restarting failed container.*osd-cluster-ready.*`),
-	})
-
 	/*
 
 			This looks duplicated with AllowBackOffRestartingFailedContainer
@@ -374,77 +371,6 @@ func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalInterva
 		})
 	*/
 
-	// If you see this error, it means enough was working to get this event which implies enough retries happened to allow initial openshift
-	// installation to succeed. Hence, we can ignore it.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "AWSFailedCreateInsufficientInstanceCapacity",
-		messageReasonRegex: regexp.MustCompile(`^FailedCreate$`),
-		messageHumanRegex:  regexp.MustCompile(`error creating EC2 instance: InsufficientInstanceCapacity: We currently do not have sufficient .* capacity in the Availability Zone you requested`),
-	})
-
-	// This was originally filed as a bug in 2021, closed as fixed, but the events continue repeating in 2023.
-	// They only occur in the namespace for a specific horizontal pod autoscaling test. Ignoring permanently,
-	// as they have been for the past two years.
-	// https://bugzilla.redhat.com/show_bug.cgi?id=1993985
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "PodAutoscalerFailedToGetCPUUtilization",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`horizontalpodautoscaler`),
-		},
-		messageHumanRegex: regexp.MustCompile(`failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API`),
-	})
-
-	// Formerly bug: https://bugzilla.redhat.com/show_bug.cgi?id=2075204
-	// Left stale and closed automatically. Assuming we can live with it now.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdReadinessProbeError",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-etcd`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`etcd-guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ProbeError$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe error: .* connect: connection refused`),
-	})
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "OpenShiftAPICheckFailed",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(``),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(``),
-		},
-		messageReasonRegex: regexp.MustCompile(`^OpenShiftAPICheckFailed$`),
-		messageHumanRegex:  regexp.MustCompile(`user.openshift.io.v1.*503`),
-		// TODO: Jira long closed as stale, and this problem occurs well outside single node now.
-		// A new bug should probably be filed.
-		jira: "https://bugzilla.redhat.com/show_bug.cgi?id=2017435",
-	})
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:              "MessageChangedFromFEFF",
-		messageHumanRegex: regexp.MustCompile(`message changed from "\\ufeff`),
-	})
-
-	// This was originally intended to be limited to only during the openshift/build test suite, however it was
-	// never hooked up and was just ignored everywhere. We do not have the capability to detect if
-	// events were within specific test suites yet. Leaving them as an always allow for now.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "ScalingReplicaSet",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`(openshift-controller-manager|openshift-route-controller-manager)`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`(controller-manager|route-controller-manager)`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ScalingReplicaSet$`),
-		messageHumanRegex:  regexp.MustCompile(`\(combined from similar events\): Scaled (down|up) replica set.*controller-manager-[a-z0-9-]+ to [0-9]+`),
-	})
-
-	// Match pod sandbox errors as "interesting" so they get charted, but we do not ever allow them to repeat
-	// pathologically.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:              "PodSandbox",
-		messageHumanRegex: regexp.MustCompile(`pod sandbox`),
-		neverAllow:        true,
-	})
-
 	registry.AddPathologicalEventMatcherOrDie(AllowBackOffRestartingFailedContainer)
 
 	registry.AddPathologicalEventMatcherOrDie(AllowOVNReadiness)
@@ -494,56 +420,10 @@ func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalInterva
 // Contains everything in the universal set as well.
 func NewUpgradePathologicalEventMatchers(kubeConfig *rest.Config, finalIntervals monitorapi.Intervals) *AllowedPathologicalEventRegistry {
 
-	// Start with the main list of matchers:
-	registry := NewUniversalPathologicalEventMatchers(kubeConfig, finalIntervals)
-
-	// Now add in the matchers we only want to apply during upgrade:
-
-	// Operators that use library-go can report about multiple versions during upgrades.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "OperatorMultipleVersions",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`(openshift-etcd-operator|openshift-kube-apiserver-operator|openshift-kube-controller-manager-operator|openshift-kube-scheduler-operator)`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`(etcd-operator|kube-apiserver-operator|kube-controller-manager-operator|openshift-kube-scheduler-operator)`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^MultipleVersions$`),
-		messageHumanRegex:  regexp.MustCompile(`multiple versions found, probably in transition`),
-	})
-
-	// etcd-quorum-guard can fail during upgrades.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdQuorumGuardReadinessProbe",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-etcd`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`^etcd-quorum-guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe failed:`),
-	})
-
-	// etcd can have unhealthy members during an upgrade
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdUnhealthyMembers",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`openshift-etcd-operator`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`etcd-operator`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^UnhealthyEtcdMember$`),
-		messageHumanRegex:  regexp.MustCompile(`unhealthy members`),
-	})
+	// Start with the main list of matchers, the exception registry adds those scoped to upgrade:
+	registry := newPathologicalEventMatchers(kubeConfig, finalIntervals, exceptionregistry.UpgradeSuite)
 
-	// Ignore NetworkNotReady repeat events.
-	// This was originally linked to bugzilla: https://bugzilla.redhat.com/show_bug.cgi?id=1986370
-	// The bug has been closed as NOTABUG.
-	// We used to allow this for three namespaces (openshift-multus, openshift-e2e-loki, and openshift-network-diagnostics),
-	// however a quick search of the intervals in bigquery shows this happening a ton in lots of namespaces,
-	// and killing jobs when it does. Given the bug status, I am ignoring these events, whenever they occur, in
-	// all upgrade jobs for now. - dgoodwin
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "NetworkNotReady",
-		messageReasonRegex: regexp.MustCompile(`^NetworkNotReady$`),
-		messageHumanRegex:  regexp.MustCompile(`network is not ready: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: No CNI configuration file.*Has your network provider started\?`),
-	})
+	// Now add in the go matchers we only want to apply during upgrade:
 
 	// Allow FailedScheduling repeat events during node upgrades:
 	m := newFailedSchedulingDuringNodeUpdatePathologicalEventMatcher(finalIntervals)
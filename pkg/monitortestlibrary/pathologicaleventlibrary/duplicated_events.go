@@ -3,9 +3,11 @@ package pathologicaleventlibrary
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/sirupsen/logrus"
 
@@ -21,11 +23,14 @@ import (
 	"k8s.io/client-go/rest"
 )
 
-func TestDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
+// TestDuplicatedEventForUpgrade checks for pathologically repeating events during an upgrade.  Every event excused by
+// a matcher is recorded in usage, which may be nil.
+func TestDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig *rest.Config, usage *exceptionregistry.Usage) []*junitapi.JUnitTestCase {
 	registry := NewUpgradePathologicalEventMatchers(kubeClientConfig, events)
 
 	evaluator := duplicateEventsEvaluator{
 		registry: registry,
+		usage:    usage,
 	}
 
 	platform, topology, err := GetClusterInfraInfo(kubeClientConfig)
@@ -43,11 +48,14 @@ func TestDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig
 	return tests
 }
 
-func TestDuplicatedEventForStableSystem(events monitorapi.Intervals, clientConfig *rest.Config) []*junitapi.JUnitTestCase {
+// TestDuplicatedEventForStableSystem checks for pathologically repeating events on a cluster that did not upgrade.
+// Every event excused by a matcher is recorded in usage, which may be nil.
+func TestDuplicatedEventForStableSystem(events monitorapi.Intervals, clientConfig *rest.Config, usage *exceptionregistry.Usage) []*junitapi.JUnitTestCase {
 	registry := NewUniversalPathologicalEventMatchers(clientConfig, events)
 
 	evaluator := duplicateEventsEvaluator{
 		registry: registry,
+		usage:    usage,
 	}
 
 	platform, topology, err := GetClusterInfraInfo(clientConfig)
@@ -68,6 +76,9 @@ func TestDuplicatedEventForStableSystem(events monitorapi.Intervals, clientConfi
 type duplicateEventsEvaluator struct {
 	registry *AllowedPathologicalEventRegistry
 
+	// usage records every event that was allowed to repeat, it may be nil.
+	usage *exceptionregistry.Usage
+
 	// platform contains the current platform of the cluster under Test.
 	platform v1.PlatformType
 
@@ -190,25 +201,37 @@ func (d *duplicateEventsEvaluator) testDuplicatedEvents(testName string, flakeOn
 
 	// displayToCount maps a static display message to the matching repeating interval we saw with the highest count
 	displayToCount := map[string]monitorapi.Interval{}
+	// excused holds the display messages of allowed events, and excusedByJUnit maps a junit name to them for its
+	// system out.
+	excused := sets.NewString()
+	excusedByJUnit := map[string][]string{}
 
 	for _, event := range events {
 
 		times := GetTimesAnEventHappened(event.Message)
 		if times > DuplicateEventThreshold {
 
+			// key used in a map to identify the common interval that is repeating and we may
+			// encounter multiple times.
+			eventDisplayMessage := fmt.Sprintf("%s - reason/%s %s", event.Locator.OldLocator(),
+				event.Message.Reason, event.Message.HumanMessage)
+
 			// Check if we have an allowance for this event. This code used to just check if it had an interesting flag,
 			// implying it matches some pattern, but that happens even for upgrade patterns occurring in non-upgrade jobs,
 			// so we were ignoring patterns that were meant to be allowed only in upgrade jobs in all jobs. The list of
 			// allowed patterns passed to this object wasn't even used.
-			if allowed, _ := d.registry.AllowedByAny(event, d.topology); allowed {
+			if allowed, matcher := d.registry.AllowedByAny(event, d.topology); allowed {
+				// the same event is seen again every time its count goes up, only report it once.
+				if !excused.Has(eventDisplayMessage) {
+					excused.Insert(eventDisplayMessage)
+					d.usage.Record(exceptionregistry.PathologicalEventKind, matcher.Name(), matcherJira(matcher))
+					jUnitName := getJUnitName(testName, junitNamespace(event))
+					excusedByJUnit[jUnitName] = append(excusedByJUnit[jUnitName],
+						fmt.Sprintf("allowed by %s: %s", matcher.Name(), eventDisplayMessage))
+				}
 				continue
 			}
 
-			// key used in a map to identify the common interval that is repeating and we may
-			// encounter multiple times.
-			eventDisplayMessage := fmt.Sprintf("%s - reason/%s %s", event.Locator.OldLocator(),
-				event.Message.Reason, event.Message.HumanMessage)
-
 			if _, ok := displayToCount[eventDisplayMessage]; !ok {
 				displayToCount[eventDisplayMessage] = event
 			}
@@ -221,16 +244,11 @@ func (d *duplicateEventsEvaluator) testDuplicatedEvents(testName string, flakeOn
 
 	nsResults := map[string]*eventResult{}
 	for intervalDisplayMsg, interval := range displayToCount {
-		namespace := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+		namespace := junitNamespace(interval)
 		intervalMsgWithTime := intervalDisplayMsg + " (" + interval.From.Format("15:04:05Z") + ")"
 		msg := fmt.Sprintf("event happened %d times, something is wrong: %v",
 			GetTimesAnEventHappened(interval.Message), intervalMsgWithTime)
 
-		// We only create junit for known namespaces
-		if !platformidentification.KnownNamespaces.Has(namespace) {
-			namespace = ""
-		}
-
 		if _, ok := nsResults[namespace]; !ok {
 			tmp := &eventResult{}
 			nsResults[namespace] = tmp
@@ -248,9 +266,38 @@ func (d *duplicateEventsEvaluator) testDuplicatedEvents(testName string, flakeOn
 	} else {
 		tests = generateJUnitTestCasesCoreNamespaces(testName, nsResults)
 	}
+	for _, test := range tests {
+		excused := excusedByJUnit[test.Name]
+		if test.FailureOutput != nil || len(excused) == 0 {
+			continue
+		}
+		sort.Strings(excused)
+		test.SystemOut = fmt.Sprintf("%d events were allowed to repeat\n\n%s", len(excused), strings.Join(excused, "\n"))
+	}
 	return tests
 }
 
+// junitNamespace returns the namespace of the interval, or empty if it is not one we create a junit for.
+func junitNamespace(interval monitorapi.Interval) string {
+	namespace := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+	// We only create junit for known namespaces
+	if !platformidentification.KnownNamespaces.Has(namespace) {
+		return ""
+	}
+	return namespace
+}
+
+// matcherJira returns the bug link of a matcher, if it has one.
+func matcherJira(matcher EventMatcher) string {
+	switch m := matcher.(type) {
+	case *SimplePathologicalEventMatcher:
+		return m.jira
+	case *OverlapOtherIntervalsPathologicalEventMatcher:
+		return m.delegate.jira
+	}
+	return ""
+}
+
 func GetTimesAnEventHappened(msg monitorapi.Message) int {
 	countStr, ok := msg.Annotations[monitorapi.AnnotationCount]
 	if !ok {
@@ -7,6 +7,7 @@ import (
 
 	v1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -666,3 +667,32 @@ func TestPathologicalEventsTopologyAwareHintsDisabled(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowedEventsRecordUsage(t *testing.T) {
+	events := monitorapi.Intervals{
+		BuildTestDupeKubeEvent("openshift-e2e-loki", "loki-promtail-abc", "BackOff", "Back-off restarting", 21),
+		BuildTestDupeKubeEvent("openshift-e2e-loki", "loki-promtail-abc", "BackOff", "Back-off restarting", 25),
+		BuildTestDupeKubeEvent("openshift-etcd", "etcd-guard-master-0", "ProbeError",
+			"Readiness probe error: Get \"https://10.0.0.3:9980/readyz\": dial tcp 10.0.0.3:9980: connect: connection refused", 30),
+	}
+	usage := exceptionregistry.NewUsage()
+	evaluator := duplicateEventsEvaluator{
+		registry: NewUniversalPathologicalEventMatchers(nil, events),
+		usage:    usage,
+	}
+
+	testName := "events should not repeat"
+	junits := evaluator.testDuplicatedEvents(testName, false, events, nil, false)
+	assert.Equal(t, []exceptionregistry.UsageHit{
+		{Kind: exceptionregistry.PathologicalEventKind, Name: "E2ELoki", Count: 1},
+		{Kind: exceptionregistry.PathologicalEventKind, Name: "EtcdReadinessProbeError", Count: 1},
+	}, usage.Hits())
+
+	for _, junit := range junits {
+		require.Nil(t, junit.FailureOutput, junit.Name)
+		if junit.Name == getJUnitName(testName, "openshift-etcd") {
+			assert.Contains(t, junit.SystemOut, "1 events were allowed to repeat")
+			assert.Contains(t, junit.SystemOut, "allowed by EtcdReadinessProbeError")
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package etcdmetrics
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	utilmetrics "github.com/openshift/library-go/test/library/metrics"
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/monitortests/metrics"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	exutil "github.com/openshift/origin/test/extended/util"
+	"github.com/sirupsen/logrus"
+
+	prometheustypes "github.com/prometheus/common/model"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	MonitorName = "etcd-metrics"
+
+	queryStep = time.Minute
+)
+
+// etcdMetric is an etcd latency histogram we watch the P99 of.  threshold is in seconds and comes from the etcd
+// hardware guidance: sustained latencies above it mean the disk or network is too slow for etcd to stay healthy.
+type etcdMetric struct {
+	name        string
+	description string
+	threshold   float64
+}
+
+var etcdMetrics = []etcdMetric{
+	{name: "etcd_disk_wal_fsync_duration_seconds", description: "WAL fsync", threshold: 0.01},
+	{name: "etcd_disk_backend_commit_duration_seconds", description: "backend commit", threshold: 0.025},
+	{name: "etcd_network_peer_round_trip_time_seconds", description: "peer round trip", threshold: 0.05},
+}
+
+func (m etcdMetric) query() string {
+	return fmt.Sprintf(`histogram_quantile(0.99, sum by (instance, le) (rate(%s_bucket[5m])))`, m.name)
+}
+
+// queryResults is the worst P99 a job run saw for each metric, aggregated over job runs the same way as the alert
+// and disruption data.  Every run records its worst P99s in the etcd_metrics autodl table, which the data is
+// refreshed from with `openshift-tests historical-data --kind etcd-metrics`.
+//
+//go:embed query_results.json
+var queryResults []byte
+
+var (
+	readResults    sync.Once
+	historicalData *historicaldata.EtcdMetricBestMatcher
+)
+
+func GetHistoricalData() *historicaldata.EtcdMetricBestMatcher {
+	readResults.Do(
+		func() {
+			var err error
+			historicalData, err = historicaldata.NewEtcdMetricMatcher(queryResults)
+			if err != nil {
+				panic(err)
+			}
+		})
+
+	return historicalData
+}
+
+// OverrideHistoricalData replaces the embedded historical data with the content of a file in the same format.  It must
+// be called before the monitor test is evaluated.
+func OverrideHistoricalData(historicalJSON []byte) error {
+	matcher, err := historicaldata.NewEtcdMetricMatcher(historicalJSON)
+	if err != nil {
+		return err
+	}
+	readResults.Do(func() {})
+	historicalData = matcher
+	return nil
+}
+
+// NewEtcdMetricsMonitorTest returns a monitor test that queries the P99 of the etcd WAL fsync, backend commit, and
+// peer round trip latencies from prometheus over the test window.  It produces an interval whenever a member is over
+// the etcd guidance for a metric, records the worst P99 of the run, and compares it to historical data for the job
+// type.
+func NewEtcdMetricsMonitorTest() monitortestframework.MonitorTest {
+	return &monitorTest{}
+}
+
+type etcdMetricMonitor struct {
+	metric etcdMetric
+	query  metrics.QueryRunner
+}
+
+// observation is the worst P99 seen for a metric during the run.
+type observation struct {
+	metric   etcdMetric
+	found    bool
+	worst    float64
+	instance string
+}
+
+type monitorTest struct {
+	adminRESTConfig    *rest.Config
+	monitors           []*etcdMetricMonitor
+	historicalData     *historicaldata.EtcdMetricBestMatcher
+	observations       []observation
+	notSupportedReason error
+}
+
+func (test *monitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	test.adminRESTConfig = adminRESTConfig
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	isMicroShift, err := exutil.IsMicroShiftCluster(kubeClient)
+	if err != nil {
+		return fmt.Errorf("unable to determine if cluster is MicroShift: %v", err)
+	}
+	if isMicroShift {
+		test.notSupportedReason = &monitortestframework.NotSupportedError{
+			Reason: "platform MicroShift not supported",
+		}
+		return test.notSupportedReason
+	}
+	routeClient, err := routeclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := utilmetrics.NewPrometheusClient(ctx, kubeClient, routeClient)
+	if err != nil {
+		return err
+	}
+
+	for _, metric := range etcdMetrics {
+		test.monitors = append(test.monitors, &etcdMetricMonitor{
+			metric: metric,
+			query: &metrics.PrometheusQueryRunner{
+				Client:      client,
+				QueryString: metric.query(),
+				Step:        queryStep,
+			},
+		})
+	}
+	test.historicalData = GetHistoricalData()
+
+	framework.Logf("monitor[%s]: monitor initialized", MonitorName)
+	return nil
+}
+
+func (test *monitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, nil, test.notSupportedReason
+	}
+	if len(test.monitors) == 0 {
+		return monitorapi.Intervals{}, nil, fmt.Errorf("monitor test is not initialized")
+	}
+
+	intervals := monitorapi.Intervals{}
+	test.observations = nil
+	for _, m := range test.monitors {
+		result, err := m.query.RunQuery(ctx, beginning, end)
+		if err != nil {
+			return intervals, nil, fmt.Errorf("query returned error, monitor: %s, err: %w", MonitorName, err)
+		}
+		matrix, ok := result.(prometheustypes.Matrix)
+		if !ok {
+			return intervals, nil, fmt.Errorf("expected a prometheus Matrix type, but got: %q, monitor: %s", result.Type().String(), MonitorName)
+		}
+		metricIntervals, metricObservation := analyze(m.metric, matrix)
+		intervals = append(intervals, metricIntervals...)
+		test.observations = append(test.observations, metricObservation)
+	}
+	return intervals, nil, nil
+}
+
+// analyze returns an interval for every run of samples where a member was over the threshold, and the worst sample
+// of all the members.  histogram_quantile is NaN when there were no observations, those samples are ignored.
+func analyze(metric etcdMetric, matrix prometheustypes.Matrix) (monitorapi.Intervals, observation) {
+	ret := monitorapi.Intervals{}
+	worst := observation{metric: metric}
+	for _, series := range matrix {
+		instance := string(series.Metric["instance"])
+
+		var runStart, runEnd *prometheustypes.SamplePair
+		var runWorst float64
+		closeRun := func() {
+			if runStart == nil {
+				return
+			}
+			ret = append(ret, overThresholdInterval(metric, instance, runStart.Timestamp.Time(), runEnd.Timestamp.Time().Add(queryStep), runWorst))
+			runStart, runEnd, runWorst = nil, nil, 0
+		}
+
+		for i := range series.Values {
+			current := series.Values[i]
+			value := float64(current.Value)
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				closeRun()
+				continue
+			}
+			if !worst.found || value > worst.worst {
+				worst.found = true
+				worst.worst = value
+				worst.instance = instance
+			}
+			if value <= metric.threshold {
+				closeRun()
+				continue
+			}
+			if runStart == nil {
+				runStart = &current
+			}
+			runEnd = &current
+			runWorst = math.Max(runWorst, value)
+		}
+		closeRun()
+	}
+	return ret, worst
+}
+
+func overThresholdInterval(metric etcdMetric, instance string, from, to time.Time, worst float64) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceEtcdMetrics, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().EtcdMetric(instance, metric.name)).
+		Message(monitorapi.NewMessage().
+			Reason(monitorapi.ReasonEtcdMetricOverThreshold).
+			WithAnnotation(monitorapi.AnnotationQuantile, "0.99").
+			WithAnnotation(monitorapi.AnnotationThreshold, strconv.FormatFloat(metric.threshold, 'f', -1, 64)).
+			HumanMessagef("etcd %s P99 was over %s, up to %s", metric.description, secondsToDuration(metric.threshold), secondsToDuration(worst)),
+		).
+		Display().
+		Build(from, to)
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)
+}
+
+func (test *monitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, test.notSupportedReason
+}
+
+func (test *monitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, test.notSupportedReason
+	}
+	jobType, err := platformidentification.GetJobType(ctx, test.adminRESTConfig)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to determine job type for etcd metrics, skipping the comparison to historical data")
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	for _, curr := range test.observations {
+		junits = append(junits, compareToHistoricalData(curr, jobType, test.historicalData)...)
+	}
+	return junits, nil
+}
+
+func compareToHistoricalData(curr observation, jobType *platformidentification.JobType, historicalData *historicaldata.EtcdMetricBestMatcher) []*junitapi.JUnitTestCase {
+	testName := fmt.Sprintf("[sig-etcd] etcd %s P99 latency should not be worse than historical data", curr.metric.description)
+
+	skip := func(message string) []*junitapi.JUnitTestCase {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:        testName,
+				SkipMessage: &junitapi.SkipMessage{Message: message},
+			},
+		}
+	}
+	if !curr.found {
+		return skip(fmt.Sprintf("no samples of %s", curr.metric.name))
+	}
+	if jobType == nil || historicalData == nil {
+		return skip("unable to determine job type")
+	}
+
+	historical, details, err := historicalData.BestMatch(historicaldata.EtcdMetricDataKey{
+		MetricName: curr.metric.name,
+		JobType:    *jobType,
+	})
+	if err != nil {
+		return skip(fmt.Sprintf("unable to find historical data: %v", err))
+	}
+	if historical == (historicaldata.Percentiles{}) {
+		// the worst P99 is recorded either way, this run becomes part of the history once there is enough of it
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				SystemOut: fmt.Sprintf("worst P99 of %s was %s on %s, there is no historical data to compare to %s",
+					curr.metric.name, secondsToDuration(curr.worst), curr.instance, details),
+			},
+		}
+	}
+
+	summary := fmt.Sprintf("worst P99 of %s was %s on %s, historical P99 is %s %s",
+		curr.metric.name, secondsToDuration(curr.worst), curr.instance, secondsToDuration(historical.P99), details)
+	passing := &junitapi.JUnitTestCase{
+		Name:      testName,
+		SystemOut: summary,
+	}
+	if curr.worst <= historical.P99 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	// a slow disk or network on one run is more often the cloud than etcd, so this flakes, and the over threshold
+	// intervals show which member was slow and when
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: summary,
+				Output:  fmt.Sprintf("see the %s intervals for when each member was slow", monitorapi.ReasonEtcdMetricOverThreshold),
+			},
+		},
+		passing,
+	}
+}
+
+// metricObservation is the worst P99 of a metric during the run, in the etcd-metrics artifact and autodl data.
+type metricObservation struct {
+	MetricName      string
+	Instance        string
+	WorstP99Seconds float64
+}
+
+func (test *monitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if test.notSupportedReason != nil {
+		return test.notSupportedReason
+	}
+
+	observations := []metricObservation{}
+	rows := []map[string]string{}
+	for _, curr := range test.observations {
+		if !curr.found {
+			continue
+		}
+		observations = append(observations, metricObservation{
+			MetricName:      curr.metric.name,
+			Instance:        curr.instance,
+			WorstP99Seconds: curr.worst,
+		})
+		rows = append(rows, map[string]string{
+			"MetricName":      curr.metric.name,
+			"Instance":        curr.instance,
+			"WorstP99Seconds": strconv.FormatFloat(curr.worst, 'f', -1, 64),
+		})
+	}
+
+	jsonContent, err := json.MarshalIndent(observations, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(storageDir, fmt.Sprintf("etcd-metrics%s.json", timeSuffix)), jsonContent, 0644); err != nil {
+		return err
+	}
+
+	dataFile := dataloader.DataFile{
+		TableName: "etcd_metrics",
+		Schema: map[string]dataloader.DataType{
+			"MetricName":      dataloader.DataTypeString,
+			"Instance":        dataloader.DataTypeString,
+			"WorstP99Seconds": dataloader.DataTypeFloat64,
+		},
+		Rows: rows,
+	}
+	fileName := filepath.Join(storageDir, fmt.Sprintf("etcd-metrics%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	if err := dataloader.WriteDataFile(fileName, dataFile); err != nil {
+		logrus.WithError(err).Warnf("unable to write data file: %s", fileName)
+	}
+	return nil
+}
+
+func (test *monitorTest) Cleanup(ctx context.Context) error {
+	return test.notSupportedReason
+}
@@ -0,0 +1,145 @@
+package etcdmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+type matrixQuery prometheustypes.Matrix
+
+func (q matrixQuery) RunQuery(ctx context.Context, start, end time.Time) (prometheustypes.Value, error) {
+	return prometheustypes.Matrix(q), nil
+}
+
+func series(instance string, start time.Time, values ...float64) *prometheustypes.SampleStream {
+	ret := &prometheustypes.SampleStream{
+		Metric: prometheustypes.Metric{"instance": prometheustypes.LabelValue(instance)},
+	}
+	for i, value := range values {
+		ret.Values = append(ret.Values, prometheustypes.SamplePair{
+			Timestamp: prometheustypes.TimeFromUnixNano(start.Add(time.Duration(i) * queryStep).UnixNano()),
+			Value:     prometheustypes.SampleValue(value),
+		})
+	}
+	return ret
+}
+
+func TestCollectData(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsync := etcdMetrics[0]
+	commit := etcdMetrics[1]
+
+	test := &monitorTest{
+		monitors: []*etcdMetricMonitor{
+			{
+				metric: fsync,
+				query: matrixQuery{
+					series("10.0.0.1:9979", start, 0.002, 0.02, 0.04, 0.003, 0.015),
+					series("10.0.0.2:9979", start, math.NaN(), 0.004, 0.005, 0.004, 0.003),
+				},
+			},
+			{
+				metric: commit,
+				query:  matrixQuery{series("10.0.0.1:9979", start, math.NaN(), math.NaN())},
+			},
+		},
+	}
+
+	intervals, _, err := test.CollectData(context.Background(), "", start, start.Add(5*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, intervals, 2)
+	assert.Equal(t, monitorapi.ReasonEtcdMetricOverThreshold, intervals[0].Message.Reason)
+	assert.Equal(t, "10.0.0.1:9979", intervals[0].Locator.Keys[monitorapi.LocatorInstanceKey])
+	assert.Equal(t, fsync.name, intervals[0].Locator.Keys[monitorapi.LocatorMetricKey])
+	assert.Equal(t, start.Add(time.Minute), intervals[0].From.UTC())
+	assert.Equal(t, start.Add(3*time.Minute), intervals[0].To.UTC())
+	assert.Contains(t, intervals[0].Message.HumanMessage, "up to 40ms")
+	assert.Equal(t, start.Add(4*time.Minute), intervals[1].From.UTC())
+	assert.Equal(t, start.Add(5*time.Minute), intervals[1].To.UTC())
+
+	require.Len(t, test.observations, 2)
+	assert.True(t, test.observations[0].found)
+	assert.Equal(t, 0.04, test.observations[0].worst)
+	assert.Equal(t, "10.0.0.1:9979", test.observations[0].instance)
+	assert.False(t, test.observations[1].found, "NaN samples are not observations")
+}
+
+func TestCompareToHistoricalData(t *testing.T) {
+	jobType := platformidentification.JobType{Release: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	fsync := etcdMetrics[0]
+	key := historicaldata.EtcdMetricDataKey{MetricName: fsync.name, JobType: jobType}
+	matcher := historicaldata.NewEtcdMetricMatcherWithHistoricalData(map[historicaldata.EtcdMetricDataKey]historicaldata.Percentiles{
+		key: {P99: 0.03, JobRuns: 200},
+	})
+
+	junits := compareToHistoricalData(observation{metric: fsync, found: true, worst: 0.02, instance: "a"}, &jobType, matcher)
+	require.Len(t, junits, 1)
+	assert.Nil(t, junits[0].FailureOutput)
+	assert.Nil(t, junits[0].SkipMessage)
+
+	junits = compareToHistoricalData(observation{metric: fsync, found: true, worst: 0.05, instance: "a"}, &jobType, matcher)
+	require.Len(t, junits, 2, "worse than history should flake")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, "was 50ms on a, historical P99 is 30ms")
+	assert.Nil(t, junits[1].FailureOutput)
+
+	gcp := platformidentification.CloneJobType(jobType)
+	gcp.Platform = "gcp"
+	junits = compareToHistoricalData(observation{metric: fsync, found: true, worst: 0.05}, &gcp, matcher)
+	require.Len(t, junits, 1)
+	assert.Nil(t, junits[0].FailureOutput, "no historical data should pass")
+	assert.Nil(t, junits[0].SkipMessage, "no historical data should pass")
+	assert.Contains(t, junits[0].SystemOut, "there is no historical data to compare to")
+
+	junits = compareToHistoricalData(observation{metric: fsync}, &jobType, matcher)
+	require.Len(t, junits, 1)
+	assert.NotNil(t, junits[0].SkipMessage)
+}
+
+func TestCompareWithoutHistoricalData(t *testing.T) {
+	jobType := platformidentification.JobType{Release: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	junits := compareToHistoricalData(observation{metric: etcdMetrics[0], found: true, worst: 0.05, instance: "a"}, &jobType,
+		historicaldata.NewEtcdMetricMatcherWithHistoricalData(nil))
+	require.Len(t, junits, 1, "the test runs before there is any history")
+	assert.Nil(t, junits[0].FailureOutput)
+	assert.Nil(t, junits[0].SkipMessage)
+	assert.NotNil(t, GetHistoricalData())
+}
+
+func TestWriteContentToStorage(t *testing.T) {
+	storageDir := t.TempDir()
+	test := &monitorTest{
+		observations: []observation{
+			{metric: etcdMetrics[0], found: true, worst: 0.05, instance: "10.0.0.1:9979"},
+			{metric: etcdMetrics[1]},
+		},
+	}
+	require.NoError(t, test.WriteContentToStorage(context.Background(), storageDir, "_20240101", nil, nil))
+
+	content, err := os.ReadFile(filepath.Join(storageDir, "etcd-metrics_20240101.json"))
+	require.NoError(t, err)
+	observations := []metricObservation{}
+	require.NoError(t, json.Unmarshal(content, &observations))
+	assert.Equal(t, []metricObservation{{MetricName: etcdMetrics[0].name, Instance: "10.0.0.1:9979", WorstP99Seconds: 0.05}}, observations)
+
+	content, err = os.ReadFile(filepath.Join(storageDir, "etcd-metrics_20240101-"+dataloader.AutoDataLoaderSuffix))
+	require.NoError(t, err)
+	dataFile := dataloader.DataFile{}
+	require.NoError(t, json.Unmarshal(content, &dataFile))
+	assert.Equal(t, "etcd_metrics", dataFile.TableName)
+	assert.Equal(t, []map[string]string{{"MetricName": etcdMetrics[0].name, "Instance": "10.0.0.1:9979", "WorstP99Seconds": "0.05"}}, dataFile.Rows)
+}
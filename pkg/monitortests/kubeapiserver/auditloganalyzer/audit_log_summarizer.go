@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
@@ -70,6 +71,21 @@ type auditEventInfo struct {
 	groupVersionResource *schema.GroupVersionResource
 }
 
+// observeSeen widens the first and last time something was seen to include the audit event, and returns true when it
+// is the earliest event seen.  Audit logs from different masters are read concurrently, so events do not arrive in
+// order.
+func observeSeen(firstSeen, lastSeen *metav1.Time, auditEvent *auditv1.Event) bool {
+	earliest := false
+	if auditEvent.RequestReceivedTimestamp.Time.Before(firstSeen.Time) {
+		*firstSeen = metav1.Time(auditEvent.RequestReceivedTimestamp)
+		earliest = true
+	}
+	if auditEvent.RequestReceivedTimestamp.Time.After(lastSeen.Time) {
+		*lastSeen = metav1.Time(auditEvent.RequestReceivedTimestamp)
+	}
+	return earliest
+}
+
 func (i *auditEventInfo) getGroupVersionResource(auditEvent *auditv1.Event) schema.GroupVersionResource {
 	if len(i.auditID) > 0 && i.auditID != auditEvent.AuditID {
 		panic(fmt.Sprintf("mismatched auditID: have %v, need %v", i.auditID, auditEvent.AuditID))
@@ -0,0 +1,275 @@
+package auditloganalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// nextKubeRelease returns the kube release the next OpenShift release rebases onto, the one after the kube release
+// vendored in this binary, so we find out which clients will break before the next rebase.
+func nextKubeRelease() (*utilversion.Version, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil, fmt.Errorf("unable to read the build info of this binary")
+	}
+	for _, dep := range info.Deps {
+		// the staging repositories are versioned v0.x for kube 1.x
+		if dep.Path != "k8s.io/apiserver" {
+			continue
+		}
+		vendored, err := utilversion.ParseSemantic(dep.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the vendored k8s.io/apiserver version: %w", err)
+		}
+		return utilversion.MajorMinor(1, vendored.Minor()+1), nil
+	}
+	return nil, fmt.Errorf("k8s.io/apiserver is not vendored in this binary")
+}
+
+const (
+	// the kube-apiserver adds these annotations to requests for deprecated built-in APIs
+	deprecatedAnnotation     = "k8s.io/deprecated"
+	removedReleaseAnnotation = "k8s.io/removed-release"
+)
+
+type deprecatedAPI struct {
+	// removedInRelease is the kube release that stops serving the API.  Empty means no removal is planned yet.
+	removedInRelease string
+	replacement      string
+}
+
+// deprecatedAPIs are the deprecated APIs we know about.  Built-in APIs the kube-apiserver marks deprecated are found
+// through the audit annotations even when they are missing here, so this is mostly for the OpenShift APIs and for
+// the replacement to suggest.  https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var deprecatedAPIs = map[schema.GroupVersionResource]deprecatedAPI{
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Resource: "flowschemas"}:                 {removedInRelease: "1.32", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Resource: "prioritylevelconfigurations"}: {removedInRelease: "1.32", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Resource: "flowschemas"}:                 {removedInRelease: "1.29", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Resource: "prioritylevelconfigurations"}: {removedInRelease: "1.29", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "storage.k8s.io", Version: "v1beta1", Resource: "csistoragecapacities"}:                      {removedInRelease: "1.27", replacement: "storage.k8s.io/v1"},
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta1", Resource: "flowschemas"}:                 {removedInRelease: "1.26", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta1", Resource: "prioritylevelconfigurations"}: {removedInRelease: "1.26", replacement: "flowcontrol.apiserver.k8s.io/v1"},
+	{Group: "autoscaling", Version: "v2beta2", Resource: "horizontalpodautoscalers"}:                     {removedInRelease: "1.26", replacement: "autoscaling/v2"},
+	{Group: "autoscaling", Version: "v2beta1", Resource: "horizontalpodautoscalers"}:                     {removedInRelease: "1.25", replacement: "autoscaling/v2"},
+	{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}:                                           {removedInRelease: "1.25", replacement: "batch/v1"},
+	{Group: "discovery.k8s.io", Version: "v1beta1", Resource: "endpointslices"}:                          {removedInRelease: "1.25", replacement: "discovery.k8s.io/v1"},
+	{Group: "events.k8s.io", Version: "v1beta1", Resource: "events"}:                                     {removedInRelease: "1.25", replacement: "events.k8s.io/v1"},
+	{Group: "node.k8s.io", Version: "v1beta1", Resource: "runtimeclasses"}:                               {removedInRelease: "1.25", replacement: "node.k8s.io/v1"},
+	{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"}:                              {removedInRelease: "1.25", replacement: "policy/v1"},
+	{Group: "policy", Version: "v1beta1", Resource: "podsecuritypolicies"}:                               {removedInRelease: "1.25", replacement: "pod security admission"},
+
+	// deprecated in OpenShift 4.14, no removal planned
+	{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"}: {replacement: "apps/v1 deployments"},
+}
+
+// usersAllowedToCallRemovedAPIs may call removed APIs on purpose.  Keep in sync with the api_requests.go e2e test.
+var usersAllowedToCallRemovedAPIs = sets.New[string](
+	"system:serviceaccount:openshift-kube-storage-version-migrator:kube-storage-version-migrator-sa",
+)
+
+// DeprecatedAPIReport is every request to a deprecated API, with the APIs removed in the next release first.
+type DeprecatedAPIReport struct {
+	NextRelease string
+	APIs        []DeprecatedAPIUsage
+}
+
+type DeprecatedAPIUsage struct {
+	GroupVersionResource schema.GroupVersionResource
+	RemovedInRelease     string
+	RemovedInNextRelease bool
+	Replacement          string
+	Clients              []DeprecatedAPIClient
+}
+
+type DeprecatedAPIClient struct {
+	User      string
+	UserAgent string
+	Platform  bool
+	Count     int
+	FirstSeen metav1.Time
+	LastSeen  metav1.Time
+}
+
+type deprecatedAPIUsage struct {
+	lock        sync.Mutex
+	nextRelease *utilversion.Version
+	apis        map[schema.GroupVersionResource]*DeprecatedAPIUsage
+	clients     map[schema.GroupVersionResource]map[apiClient]*DeprecatedAPIClient
+}
+
+func CheckForDeprecatedAPIUsage() *deprecatedAPIUsage {
+	nextRelease, err := nextKubeRelease()
+	if err != nil {
+		logrus.WithError(err).Warning("Unable to determine the next kube release, only the removals the kube-apiserver reports will be checked")
+	}
+	return &deprecatedAPIUsage{
+		nextRelease: nextRelease,
+		apis:        map[schema.GroupVersionResource]*DeprecatedAPIUsage{},
+		clients:     map[schema.GroupVersionResource]map[apiClient]*DeprecatedAPIClient{},
+	}
+}
+
+func (d *deprecatedAPIUsage) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if beginning != nil && auditEvent.RequestReceivedTimestamp.Before(beginning) || end != nil && end.Before(&auditEvent.RequestReceivedTimestamp) {
+		return
+	}
+	if auditEvent.Stage != auditv1.StageResponseComplete {
+		return
+	}
+	if auditEvent.ObjectRef == nil {
+		return
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    auditEvent.ObjectRef.APIGroup,
+		Version:  auditEvent.ObjectRef.APIVersion,
+		Resource: auditEvent.ObjectRef.Resource,
+	}
+
+	api, known := deprecatedAPIs[gvr]
+	if !known {
+		if auditEvent.Annotations[deprecatedAnnotation] != "true" {
+			return
+		}
+		api = deprecatedAPI{removedInRelease: auditEvent.Annotations[removedReleaseAnnotation]}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, ok := d.apis[gvr]; !ok {
+		d.apis[gvr] = &DeprecatedAPIUsage{
+			GroupVersionResource: gvr,
+			RemovedInRelease:     api.removedInRelease,
+			RemovedInNextRelease: d.removedByNextRelease(api.removedInRelease),
+			Replacement:          api.replacement,
+		}
+		d.clients[gvr] = map[apiClient]*DeprecatedAPIClient{}
+	}
+
+	key := apiClient{
+		user:      auditEvent.User.Username,
+		userAgent: strings.SplitN(auditEvent.UserAgent, " ", 2)[0],
+	}
+	client, ok := d.clients[gvr][key]
+	if !ok {
+		client = &DeprecatedAPIClient{
+			User:      key.user,
+			UserAgent: key.userAgent,
+			Platform:  isPlatformAPIClient(key.user),
+			FirstSeen: metav1.Time(auditEvent.RequestReceivedTimestamp),
+			LastSeen:  metav1.Time(auditEvent.RequestReceivedTimestamp),
+		}
+		d.clients[gvr][key] = client
+	}
+	client.Count++
+	observeSeen(&client.FirstSeen, &client.LastSeen, auditEvent)
+}
+
+func (d *deprecatedAPIUsage) removedByNextRelease(removedInRelease string) bool {
+	if len(removedInRelease) == 0 || d.nextRelease == nil {
+		return false
+	}
+	removed, err := utilversion.ParseGeneric(removedInRelease)
+	if err != nil {
+		return false
+	}
+	return !removed.GreaterThan(d.nextRelease)
+}
+
+func (d *deprecatedAPIUsage) Report() DeprecatedAPIReport {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	ret := DeprecatedAPIReport{
+		APIs: []DeprecatedAPIUsage{},
+	}
+	if d.nextRelease != nil {
+		ret.NextRelease = fmt.Sprintf("%d.%d", d.nextRelease.Major(), d.nextRelease.Minor())
+	}
+	for gvr, api := range d.apis {
+		usage := *api
+		usage.Clients = []DeprecatedAPIClient{}
+		for _, client := range d.clients[gvr] {
+			usage.Clients = append(usage.Clients, *client)
+		}
+		sort.Slice(usage.Clients, func(i, j int) bool {
+			if usage.Clients[i].Count != usage.Clients[j].Count {
+				return usage.Clients[i].Count > usage.Clients[j].Count
+			}
+			return usage.Clients[i].User < usage.Clients[j].User
+		})
+		ret.APIs = append(ret.APIs, usage)
+	}
+	sort.Slice(ret.APIs, func(i, j int) bool {
+		if ret.APIs[i].RemovedInNextRelease != ret.APIs[j].RemovedInNextRelease {
+			return ret.APIs[i].RemovedInNextRelease
+		}
+		return ret.APIs[i].GroupVersionResource.String() < ret.APIs[j].GroupVersionResource.String()
+	})
+	return ret
+}
+
+func (d *deprecatedAPIUsage) WriteReport(artifactDir, timeSuffix string) error {
+	reportBytes, err := json.MarshalIndent(d.Report(), "", "    ")
+	if err != nil {
+		return err
+	}
+	reportPath := filepath.Join(artifactDir, fmt.Sprintf("deprecated-api-usage_%s.json", timeSuffix))
+	if err := os.WriteFile(reportPath, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write %v: %w", reportPath, err)
+	}
+	return nil
+}
+
+// CreateJunits only fails when failOnRemovedAPIUsage is set, otherwise platform components using removed APIs flake
+// so they are visible without breaking payloads.
+func (d *deprecatedAPIUsage) CreateJunits(failOnRemovedAPIUsage bool) []*junitapi.JUnitTestCase {
+	testName := `[Jira:"kube-apiserver"] platform components must not use APIs removed in the next kube release`
+
+	report := d.Report()
+	failures := []string{}
+	for _, api := range report.APIs {
+		if !api.RemovedInNextRelease {
+			continue
+		}
+		for _, client := range api.Clients {
+			if !client.Platform || usersAllowedToCallRemovedAPIs.Has(client.User) {
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("%s (%s) made %d requests to %s, removed in %s, between %s and %s",
+				client.User, client.UserAgent, client.Count, api.GroupVersionResource, api.RemovedInRelease,
+				client.FirstSeen.UTC().Format("2006-01-02T15:04:05Z"), client.LastSeen.UTC().Format("2006-01-02T15:04:05Z")))
+		}
+	}
+
+	passing := &junitapi.JUnitTestCase{Name: testName}
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	failing := &junitapi.JUnitTestCase{
+		Name: testName,
+		FailureOutput: &junitapi.FailureOutput{
+			Message: strings.Join(failures, "\n"),
+			Output:  fmt.Sprintf("these APIs are no longer served in %s, details in deprecated-api-usage", report.NextRelease),
+		},
+	}
+	if failOnRemovedAPIUsage {
+		return []*junitapi.JUnitTestCase{failing}
+	}
+	return []*junitapi.JUnitTestCase{failing, passing}
+}
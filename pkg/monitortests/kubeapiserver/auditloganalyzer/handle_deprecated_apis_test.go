@@ -0,0 +1,89 @@
+package auditloganalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+func apiRequestAt(user, group, version, resource string, annotations map[string]string, at time.Time) *auditv1.Event {
+	return &auditv1.Event{
+		Stage:     auditv1.StageResponseComplete,
+		Verb:      "list",
+		UserAgent: "some-client/v1.0.0 (linux/amd64)",
+		User:      authenticationv1.UserInfo{Username: user},
+		ObjectRef: &auditv1.ObjectReference{
+			APIGroup:   group,
+			APIVersion: version,
+			Resource:   resource,
+		},
+		Annotations:              annotations,
+		RequestReceivedTimestamp: metav1.NewMicroTime(at),
+	}
+}
+
+func TestNextKubeRelease(t *testing.T) {
+	next, err := nextKubeRelease()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), next.Major())
+	assert.Greater(t, next.Minor(), uint(32))
+}
+
+func TestDeprecatedAPIUsage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	operator := "system:serviceaccount:openshift-foo:foo-operator"
+
+	checker := CheckForDeprecatedAPIUsage()
+	checker.nextRelease = utilversion.MajorMinor(1, 32)
+
+	// received out of order, like when reading the logs from several masters
+	checker.HandleAuditLogEvent(apiRequestAt(operator, "flowcontrol.apiserver.k8s.io", "v1beta3", "flowschemas", nil, start.Add(time.Minute)), nil, nil)
+	checker.HandleAuditLogEvent(apiRequestAt(operator, "flowcontrol.apiserver.k8s.io", "v1beta3", "flowschemas", nil, start), nil, nil)
+	checker.HandleAuditLogEvent(apiRequestAt("system:admin", "flowcontrol.apiserver.k8s.io", "v1beta3", "flowschemas", nil, start), nil, nil)
+	// not in the table, but marked deprecated by the kube-apiserver
+	checker.HandleAuditLogEvent(apiRequestAt(operator, "example.k8s.io", "v1beta1", "widgets",
+		map[string]string{deprecatedAnnotation: "true", removedReleaseAnnotation: "1.40"}, start), nil, nil)
+	checker.HandleAuditLogEvent(apiRequestAt(operator, "apps.openshift.io", "v1", "deploymentconfigs", nil, start), nil, nil)
+	checker.HandleAuditLogEvent(apiRequestAt(operator, "flowcontrol.apiserver.k8s.io", "v1", "flowschemas", nil, start), nil, nil)
+
+	report := checker.Report()
+	assert.Equal(t, "1.32", report.NextRelease)
+	require.Len(t, report.APIs, 3)
+
+	flowSchemas := report.APIs[0]
+	assert.Equal(t, schema.GroupVersionResource{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Resource: "flowschemas"}, flowSchemas.GroupVersionResource)
+	assert.True(t, flowSchemas.RemovedInNextRelease)
+	assert.Equal(t, "flowcontrol.apiserver.k8s.io/v1", flowSchemas.Replacement)
+	require.Len(t, flowSchemas.Clients, 2)
+	assert.Equal(t, operator, flowSchemas.Clients[0].User)
+	assert.Equal(t, "some-client/v1.0.0", flowSchemas.Clients[0].UserAgent)
+	assert.True(t, flowSchemas.Clients[0].Platform)
+	assert.Equal(t, 2, flowSchemas.Clients[0].Count)
+	assert.Equal(t, start, flowSchemas.Clients[0].FirstSeen.UTC())
+	assert.Equal(t, start.Add(time.Minute), flowSchemas.Clients[0].LastSeen.UTC())
+	assert.False(t, flowSchemas.Clients[1].Platform)
+
+	assert.Equal(t, "deploymentconfigs", report.APIs[1].GroupVersionResource.Resource)
+	assert.False(t, report.APIs[1].RemovedInNextRelease)
+	assert.Equal(t, "widgets", report.APIs[2].GroupVersionResource.Resource)
+	assert.Equal(t, "1.40", report.APIs[2].RemovedInRelease)
+	assert.False(t, report.APIs[2].RemovedInNextRelease)
+
+	junits := checker.CreateJunits(false)
+	require.Len(t, junits, 2, "removed API usage should flake unless failing is enabled")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, operator)
+	assert.NotContains(t, junits[0].FailureOutput.Message, "system:admin")
+	assert.Nil(t, junits[1].FailureOutput)
+
+	junits = checker.CreateJunits(true)
+	require.Len(t, junits, 1)
+	assert.NotNil(t, junits[0].FailureOutput)
+}
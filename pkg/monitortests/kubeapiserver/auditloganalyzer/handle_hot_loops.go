@@ -0,0 +1,349 @@
+package auditloganalyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+)
+
+const (
+	// a client making the same request on the same object at least once a second for ten minutes straight is hot
+	// looping.  Leader election renews every two seconds, so this stays clear of it.
+	hotLoopRequestsPerMinute = 60
+	hotLoopMinimumMinutes    = 10
+
+	// a client spikes when it makes at least spikeMinimumRequests in a minute, and spikeFactor times more than its
+	// average over the previous spikeWindowMinutes.  Clients we have not seen for a full window have no baseline.
+	spikeMinimumRequests = 600
+	spikeFactor          = 10
+	spikeWindowMinutes   = 10
+)
+
+// knownHotLoop tolerates a client we already know makes the same request at a high rate.  Every entry needs a bug.
+type knownHotLoop struct {
+	user     *regexp.Regexp
+	verb     string
+	resource string
+	bug      string
+}
+
+func (k knownHotLoop) matches(request apiClientRequest) bool {
+	if k.user != nil && !k.user.MatchString(request.user) {
+		return false
+	}
+	if len(k.verb) > 0 && k.verb != request.verb {
+		return false
+	}
+	if len(k.resource) > 0 && k.resource != request.resource {
+		return false
+	}
+	return true
+}
+
+// knownHotLoops is empty until we have seen what this finds in CI.
+var knownHotLoops = []knownHotLoop{}
+
+type apiClient struct {
+	user      string
+	userAgent string
+}
+
+type apiClientRequest struct {
+	apiClient
+	verb      string
+	resource  string
+	namespace string
+	name      string
+}
+
+func (r apiClientRequest) String() string {
+	object := r.resource
+	if len(r.name) > 0 {
+		object = fmt.Sprintf("%s/%s", object, r.name)
+	}
+	if len(r.namespace) > 0 {
+		object = fmt.Sprintf("%s -n %s", object, r.namespace)
+	}
+	return fmt.Sprintf("%s %s (%s) %s", r.user, r.verb, r.userAgent, object)
+}
+
+// hotLoop is a run of minutes where a client repeated the same request on the same object at a high rate.
+type hotLoop struct {
+	request apiClientRequest
+	from    time.Time
+	to      time.Time
+	count   int
+}
+
+func (h hotLoop) ratePerMinute() int {
+	return h.count / int(h.to.Sub(h.from).Minutes())
+}
+
+// rateSpike is a run of minutes where a client made far more requests than it usually does.
+type rateSpike struct {
+	client   apiClient
+	from     time.Time
+	to       time.Time
+	count    int
+	baseline int
+}
+
+type hotLoops struct {
+	lock                    sync.Mutex
+	requestsPerMinute       map[apiClientRequest]map[int64]int
+	clientRequestsPerMinute map[apiClient]map[int64]int
+
+	knownHotLoops []knownHotLoop
+}
+
+func CheckForHotLoops() *hotLoops {
+	return &hotLoops{
+		requestsPerMinute:       map[apiClientRequest]map[int64]int{},
+		clientRequestsPerMinute: map[apiClient]map[int64]int{},
+		knownHotLoops:           knownHotLoops,
+	}
+}
+
+func (h *hotLoops) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if beginning != nil && auditEvent.RequestReceivedTimestamp.Before(beginning) || end != nil && end.Before(&auditEvent.RequestReceivedTimestamp) {
+		return
+	}
+	if auditEvent.Stage != auditv1.StageResponseComplete {
+		return
+	}
+	// watches are long running, their rate is not interesting
+	if auditEvent.Verb == "watch" {
+		return
+	}
+
+	client := apiClient{
+		user: auditEvent.User.Username,
+		// the user agent starts with the binary name and version, the platform and commit that follow make no difference
+		userAgent: strings.SplitN(auditEvent.UserAgent, " ", 2)[0],
+	}
+	request := apiClientRequest{
+		apiClient: client,
+		verb:      auditEvent.Verb,
+	}
+	if obj := auditEvent.ObjectRef; obj != nil {
+		request.resource = obj.Resource
+		if len(obj.Subresource) > 0 {
+			request.resource = fmt.Sprintf("%s/%s", request.resource, obj.Subresource)
+		}
+		if len(obj.APIGroup) > 0 {
+			request.resource = fmt.Sprintf("%s.%s", obj.APIGroup, request.resource)
+		}
+		request.namespace = obj.Namespace
+		request.name = obj.Name
+	} else {
+		request.resource = auditEvent.RequestURI
+	}
+	minute := auditEvent.RequestReceivedTimestamp.Unix() / 60
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.requestsPerMinute[request]; !ok {
+		h.requestsPerMinute[request] = map[int64]int{}
+	}
+	h.requestsPerMinute[request][minute]++
+	if _, ok := h.clientRequestsPerMinute[client]; !ok {
+		h.clientRequestsPerMinute[client] = map[int64]int{}
+	}
+	h.clientRequestsPerMinute[client][minute]++
+}
+
+func minuteToTime(minute int64) time.Time {
+	return time.Unix(minute*60, 0).UTC()
+}
+
+func (h *hotLoops) hotLoops() []hotLoop {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	ret := []hotLoop{}
+	for request, countsPerMinute := range h.requestsPerMinute {
+		minutes := []int64{}
+		for minute, count := range countsPerMinute {
+			if count >= hotLoopRequestsPerMinute {
+				minutes = append(minutes, minute)
+			}
+		}
+		sort.Slice(minutes, func(i, j int) bool { return minutes[i] < minutes[j] })
+
+		for start := 0; start < len(minutes); {
+			last := start
+			count := countsPerMinute[minutes[start]]
+			for last+1 < len(minutes) && minutes[last+1] == minutes[last]+1 {
+				last++
+				count += countsPerMinute[minutes[last]]
+			}
+			if last-start+1 >= hotLoopMinimumMinutes {
+				ret = append(ret, hotLoop{
+					request: request,
+					from:    minuteToTime(minutes[start]),
+					to:      minuteToTime(minutes[last] + 1),
+					count:   count,
+				})
+			}
+			start = last + 1
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].from.Equal(ret[j].from) {
+			return ret[i].from.Before(ret[j].from)
+		}
+		return ret[i].request.String() < ret[j].request.String()
+	})
+	return ret
+}
+
+func (h *hotLoops) rateSpikes() []rateSpike {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	ret := []rateSpike{}
+	for client, countsPerMinute := range h.clientRequestsPerMinute {
+		first, last := int64(-1), int64(-1)
+		for minute := range countsPerMinute {
+			if first < 0 || minute < first {
+				first = minute
+			}
+			if minute > last {
+				last = minute
+			}
+		}
+
+		var current *rateSpike
+		for minute := first + spikeWindowMinutes; minute <= last; minute++ {
+			windowCount := 0
+			for previous := minute - spikeWindowMinutes; previous < minute; previous++ {
+				windowCount += countsPerMinute[previous]
+			}
+			baseline := windowCount / spikeWindowMinutes
+			count := countsPerMinute[minute]
+
+			isSpike := count >= spikeMinimumRequests && count >= spikeFactor*baseline
+			switch {
+			case isSpike && current == nil:
+				current = &rateSpike{client: client, from: minuteToTime(minute), baseline: baseline}
+				fallthrough
+			case isSpike:
+				current.to = minuteToTime(minute + 1)
+				current.count += count
+			case current != nil:
+				ret = append(ret, *current)
+				current = nil
+			}
+		}
+		if current != nil {
+			ret = append(ret, *current)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].from.Equal(ret[j].from) {
+			return ret[i].from.Before(ret[j].from)
+		}
+		return ret[i].client.user < ret[j].client.user
+	})
+	return ret
+}
+
+func (h *hotLoops) Intervals() monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, loop := range h.hotLoops() {
+		request := loop.request
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceAuditLog, monitorapi.Warning).
+				Locator(monitorapi.NewLocator().APIClientRequest(request.user, request.userAgent, request.verb, request.resource, request.namespace, request.name)).
+				Message(monitorapi.NewMessage().
+					Reason(monitorapi.ReasonAPIClientHotLoop).
+					WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(loop.count)).
+					WithAnnotation(monitorapi.AnnotationRatePerMinute, strconv.Itoa(loop.ratePerMinute())).
+					HumanMessagef("%d %s requests on the same object, %d per minute", loop.count, request.verb, loop.ratePerMinute()),
+				).
+				Display().
+				Build(loop.from, loop.to))
+	}
+	for _, spike := range h.rateSpikes() {
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceAuditLog, monitorapi.Warning).
+				Locator(monitorapi.NewLocator().APIClient(spike.client.user, spike.client.userAgent)).
+				Message(monitorapi.NewMessage().
+					Reason(monitorapi.ReasonAPIClientRateSpike).
+					WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(spike.count)).
+					WithAnnotation(monitorapi.AnnotationRatePerMinute, strconv.Itoa(spike.count/int(spike.to.Sub(spike.from).Minutes()))).
+					HumanMessagef("%d requests, up from %d per minute over the previous %d minutes", spike.count, spike.baseline, spikeWindowMinutes),
+				).
+				Display().
+				Build(spike.from, spike.to))
+	}
+	return ret
+}
+
+// isPlatformAPIClient is true for the serviceaccounts of platform namespaces and the users of the kube components.
+// e2e tests run as system:admin or serviceaccounts in their own namespaces.
+func isPlatformAPIClient(user string) bool {
+	if namespace, _, err := serviceaccount.SplitUsername(user); err == nil {
+		return platformidentification.IsPlatformNamespace(namespace)
+	}
+	return strings.HasPrefix(user, "system:") && user != "system:admin"
+}
+
+func (h *hotLoops) CreateJunits() []*junitapi.JUnitTestCase {
+	testName := `[Jira:"kube-apiserver"] platform API clients must not hot loop`
+
+	failures := []string{}
+	tolerated := []string{}
+	for _, loop := range h.hotLoops() {
+		if !isPlatformAPIClient(loop.request.user) {
+			continue
+		}
+		message := fmt.Sprintf("%s made %d requests from %s to %s, %d per minute",
+			loop.request, loop.count, loop.from.Format(time.RFC3339), loop.to.Format(time.RFC3339), loop.ratePerMinute())
+
+		knownBug := ""
+		for _, known := range h.knownHotLoops {
+			if known.matches(loop.request) {
+				knownBug = known.bug
+				break
+			}
+		}
+		if len(knownBug) > 0 {
+			tolerated = append(tolerated, fmt.Sprintf("%s: %s", knownBug, message))
+			continue
+		}
+		failures = append(failures, message)
+	}
+
+	passing := &junitapi.JUnitTestCase{Name: testName}
+	if len(tolerated) > 0 {
+		passing.SystemOut = fmt.Sprintf("known hot loops:\n%s", strings.Join(tolerated, "\n"))
+	}
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	// flake until we know how many hot loops CI has
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: strings.Join(failures, "\n"),
+				Output:  "details in audit log",
+			},
+		},
+		passing,
+	}
+}
@@ -0,0 +1,106 @@
+package auditloganalyzer
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func requestAt(user, verb, name string, at time.Time) *auditv1.Event {
+	return &auditv1.Event{
+		Stage:     auditv1.StageResponseComplete,
+		Verb:      verb,
+		UserAgent: "cluster-foo-operator/v0.0.0 (linux/amd64) kubernetes/$Format",
+		User:      authenticationv1.UserInfo{Username: user},
+		ObjectRef: &auditv1.ObjectReference{
+			Resource:  "configmaps",
+			Namespace: "openshift-foo",
+			Name:      name,
+		},
+		RequestReceivedTimestamp: metav1.NewMicroTime(at),
+	}
+}
+
+func TestHotLoops(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	operator := "system:serviceaccount:openshift-foo:foo-operator"
+
+	checker := CheckForHotLoops()
+	// two updates a second on the same configmap for fifteen minutes
+	for i := 0; i < 15*60*2; i++ {
+		checker.HandleAuditLogEvent(requestAt(operator, "update", "hot", start.Add(time.Duration(i)*500*time.Millisecond)), nil, nil)
+	}
+	// the same rate on a configmap for only five minutes is not a hot loop
+	for i := 0; i < 5*60*2; i++ {
+		checker.HandleAuditLogEvent(requestAt(operator, "update", "warm", start.Add(time.Duration(i)*500*time.Millisecond)), nil, nil)
+	}
+	// an e2e test hot looping does not fail the junit
+	for i := 0; i < 15*60*2; i++ {
+		checker.HandleAuditLogEvent(requestAt("system:admin", "get", "e2e", start.Add(time.Duration(i)*500*time.Millisecond)), nil, nil)
+	}
+
+	loops := checker.hotLoops()
+	require.Len(t, loops, 2)
+	assert.Equal(t, "hot", loops[1].request.name)
+	assert.Equal(t, "cluster-foo-operator/v0.0.0", loops[1].request.userAgent)
+	assert.Equal(t, start, loops[1].from)
+	assert.Equal(t, start.Add(15*time.Minute), loops[1].to)
+	assert.Equal(t, 120, loops[1].ratePerMinute())
+
+	intervals := checker.Intervals().Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonAPIClientHotLoop
+	})
+	require.Len(t, intervals, 2)
+	assert.Equal(t, monitorapi.LocatorTypeAPIClient, intervals[1].Locator.Type)
+	assert.Equal(t, "hot", intervals[1].Locator.Keys[monitorapi.LocatorNameKey])
+
+	junits := checker.CreateJunits()
+	require.Len(t, junits, 2, "hot loops should flake")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, "configmaps/hot -n openshift-foo")
+	assert.NotContains(t, junits[0].FailureOutput.Message, "system:admin")
+
+	checker.knownHotLoops = []knownHotLoop{{user: regexp.MustCompile("^system:serviceaccount:openshift-foo:"), verb: "update", bug: "OCPBUGS-1"}}
+	junits = checker.CreateJunits()
+	require.Len(t, junits, 1)
+	assert.Nil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].SystemOut, "OCPBUGS-1")
+}
+
+func TestRateSpikes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	operator := "system:serviceaccount:openshift-foo:foo-operator"
+
+	checker := CheckForHotLoops()
+	// ten requests a minute for twenty minutes, with a thousand in minute fifteen
+	for minute := 0; minute < 20; minute++ {
+		requests := 10
+		if minute == 15 {
+			requests = 1000
+		}
+		for i := 0; i < requests; i++ {
+			at := start.Add(time.Duration(minute)*time.Minute + time.Duration(i)*time.Minute/time.Duration(requests))
+			checker.HandleAuditLogEvent(requestAt(operator, "get", "", at), nil, nil)
+		}
+	}
+	// a burst when a client first starts has no baseline
+	for i := 0; i < 1000; i++ {
+		checker.HandleAuditLogEvent(requestAt("system:serviceaccount:openshift-bar:bar-operator", "get", "", start.Add(time.Duration(i)*time.Millisecond)), nil, nil)
+	}
+
+	spikes := checker.rateSpikes()
+	require.Len(t, spikes, 1)
+	assert.Equal(t, operator, spikes[0].client.user)
+	assert.Equal(t, start.Add(15*time.Minute), spikes[0].from)
+	assert.Equal(t, start.Add(16*time.Minute), spikes[0].to)
+	assert.Equal(t, 1000, spikes[0].count)
+	assert.Equal(t, 10, spikes[0].baseline)
+}
@@ -0,0 +1,56 @@
+package auditloganalyzer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+)
+
+// platformNamespaces collects the platform namespaces that appear in the audit log.  When the audit logs are read
+// offline the watchnamespaces monitor has not run, so this is the only way to know which namespaces to check.
+type platformNamespaces struct {
+	lock       sync.Mutex
+	namespaces sets.Set[string]
+}
+
+func TrackPlatformNamespaces() *platformNamespaces {
+	return &platformNamespaces{
+		namespaces: sets.New[string](),
+	}
+}
+
+func (p *platformNamespaces) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if beginning != nil && auditEvent.RequestReceivedTimestamp.Before(beginning) || end != nil && end.Before(&auditEvent.RequestReceivedTimestamp) {
+		return
+	}
+
+	namespaces := []string{}
+	if auditEvent.ObjectRef != nil {
+		namespaces = append(namespaces, auditEvent.ObjectRef.Namespace)
+	}
+	if namespace, _, err := serviceaccount.SplitUsername(auditEvent.User.Username); err == nil {
+		namespaces = append(namespaces, namespace)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, namespace := range namespaces {
+		if platformidentification.IsPlatformNamespace(namespace) {
+			p.namespaces.Insert(namespace)
+		}
+	}
+}
+
+func (p *platformNamespaces) List() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ret := p.namespaces.UnsortedList()
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,255 @@
+package auditloganalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+const (
+	authorizationDecisionAnnotation = "authorization.k8s.io/decision"
+	authorizationReasonAnnotation   = "authorization.k8s.io/reason"
+
+	// a platform client denied the same request this many times is usually missing a role, not racing the
+	// creation of one.
+	repeatedDenialCount = 10
+	// a user denied at least this many times in a minute produces an interval
+	denialSpikePerMinute = 30
+	// only keep a few distinct reasons and namespaces per denial, they are examples, not a full record
+	maxDenialExamples = 5
+)
+
+// RBACDenialReport is every request the authorizer denied, with the most denied first.
+type RBACDenialReport struct {
+	Denials []RBACDenial
+}
+
+type RBACDenial struct {
+	User     string
+	Verb     string
+	Resource string
+	// Platform is true for the serviceaccounts of platform namespaces and the users of the kube components
+	Platform     bool
+	Count        int
+	Namespaces   []string
+	Reasons      []string
+	FirstAuditID string
+	FirstSeen    metav1.Time
+	LastSeen     metav1.Time
+}
+
+type rbacDenialKey struct {
+	user     string
+	verb     string
+	resource string
+}
+
+type rbacDenial struct {
+	RBACDenial
+	namespaces sets.Set[string]
+	reasons    sets.Set[string]
+}
+
+type rbacDenials struct {
+	lock                 sync.Mutex
+	denials              map[rbacDenialKey]*rbacDenial
+	userDenialsPerMinute map[string]map[int64]int
+}
+
+func CheckForRBACDenials() *rbacDenials {
+	return &rbacDenials{
+		denials:              map[rbacDenialKey]*rbacDenial{},
+		userDenialsPerMinute: map[string]map[int64]int{},
+	}
+}
+
+func (r *rbacDenials) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	if beginning != nil && auditEvent.RequestReceivedTimestamp.Before(beginning) || end != nil && end.Before(&auditEvent.RequestReceivedTimestamp) {
+		return
+	}
+	if auditEvent.Stage != auditv1.StageResponseComplete {
+		return
+	}
+	if auditEvent.Annotations[authorizationDecisionAnnotation] != "forbid" {
+		return
+	}
+
+	key := rbacDenialKey{
+		user: auditEvent.User.Username,
+		verb: auditEvent.Verb,
+	}
+	namespace := ""
+	if obj := auditEvent.ObjectRef; obj != nil {
+		key.resource = obj.Resource
+		if len(obj.Subresource) > 0 {
+			key.resource = fmt.Sprintf("%s/%s", key.resource, obj.Subresource)
+		}
+		if len(obj.APIGroup) > 0 {
+			key.resource = fmt.Sprintf("%s.%s", obj.APIGroup, key.resource)
+		}
+		namespace = obj.Namespace
+	} else {
+		// non-resource URLs, like /metrics
+		key.resource = strings.Split(auditEvent.RequestURI, "?")[0]
+	}
+	reason := auditEvent.Annotations[authorizationReasonAnnotation]
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	denial, ok := r.denials[key]
+	if !ok {
+		denial = &rbacDenial{
+			RBACDenial: RBACDenial{
+				User:         key.user,
+				Verb:         key.verb,
+				Resource:     key.resource,
+				Platform:     isPlatformAPIClient(key.user),
+				FirstAuditID: string(auditEvent.AuditID),
+				FirstSeen:    metav1.Time(auditEvent.RequestReceivedTimestamp),
+				LastSeen:     metav1.Time(auditEvent.RequestReceivedTimestamp),
+			},
+			namespaces: sets.New[string](),
+			reasons:    sets.New[string](),
+		}
+		r.denials[key] = denial
+	}
+	denial.Count++
+	if observeSeen(&denial.FirstSeen, &denial.LastSeen, auditEvent) {
+		denial.FirstAuditID = string(auditEvent.AuditID)
+	}
+	if len(namespace) > 0 && denial.namespaces.Len() < maxDenialExamples {
+		denial.namespaces.Insert(namespace)
+	}
+	if len(reason) > 0 && denial.reasons.Len() < maxDenialExamples {
+		denial.reasons.Insert(reason)
+	}
+
+	if _, ok := r.userDenialsPerMinute[key.user]; !ok {
+		r.userDenialsPerMinute[key.user] = map[int64]int{}
+	}
+	r.userDenialsPerMinute[key.user][auditEvent.RequestReceivedTimestamp.Unix()/60]++
+}
+
+func (r *rbacDenials) Report() RBACDenialReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ret := RBACDenialReport{Denials: []RBACDenial{}}
+	for _, denial := range r.denials {
+		curr := denial.RBACDenial
+		curr.Namespaces = sets.List(denial.namespaces)
+		curr.Reasons = sets.List(denial.reasons)
+		ret.Denials = append(ret.Denials, curr)
+	}
+	sort.Slice(ret.Denials, func(i, j int) bool {
+		lhs, rhs := ret.Denials[i], ret.Denials[j]
+		if lhs.Count != rhs.Count {
+			return lhs.Count > rhs.Count
+		}
+		if lhs.User != rhs.User {
+			return lhs.User < rhs.User
+		}
+		if lhs.Verb != rhs.Verb {
+			return lhs.Verb < rhs.Verb
+		}
+		return lhs.Resource < rhs.Resource
+	})
+	return ret
+}
+
+func (r *rbacDenials) WriteReport(artifactDir, timeSuffix string) error {
+	reportBytes, err := json.MarshalIndent(r.Report(), "", "    ")
+	if err != nil {
+		return err
+	}
+	reportPath := filepath.Join(artifactDir, fmt.Sprintf("rbac-denials_%s.json", timeSuffix))
+	if err := os.WriteFile(reportPath, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write %v: %w", reportPath, err)
+	}
+	return nil
+}
+
+// Intervals has one interval for every run of minutes where a user was denied at least denialSpikePerMinute times
+// a minute.
+func (r *rbacDenials) Intervals() monitorapi.Intervals {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ret := monitorapi.Intervals{}
+	for user, denialsPerMinute := range r.userDenialsPerMinute {
+		minutes := []int64{}
+		for minute, count := range denialsPerMinute {
+			if count >= denialSpikePerMinute {
+				minutes = append(minutes, minute)
+			}
+		}
+		sort.Slice(minutes, func(i, j int) bool { return minutes[i] < minutes[j] })
+
+		for start := 0; start < len(minutes); {
+			last := start
+			count := denialsPerMinute[minutes[start]]
+			for last+1 < len(minutes) && minutes[last+1] == minutes[last]+1 {
+				last++
+				count += denialsPerMinute[minutes[last]]
+			}
+			ret = append(ret,
+				monitorapi.NewInterval(monitorapi.SourceAuditLog, monitorapi.Warning).
+					Locator(monitorapi.NewLocator().APIClient(user, "")).
+					Message(monitorapi.NewMessage().
+						Reason(monitorapi.ReasonAuthorizationDenials).
+						WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(count)).
+						HumanMessagef("%d requests were denied by the authorizer", count),
+					).
+					Display().
+					Build(minuteToTime(minutes[start]), minuteToTime(minutes[last]+1)))
+			start = last + 1
+		}
+	}
+	sort.Sort(ret)
+	return ret
+}
+
+func (r *rbacDenials) CreateJunits() []*junitapi.JUnitTestCase {
+	testName := `[Jira:"kube-apiserver"] platform API clients must not be repeatedly denied by the authorizer`
+
+	failures := []string{}
+	for _, denial := range r.Report().Denials {
+		if !denial.Platform || denial.Count < repeatedDenialCount {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s was denied %s %s %d times between %s and %s, namespaces=%v reasons=%q auditID=%s",
+			denial.User, denial.Verb, denial.Resource, denial.Count,
+			denial.FirstSeen.UTC().Format(time.RFC3339), denial.LastSeen.UTC().Format(time.RFC3339),
+			denial.Namespaces, denial.Reasons, denial.FirstAuditID))
+	}
+
+	passing := &junitapi.JUnitTestCase{Name: testName}
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	// flake until we know how many of these CI has
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: strings.Join(failures, "\n"),
+				Output:  "a client that is denied over and over is usually missing a role, details in rbac-denials",
+			},
+		},
+		passing,
+	}
+}
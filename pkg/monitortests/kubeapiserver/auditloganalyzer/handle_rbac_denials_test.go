@@ -0,0 +1,92 @@
+package auditloganalyzer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func deniedRequestAt(user, namespace, auditID, reason string, at time.Time) *auditv1.Event {
+	return &auditv1.Event{
+		AuditID: types.UID(auditID),
+		Stage:   auditv1.StageResponseComplete,
+		Verb:    "list",
+		User:    authenticationv1.UserInfo{Username: user},
+		ObjectRef: &auditv1.ObjectReference{
+			APIGroup:  "monitoring.coreos.com",
+			Resource:  "servicemonitors",
+			Namespace: namespace,
+		},
+		ResponseStatus: &metav1.Status{Code: 403},
+		Annotations: map[string]string{
+			authorizationDecisionAnnotation: "forbid",
+			authorizationReasonAnnotation:   reason,
+		},
+		RequestReceivedTimestamp: metav1.NewMicroTime(at),
+	}
+}
+
+func TestRBACDenials(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	operator := "system:serviceaccount:openshift-foo:foo-operator"
+	missingRole := `RBAC: role.rbac.authorization.k8s.io "prometheus-k8s" not found`
+
+	checker := CheckForRBACDenials()
+	// denied once a second for two minutes, received out of order
+	for i := 119; i >= 0; i-- {
+		checker.HandleAuditLogEvent(deniedRequestAt(operator, "openshift-monitoring", fmt.Sprintf("audit-%d", i), missingRole, start.Add(time.Duration(i)*time.Second)), nil, nil)
+	}
+	// e2e users are denied all the time on purpose
+	for i := 0; i < 20; i++ {
+		checker.HandleAuditLogEvent(deniedRequestAt("system:serviceaccount:e2e-test-foo:default", "e2e-test-foo", "e2e", "", start), nil, nil)
+	}
+	// the kube components are platform clients too
+	for i := 0; i < 10; i++ {
+		checker.HandleAuditLogEvent(deniedRequestAt("system:kube-controller-manager", "openshift-monitoring", "kcm", "", start), nil, nil)
+	}
+	allowed := deniedRequestAt(operator, "openshift-monitoring", "allowed", missingRole, start)
+	allowed.Annotations[authorizationDecisionAnnotation] = "allow"
+	checker.HandleAuditLogEvent(allowed, nil, nil)
+
+	report := checker.Report()
+	require.Len(t, report.Denials, 3)
+	denial := report.Denials[0]
+	assert.Equal(t, operator, denial.User)
+	assert.Equal(t, "list", denial.Verb)
+	assert.Equal(t, "monitoring.coreos.com.servicemonitors", denial.Resource)
+	assert.True(t, denial.Platform)
+	assert.Equal(t, 120, denial.Count)
+	assert.Equal(t, []string{"openshift-monitoring"}, denial.Namespaces)
+	assert.Equal(t, []string{missingRole}, denial.Reasons)
+	assert.Equal(t, "audit-0", denial.FirstAuditID)
+	assert.Equal(t, start, denial.FirstSeen.UTC())
+	assert.Equal(t, "system:serviceaccount:e2e-test-foo:default", report.Denials[1].User)
+	assert.False(t, report.Denials[1].Platform)
+	assert.Empty(t, report.Denials[1].Reasons)
+	assert.Equal(t, "system:kube-controller-manager", report.Denials[2].User)
+	assert.True(t, report.Denials[2].Platform)
+
+	intervals := checker.Intervals()
+	require.Len(t, intervals, 1)
+	assert.Equal(t, monitorapi.ReasonAuthorizationDenials, intervals[0].Message.Reason)
+	assert.Equal(t, operator, intervals[0].Locator.Keys[monitorapi.LocatorAPIClientUserKey])
+	assert.Equal(t, start, intervals[0].From)
+	assert.Equal(t, start.Add(2*time.Minute), intervals[0].To)
+
+	junits := checker.CreateJunits()
+	require.Len(t, junits, 2, "repeated denials should flake")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, operator)
+	assert.Contains(t, junits[0].FailureOutput.Message, fmt.Sprintf("reasons=%q", []string{missingRole}))
+	assert.Contains(t, junits[0].FailureOutput.Message, "system:kube-controller-manager")
+	assert.NotContains(t, junits[0].FailureOutput.Message, "e2e-test-foo")
+}
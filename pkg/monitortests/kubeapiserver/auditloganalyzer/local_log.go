@@ -0,0 +1,163 @@
+package auditloganalyzer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// nonKubeAPIServerAuditLogDirs are the directories a must-gather uses for the audit logs of the other apiservers.
+// Only kube-apiserver audit logs are analyzed, same as when the logs are read from the nodes.
+var nonKubeAPIServerAuditLogDirs = sets.New[string]("openshift-apiserver", "oauth-apiserver", "oauth-server")
+
+// GetLocalKubeAuditLogSummary runs the handlers over kube-apiserver audit logs in a local directory, like the
+// audit_logs directory of a must-gather, so the audit log analysis can be done without access to the cluster.
+// Plain and gzipped logs are read.
+func GetLocalKubeAuditLogSummary(ctx context.Context, auditLogDir string, beginning, end *time.Time, auditLogHandlers []AuditEventHandler) error {
+	auditLogFiles, err := findLocalAuditLogs(auditLogDir)
+	if err != nil {
+		return err
+	}
+	if len(auditLogFiles) == 0 {
+		return fmt.Errorf("no kube-apiserver audit logs found in %q", auditLogDir)
+	}
+
+	wg := sync.WaitGroup{}
+	errCh := make(chan error, len(auditLogFiles))
+	for _, auditLogFile := range auditLogFiles {
+		wg.Add(1)
+		go func(auditLogFile string) {
+			defer wg.Done()
+
+			auditStream, err := openLocalAuditLog(auditLogFile)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			defer auditStream.Close()
+
+			handleAuditLogStream(auditLogFile, auditStream, toMicroTime(beginning), toMicroTime(end), auditLogHandlers)
+		}(auditLogFile)
+	}
+	wg.Wait()
+	close(errCh)
+
+	errs := []error{}
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// EarliestLocalAuditEvent returns the time of the first request in the local audit logs.  Without a cluster to read
+// the ClusterVersion from, this is our best estimate of when the cluster started.
+func EarliestLocalAuditEvent(auditLogDir string) (time.Time, error) {
+	auditLogFiles, err := findLocalAuditLogs(auditLogDir)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	earliest := time.Time{}
+	for _, auditLogFile := range auditLogFiles {
+		first, err := firstLocalAuditEvent(auditLogFile)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if first == nil {
+			continue
+		}
+		if earliest.IsZero() || first.RequestReceivedTimestamp.Time.Before(earliest) {
+			earliest = first.RequestReceivedTimestamp.Time
+		}
+	}
+	if earliest.IsZero() {
+		return time.Time{}, fmt.Errorf("no audit events found in %q", auditLogDir)
+	}
+	return earliest, nil
+}
+
+// firstLocalAuditEvent relies on audit logs being written in order, so the first event decoded is the earliest.
+func firstLocalAuditEvent(auditLogFile string) (*auditv1.Event, error) {
+	auditStream, err := openLocalAuditLog(auditLogFile)
+	if err != nil {
+		return nil, err
+	}
+	defer auditStream.Close()
+
+	scanner := bufio.NewScanner(auditStream)
+	for scanner.Scan() {
+		auditEvent := &auditv1.Event{}
+		if err := json.Unmarshal(scanner.Bytes(), auditEvent); err != nil {
+			continue
+		}
+		return auditEvent, nil
+	}
+	return nil, nil
+}
+
+func findLocalAuditLogs(auditLogDir string) ([]string, error) {
+	auditLogFiles := []string{}
+	err := filepath.WalkDir(auditLogDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if nonKubeAPIServerAuditLogDirs.Has(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.Contains(d.Name(), "audit") {
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".log") && !strings.HasSuffix(d.Name(), ".log.gz") {
+			return nil
+		}
+		auditLogFiles = append(auditLogFiles, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return auditLogFiles, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	return utilerrors.NewAggregate([]error{g.Reader.Close(), g.file.Close()})
+}
+
+func openLocalAuditLog(auditLogFile string) (io.ReadCloser, error) {
+	file, err := os.Open(auditLogFile)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(auditLogFile, ".gz") {
+		return file, nil
+	}
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to read %q: %w", auditLogFile, err)
+	}
+	return gzipFile{Reader: gzipReader, file: file}, nil
+}
@@ -0,0 +1,100 @@
+package auditloganalyzer
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/client-go/rest"
+)
+
+type recordingHandler struct {
+	lock     sync.Mutex
+	auditIDs []string
+}
+
+func (r *recordingHandler) HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.auditIDs = append(r.auditIDs, string(auditEvent.AuditID))
+}
+
+const (
+	firstAuditLine  = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"first","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/openshift-etcd/pods","verb":"list","user":{"username":"system:serviceaccount:openshift-etcd-operator:etcd-operator"},"objectRef":{"resource":"pods","namespace":"openshift-etcd","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2024-01-01T00:00:00.000000Z","stageTimestamp":"2024-01-01T00:00:00.010000Z"}`
+	secondAuditLine = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"second","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/e2e-test/pods","verb":"create","user":{"username":"system:admin"},"objectRef":{"resource":"pods","namespace":"e2e-test","apiVersion":"v1"},"responseStatus":{"code":500},"requestReceivedTimestamp":"2024-01-01T00:10:00.000000Z","stageTimestamp":"2024-01-01T00:10:00.010000Z"}`
+	thirdAuditLine  = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"third","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/e2e-test/pods","verb":"create","user":{"username":"system:admin"},"objectRef":{"resource":"pods","namespace":"e2e-test","apiVersion":"v1"},"responseStatus":{"code":201},"requestReceivedTimestamp":"2024-01-01T00:10:05.000000Z","stageTimestamp":"2024-01-01T00:10:05.010000Z"}`
+	otherAuditLine  = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"other","stage":"ResponseComplete","requestURI":"/apis/route.openshift.io/v1/routes","verb":"list","user":{"username":"system:admin"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2023-12-31T00:00:00.000000Z","stageTimestamp":"2023-12-31T00:00:00.010000Z"}`
+)
+
+func writeAuditLogs(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "kube-apiserver"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "openshift-apiserver"), 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kube-apiserver", "master-0-audit.log"), []byte(firstAuditLine+"\n\nnot json\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "openshift-apiserver", "master-0-audit.log"), []byte(otherAuditLine+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kube-apiserver", "README"), []byte("audit logs\n"), 0644))
+
+	gzipped, err := os.Create(filepath.Join(dir, "kube-apiserver", "master-1-audit-2024-01-01T00-10-00.000.log.gz"))
+	require.NoError(t, err)
+	gzipWriter := gzip.NewWriter(gzipped)
+	_, err = gzipWriter.Write([]byte(secondAuditLine + "\n" + thirdAuditLine + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, gzipped.Close())
+
+	return dir
+}
+
+func TestGetLocalKubeAuditLogSummary(t *testing.T) {
+	dir := writeAuditLogs(t)
+
+	handler := &recordingHandler{}
+	require.NoError(t, GetLocalKubeAuditLogSummary(context.TODO(), dir, nil, nil, []AuditEventHandler{handler}))
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, handler.auditIDs)
+
+	earliest, err := EarliestLocalAuditEvent(dir)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), earliest.UTC())
+
+	err = GetLocalKubeAuditLogSummary(context.TODO(), t.TempDir(), nil, nil, []AuditEventHandler{handler})
+	assert.ErrorContains(t, err, "no kube-apiserver audit logs found")
+}
+
+func TestOfflineAuditLogAnalyzer(t *testing.T) {
+	dir := writeAuditLogs(t)
+	storageDir := t.TempDir()
+	ctx := context.TODO()
+
+	// run-monitor passes the cluster it monitors, which must not be read when analyzing local audit logs
+	unreachable := &rest.Config{Host: "https://127.0.0.1:1"}
+	analyzer := NewOfflineAuditLogAnalyzer(dir, false)
+	require.NoError(t, analyzer.StartCollection(ctx, unreachable, nil))
+	intervals, _, err := analyzer.CollectData(ctx, storageDir, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	junits, err := analyzer.EvaluateTestsFromConstructedIntervals(ctx, intervals)
+	require.NoError(t, err)
+	require.NoError(t, analyzer.WriteContentToStorage(ctx, storageDir, "", intervals, nil))
+
+	junitNames := []string{}
+	for _, junit := range junits {
+		junitNames = append(junitNames, junit.Name)
+	}
+	assert.Contains(t, junitNames, "users in ns/openshift-etcd must not produce too many applies")
+	assert.Contains(t, junitNames, "users in ns/openshift-etcd-operator must not produce too many applies")
+	assert.NotContains(t, junitNames, "users in ns/e2e-test must not produce too many applies")
+
+	assert.Len(t, intervals, 1, "the 500 should produce an interval")
+
+	summaries, err := filepath.Glob(filepath.Join(storageDir, "audit-log-summary*"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, summaries)
+}
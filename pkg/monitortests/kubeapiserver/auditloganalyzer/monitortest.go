@@ -31,23 +31,64 @@ type auditLogAnalyzer struct {
 	invalidRequestsChecker        *invalidRequests
 	requestsDuringShutdownChecker *lateRequestTracking
 	violationChecker              *auditViolations
+	hotLoopChecker                *hotLoops
+	deprecatedAPIChecker          *deprecatedAPIUsage
+	rbacDenialChecker             *rbacDenials
+
+	// failOnRemovedAPIUsage fails, instead of flakes, when platform components use APIs removed in the next release.
+	failOnRemovedAPIUsage bool
 
 	countsForInstall *CountsForRun
+
+	// auditLogDir is a local directory of audit logs, like a must-gather, to analyze instead of the logs on the
+	// cluster's nodes.  When it is set, nothing is read from the cluster, even when one is being monitored.
+	auditLogDir        string
+	platformNamespaces *platformNamespaces
 }
 
-func NewAuditLogAnalyzer() monitortestframework.MonitorTest {
-	return &auditLogAnalyzer{
+// NewAuditLogAnalyzer analyzes the audit logs on the cluster's nodes, or with info.AuditLogDir, the kube-apiserver
+// audit logs in that directory.  Local audit logs are analyzed without the parts of the analysis that need the
+// cluster: the start of the cluster is estimated from the earliest audit event, there are no request counts for
+// install, and tech preview allowances do not apply.
+func NewAuditLogAnalyzer(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	w := &auditLogAnalyzer{
 		summarizer:                    NewAuditLogSummarizer(),
 		excessiveApplyChecker:         CheckForExcessiveApplies(),
 		invalidRequestsChecker:        CheckForInvalidMutations(),
 		requestsDuringShutdownChecker: CheckForRequestsDuringShutdown(),
 		violationChecker:              CheckForViolations(),
+		hotLoopChecker:                CheckForHotLoops(),
+		deprecatedAPIChecker:          CheckForDeprecatedAPIUsage(),
+		rbacDenialChecker:             CheckForRBACDenials(),
+		failOnRemovedAPIUsage:         info.FailOnRemovedAPIUsage,
+	}
+	if len(info.AuditLogDir) > 0 {
+		w.auditLogDir = info.AuditLogDir
+		w.platformNamespaces = TrackPlatformNamespaces()
 	}
+	return w
+}
+
+// NewOfflineAuditLogAnalyzer analyzes the kube-apiserver audit logs in auditLogDir without a cluster.
+func NewOfflineAuditLogAnalyzer(auditLogDir string, failOnRemovedAPIUsage bool) monitortestframework.MonitorTest {
+	return NewAuditLogAnalyzer(monitortestframework.MonitorTestInitializationInfo{
+		AuditLogDir:           auditLogDir,
+		FailOnRemovedAPIUsage: failOnRemovedAPIUsage,
+	})
 }
 
 func (w *auditLogAnalyzer) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	w.adminRESTConfig = adminRESTConfig
 
+	if len(w.auditLogDir) > 0 {
+		estimatedStartOfCluster, err := EarliestLocalAuditEvent(w.auditLogDir)
+		if err != nil {
+			return err
+		}
+		w.requestCountTracking = CountsOverTime(metav1.NewTime(estimatedStartOfCluster))
+		return nil
+	}
+
 	configClient, err := configclient.NewForConfig(w.adminRESTConfig)
 	if err != nil {
 		return err
@@ -74,42 +115,64 @@ func (w *auditLogAnalyzer) StartCollection(ctx context.Context, adminRESTConfig
 }
 
 func (w *auditLogAnalyzer) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
-	kubeClient, err := kubernetes.NewForConfig(w.adminRESTConfig)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	auditLogHandlers := []AuditEventHandler{
 		w.summarizer,
 		w.excessiveApplyChecker,
 		w.invalidRequestsChecker,
 		w.requestsDuringShutdownChecker,
 		w.violationChecker,
+		w.hotLoopChecker,
+		w.deprecatedAPIChecker,
+		w.rbacDenialChecker,
 	}
 	if w.requestCountTracking != nil {
 		auditLogHandlers = append(auditLogHandlers, w.requestCountTracking)
 	}
+	if w.platformNamespaces != nil {
+		auditLogHandlers = append(auditLogHandlers, w.platformNamespaces)
+	}
 
-	err = GetKubeAuditLogSummary(ctx, kubeClient, &beginning, &end, auditLogHandlers)
+	var err error
+	if len(w.auditLogDir) > 0 {
+		// must-gathers are not limited to a job run, so unless we are told otherwise, read everything.
+		var localBeginning, localEnd *time.Time
+		if !beginning.IsZero() {
+			localBeginning = &beginning
+		}
+		if !end.IsZero() {
+			localEnd = &end
+		}
+		err = GetLocalKubeAuditLogSummary(ctx, w.auditLogDir, localBeginning, localEnd, auditLogHandlers)
+	} else {
+		kubeClient, kubeErr := kubernetes.NewForConfig(w.adminRESTConfig)
+		if kubeErr != nil {
+			return nil, nil, kubeErr
+		}
+		err = GetKubeAuditLogSummary(ctx, kubeClient, &beginning, &end, auditLogHandlers)
+	}
 
-	retIntervals := monitorapi.Intervals{}
+	retIntervals := w.hotLoopChecker.Intervals()
+	retIntervals = append(retIntervals, w.rbacDenialChecker.Intervals()...)
 
 	if w.requestCountTracking != nil {
 		w.requestCountTracking.CountsForRun.TruncateDataAfterLastValue()
 
-		// now make a smaller line chart that only includes installation so the plot will be a little easier to read
-		configClient, err := configclient.NewForConfig(w.adminRESTConfig)
-		if err != nil {
-			return nil, nil, err
-		}
-		clusterVersion, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(clusterVersion.Status.History) > 0 {
-			installedLevel := clusterVersion.Status.History[len(clusterVersion.Status.History)-1]
-			if installedLevel.CompletionTime != nil {
-				w.countsForInstall = w.requestCountTracking.CountsForRun.SubsetDataAtTime(*installedLevel.CompletionTime)
+		// now make a smaller line chart that only includes installation so the plot will be a little easier to read.
+		// Local audit logs need not come from the cluster being monitored, so they have no chart for install.
+		if w.adminRESTConfig != nil && len(w.auditLogDir) == 0 {
+			configClient, err := configclient.NewForConfig(w.adminRESTConfig)
+			if err != nil {
+				return nil, nil, err
+			}
+			clusterVersion, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(clusterVersion.Status.History) > 0 {
+				installedLevel := clusterVersion.Status.History[len(clusterVersion.Status.History)-1]
+				if installedLevel.CompletionTime != nil {
+					w.countsForInstall = w.requestCountTracking.CountsForRun.SubsetDataAtTime(*installedLevel.CompletionTime)
+				}
 			}
 		}
 
@@ -188,7 +251,7 @@ func (w *auditLogAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Con
 		})
 	}
 
-	allPlatformNamespaces, err := watchnamespaces.GetAllPlatformNamespaces()
+	allPlatformNamespaces, err := w.getAllPlatformNamespaces()
 	if err != nil {
 		return nil, fmt.Errorf("problem getting platform namespaces: %w", err)
 	}
@@ -401,14 +464,30 @@ func (w *auditLogAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Con
 	}
 
 	ret = append(ret, w.violationChecker.CreateJunits()...)
+	ret = append(ret, w.hotLoopChecker.CreateJunits()...)
+	ret = append(ret, w.deprecatedAPIChecker.CreateJunits(w.failOnRemovedAPIUsage)...)
+	ret = append(ret, w.rbacDenialChecker.CreateJunits()...)
 
 	return ret, nil
 }
 
+func (w *auditLogAnalyzer) getAllPlatformNamespaces() ([]string, error) {
+	if w.platformNamespaces != nil {
+		return w.platformNamespaces.List(), nil
+	}
+	return watchnamespaces.GetAllPlatformNamespaces()
+}
+
 func (w *auditLogAnalyzer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
 	if currErr := WriteAuditLogSummary(storageDir, timeSuffix, w.summarizer.auditLogSummary); currErr != nil {
 		return currErr
 	}
+	if currErr := w.deprecatedAPIChecker.WriteReport(storageDir, timeSuffix); currErr != nil {
+		return currErr
+	}
+	if currErr := w.rbacDenialChecker.WriteReport(storageDir, timeSuffix); currErr != nil {
+		return currErr
+	}
 
 	if w.requestCountTracking != nil {
 		err := w.requestCountTracking.CountsForRun.WriteContentToStorage(storageDir, "request-counts-by-second", timeSuffix)
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -40,16 +41,7 @@ func GetKubeAuditLogSummary(ctx context.Context, kubeClient kubernetes.Interface
 		wg.Add(1)
 		go func(ctx context.Context, nodeName string) {
 			defer wg.Done()
-			var microBeginning, microEnd *metav1.MicroTime
-			if nil != beginning {
-				micro := metav1.NewMicroTime(*beginning)
-				microBeginning = &micro
-			}
-			if nil != end {
-				micro := metav1.NewMicroTime(*end)
-				microEnd = &micro
-			}
-			err := getNodeKubeAuditLogSummary(ctx, kubeClient, nodeName, microBeginning, microEnd, auditLogHandlers)
+			err := getNodeKubeAuditLogSummary(ctx, kubeClient, nodeName, toMicroTime(beginning), toMicroTime(end), auditLogHandlers)
 			if err != nil {
 				errCh <- err
 				return
@@ -70,6 +62,14 @@ func GetKubeAuditLogSummary(ctx context.Context, kubeClient kubernetes.Interface
 	return utilerrors.NewAggregate(errs)
 }
 
+func toMicroTime(t *time.Time) *metav1.MicroTime {
+	if t == nil {
+		return nil
+	}
+	micro := metav1.NewMicroTime(*t)
+	return &micro
+}
+
 type AuditEventHandler interface {
 	HandleAuditLogEvent(auditEvent *auditv1.Event, beginning, end *metav1.MicroTime)
 }
@@ -108,27 +108,7 @@ func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeNa
 				return
 			}
 
-			scanner := bufio.NewScanner(auditStream)
-			line := 0
-			for scanner.Scan() {
-				line++
-				auditLine := scanner.Bytes()
-
-				if len(auditLine) == 0 {
-					continue
-				}
-
-				auditEvent := &auditv1.Event{}
-				if err := json.Unmarshal(auditLine, auditEvent); err != nil {
-					fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
-					continue
-				}
-
-				for _, auditLogHandler := range auditLogHandlers {
-					auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
-				}
-			}
-
+			handleAuditLogStream(auditLogFilename, auditStream, beginning, end, auditLogHandlers)
 		}(ctx, auditLogFilename)
 	}
 	wg.Wait()
@@ -142,6 +122,31 @@ func getAuditLogSummary(ctx context.Context, client kubernetes.Interface, nodeNa
 	return utilerrors.NewAggregate(errs)
 }
 
+// handleAuditLogStream decodes every audit event in the stream and passes it to each handler.  Lines that cannot be
+// decoded are logged and skipped.
+func handleAuditLogStream(auditLogFilename string, auditStream io.Reader, beginning, end *metav1.MicroTime, auditLogHandlers []AuditEventHandler) {
+	scanner := bufio.NewScanner(auditStream)
+	line := 0
+	for scanner.Scan() {
+		line++
+		auditLine := scanner.Bytes()
+
+		if len(auditLine) == 0 {
+			continue
+		}
+
+		auditEvent := &auditv1.Event{}
+		if err := json.Unmarshal(auditLine, auditEvent); err != nil {
+			fmt.Printf("unable to decode %q line %d: %s to audit event: %v\n", auditLogFilename, line, string(auditLine), err)
+			continue
+		}
+
+		for _, auditLogHandler := range auditLogHandlers {
+			auditLogHandler.HandleAuditLogEvent(auditEvent, beginning, end)
+		}
+	}
+}
+
 func getAuditLogFilenames(ctx context.Context, client kubernetes.Interface, nodeName, apiserverName string) ([]string, error) {
 	allBytes, err := nodeaccess.GetNodeLogFile(ctx, client, nodeName, apiserverName)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"embed"
 	_ "embed"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -83,6 +84,8 @@ type podNetworkAvalibility struct {
 	namespaceName        string
 	targetService        *corev1.Service
 	kubeClient           kubernetes.Interface
+	// requiredPaths are the network paths between the nodes we placed pollers and targets on.
+	requiredPaths []networkPath
 }
 
 func NewPodNetworkAvalibilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
@@ -135,32 +138,41 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 		return err
 	}
 
-	// our pods tolerate masters, so create one for each of them.
+	// our pods tolerate masters, so create one for each schedulable node, pollers and targets on the same nodes, so
+	// that every network path between the nodes of the cluster is polled.
 	nodes, err := pna.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return err
 	}
-	numNodes := int32(len(nodes.Items))
+	placedNodes := schedulableNodes(nodes.Items, podNetworkTargetDeployment.Spec.Template.Spec.Tolerations)
+	nodeNames := []string{}
+	for _, node := range placedNodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	if len(nodeNames) == 0 {
+		return fmt.Errorf("none of the %d nodes can run the pollers", len(nodes.Items))
+	}
+	pna.requiredPaths = requiredNetworkPaths(nodeTopologies(placedNodes))
 
-	podNetworkToPodNetworkPollerDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(podNetworkToPodNetworkPollerDeployment, nodeNames)
 	podNetworkToPodNetworkPollerDeployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 	podNetworkToPodNetworkPollerDeployment = updateDeploymentENVs(podNetworkToPodNetworkPollerDeployment, deploymentID, "")
 	if _, err = pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), podNetworkToPodNetworkPollerDeployment, metav1.CreateOptions{}); err != nil {
 		return err
 	}
-	podNetworkToHostNetworkPollerDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(podNetworkToHostNetworkPollerDeployment, nodeNames)
 	podNetworkToHostNetworkPollerDeployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 	podNetworkToHostNetworkPollerDeployment = updateDeploymentENVs(podNetworkToHostNetworkPollerDeployment, deploymentID, "")
 	if _, err = pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), podNetworkToHostNetworkPollerDeployment, metav1.CreateOptions{}); err != nil {
 		return err
 	}
-	hostNetworkToPodNetworkPollerDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(hostNetworkToPodNetworkPollerDeployment, nodeNames)
 	hostNetworkToPodNetworkPollerDeployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 	hostNetworkToPodNetworkPollerDeployment = updateDeploymentENVs(hostNetworkToPodNetworkPollerDeployment, deploymentID, "")
 	if _, err = pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), hostNetworkToPodNetworkPollerDeployment, metav1.CreateOptions{}); err != nil {
 		return err
 	}
-	hostNetworkToHostNetworkPollerDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(hostNetworkToHostNetworkPollerDeployment, nodeNames)
 	hostNetworkToHostNetworkPollerDeployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 	hostNetworkToHostNetworkPollerDeployment = updateDeploymentENVs(hostNetworkToHostNetworkPollerDeployment, deploymentID, "")
 	if _, err = pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), hostNetworkToHostNetworkPollerDeployment, metav1.CreateOptions{}); err != nil {
@@ -169,7 +181,7 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 
 	// force the image to use the "normal" global mapping.
 	originalAgnhost := k8simage.GetOriginalImageConfigs()[k8simage.Agnhost]
-	podNetworkTargetDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(podNetworkTargetDeployment, nodeNames)
 	podNetworkTargetDeployment.Spec.Template.Spec.Containers[0].Image = image.LocationFor(originalAgnhost.GetE2EImage())
 	if _, err := pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), podNetworkTargetDeployment, metav1.CreateOptions{}); err != nil {
 		return err
@@ -180,7 +192,7 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 	}
 	pna.targetService = service
 
-	hostNetworkTargetDeployment.Spec.Replicas = &numNodes
+	placeOnNodes(hostNetworkTargetDeployment, nodeNames)
 	hostNetworkTargetDeployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 	if _, err := pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), hostNetworkTargetDeployment, metav1.CreateOptions{}); err != nil {
 		return err
@@ -196,7 +208,7 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 	}
 
 	for _, deployment := range []*appsv1.Deployment{podNetworkServicePollerDep, hostNetworkServicePollerDep} {
-		deployment.Spec.Replicas = &numNodes
+		placeOnNodes(deployment, nodeNames)
 		deployment.Spec.Template.Spec.Containers[0].Image = openshiftTestsImagePullSpec
 		deployment = updateDeploymentENVs(deployment, deploymentID, service.Spec.ClusterIP)
 		if _, err = pna.kubeClient.AppsV1().Deployments(pna.namespaceName).Create(context.Background(), deployment, metav1.CreateOptions{}); err != nil {
@@ -262,6 +274,14 @@ func (pna *podNetworkAvalibility) CollectData(ctx context.Context, storageDir st
 
 	}
 
+	// the pollers only know node names, add zones and the kind of network path so disruption can be grouped by route.
+	nodes, err := pna.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		addTopologyToIntervals(retIntervals, nodeTopologies(nodes.Items))
+	}
+
 	return retIntervals, junits, utilerrors.NewAggregate(errs)
 }
 
@@ -347,11 +367,52 @@ func (pna *podNetworkAvalibility) ConstructComputedIntervals(ctx context.Context
 }
 
 func (pna *podNetworkAvalibility) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
-	return nil, pna.notSupportedReason
+	if pna.notSupportedReason != nil {
+		return nil, pna.notSupportedReason
+	}
+
+	// pass/fail for each backend is decided by the disruption tests themselves, this only checks that every path was
+	// polled.  Pollers that produced nothing at all already fail the log collection tests.
+	matrix := computeDisruptionMatrix(finalIntervals)
+	if len(matrix) == 0 {
+		return nil, nil
+	}
+	testName := "[sig-network] pod network disruption by node to node path"
+	output := matrix.String()
+	if disrupted := matrix.disruptedPaths(); len(disrupted) > 0 {
+		output = fmt.Sprintf("disrupted paths:\n%s\n\n%s", strings.Join(disrupted, "\n"), output)
+	}
+	passing := &junitapi.JUnitTestCase{
+		Name:      testName,
+		SystemOut: output,
+	}
+	missing := matrix.missingPaths(pna.requiredPaths)
+	if len(missing) == 0 {
+		return []*junitapi.JUnitTestCase{passing}, nil
+	}
+	// pods can be evicted or unschedulable for a while during upgrades, flake until we see how often that loses a path.
+	return []*junitapi.JUnitTestCase{
+		{
+			Name:      testName,
+			SystemOut: output,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("no poller reached a target over these paths:\n%s", strings.Join(missing, "\n")),
+			},
+		},
+		passing,
+	}, nil
 }
 
 func (pna *podNetworkAvalibility) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
-	return pna.notSupportedReason
+	if pna.notSupportedReason != nil {
+		return pna.notSupportedReason
+	}
+
+	matrix := computeDisruptionMatrix(finalIntervals)
+	if len(matrix) == 0 {
+		return nil
+	}
+	return writeDisruptionMatrix(filepath.Join(storageDir, fmt.Sprintf("pod-network-disruption-matrix%s.json", timeSuffix)), matrix)
 }
 
 func (pna *podNetworkAvalibility) namespaceDeleted(ctx context.Context) (bool, error) {
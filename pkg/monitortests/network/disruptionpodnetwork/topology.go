@@ -0,0 +1,286 @@
+package disruptionpodnetwork
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// networkPath classifies the route a request takes between the poller node and the target node.  Disruption that
+// only shows up on one kind of path points at where to look: same-node at the local OVN bridge, cross-zone at cloud
+// routing, worker-to-master at control plane node reboots.
+type networkPath string
+
+const (
+	sameNodePath       networkPath = "same-node"
+	crossNodePath      networkPath = "cross-node"
+	crossZonePath      networkPath = "cross-zone"
+	workerToMasterPath networkPath = "worker-to-master"
+)
+
+var allNetworkPaths = []networkPath{sameNodePath, crossNodePath, crossZonePath, workerToMasterPath}
+
+// nodeTopology is what we need to know about a node to classify a path.
+type nodeTopology struct {
+	zone         string
+	controlPlane bool
+}
+
+func nodeTopologies(nodes []corev1.Node) map[string]nodeTopology {
+	ret := map[string]nodeTopology{}
+	for _, node := range nodes {
+		_, master := node.Labels["node-role.kubernetes.io/master"]
+		_, controlPlane := node.Labels["node-role.kubernetes.io/control-plane"]
+		ret[node.Name] = nodeTopology{
+			zone:         node.Labels[corev1.LabelTopologyZone],
+			controlPlane: master || controlPlane,
+		}
+	}
+	return ret
+}
+
+func classifyNetworkPath(sourceNode, targetNode string, nodes map[string]nodeTopology) networkPath {
+	source, target := nodes[sourceNode], nodes[targetNode]
+	switch {
+	case sourceNode == targetNode:
+		return sameNodePath
+	// zones first, a worker reaching a master in another zone crosses cloud routing like any other cross-zone request.
+	case source.zone != target.zone:
+		return crossZonePath
+	case !source.controlPlane && target.controlPlane:
+		return workerToMasterPath
+	default:
+		return crossNodePath
+	}
+}
+
+// schedulableNodes returns the nodes that pods with tolerations can be scheduled to.
+func schedulableNodes(nodes []corev1.Node, tolerations []corev1.Toleration) []corev1.Node {
+	ret := []corev1.Node{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		tolerated := true
+		for i := range node.Spec.Taints {
+			taint := &node.Spec.Taints[i]
+			if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+				continue
+			}
+			if !tolerationsTolerateTaint(tolerations, taint) {
+				tolerated = false
+				break
+			}
+		}
+		if tolerated {
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
+
+func tolerationsTolerateTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeOnNodes runs one pod of deployment on each of nodeNames.  The deployments already keep their pods on separate
+// nodes, pinning them to the nodes we classified makes sure every poller and target node pair, and so every network
+// path the cluster has, is polled.
+func placeOnNodes(deployment *appsv1.Deployment, nodeNames []string) {
+	replicas := int32(len(nodeNames))
+	deployment.Spec.Replicas = &replicas
+	if deployment.Spec.Template.Spec.Affinity == nil {
+		deployment.Spec.Template.Spec.Affinity = &corev1.Affinity{}
+	}
+	deployment.Spec.Template.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchFields: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "metadata.name",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   nodeNames,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// requiredNetworkPaths returns the paths between nodes that have both a poller and a target.
+func requiredNetworkPaths(nodes map[string]nodeTopology) []networkPath {
+	found := map[networkPath]bool{}
+	for source := range nodes {
+		for target := range nodes {
+			found[classifyNetworkPath(source, target, nodes)] = true
+		}
+	}
+	ret := []networkPath{}
+	for _, path := range allNetworkPaths {
+		if found[path] {
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
+
+// addTopologyToIntervals adds the zones of the source and target nodes and the network path to every interval from a
+// node to node poller.  The pollers cannot read nodes, so this is done here with the admin client.
+func addTopologyToIntervals(intervals monitorapi.Intervals, nodes map[string]nodeTopology) {
+	for i := range intervals {
+		keys := intervals[i].Locator.Keys
+		sourceNode, targetNode := keys[monitorapi.LocatorSourceNodeKey], keys[monitorapi.LocatorTargetNodeKey]
+		if len(sourceNode) == 0 || len(targetNode) == 0 {
+			continue
+		}
+		if zone := nodes[sourceNode].zone; len(zone) > 0 {
+			keys[monitorapi.LocatorSourceZoneKey] = zone
+		}
+		if zone := nodes[targetNode].zone; len(zone) > 0 {
+			keys[monitorapi.LocatorTargetZoneKey] = zone
+		}
+		keys[monitorapi.LocatorNetworkPathKey] = string(classifyNetworkPath(sourceNode, targetNode, nodes))
+	}
+}
+
+// pathDisruption summarizes one cell of the matrix.
+type pathDisruption struct {
+	// NodePairs is the number of source and target node pairs polled.
+	NodePairs int
+	// DisruptedNodePairs is the number of those pairs that saw any disruption.
+	DisruptedNodePairs int
+	// Disruption is the sum of disruption across every pair.
+	Disruption time.Duration
+}
+
+// disruptionMatrix is keyed by backend disruption name, like pod-to-host-new-connections, and then by network path.
+type disruptionMatrix map[string]map[networkPath]*pathDisruption
+
+func computeDisruptionMatrix(intervals monitorapi.Intervals) disruptionMatrix {
+	type nodePair struct {
+		backend string
+		path    networkPath
+		source  string
+		target  string
+	}
+	observed := map[nodePair]time.Duration{}
+	for _, interval := range intervals {
+		keys := interval.Locator.Keys
+		path := networkPath(keys[monitorapi.LocatorNetworkPathKey])
+		if len(path) == 0 || interval.Source != monitorapi.SourceDisruption {
+			continue
+		}
+		pair := nodePair{
+			backend: keys[monitorapi.LocatorBackendDisruptionNameKey],
+			path:    path,
+			source:  keys[monitorapi.LocatorSourceNodeKey],
+			target:  keys[monitorapi.LocatorTargetNodeKey],
+		}
+		disruption := observed[pair]
+		if interval.Level == monitorapi.Error && !interval.To.IsZero() {
+			disruption += interval.To.Sub(interval.From)
+		}
+		observed[pair] = disruption
+	}
+
+	ret := disruptionMatrix{}
+	for pair, disruption := range observed {
+		if _, ok := ret[pair.backend]; !ok {
+			ret[pair.backend] = map[networkPath]*pathDisruption{}
+		}
+		cell, ok := ret[pair.backend][pair.path]
+		if !ok {
+			cell = &pathDisruption{}
+			ret[pair.backend][pair.path] = cell
+		}
+		cell.NodePairs++
+		cell.Disruption += disruption
+		if disruption > 0 {
+			cell.DisruptedNodePairs++
+		}
+	}
+	return ret
+}
+
+// String renders the matrix as a table with a row per backend and a column per network path.
+func (m disruptionMatrix) String() string {
+	backends := []string{}
+	for backend := range m {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	header := []string{"BACKEND"}
+	for _, path := range allNetworkPaths {
+		header = append(header, strings.ToUpper(string(path)))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, backend := range backends {
+		row := []string{backend}
+		for _, path := range allNetworkPaths {
+			cell, ok := m[backend][path]
+			if !ok {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, fmt.Sprintf("%d/%d pairs %s", cell.DisruptedNodePairs, cell.NodePairs, cell.Disruption.Round(time.Second)))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func (m disruptionMatrix) disruptedPaths() []string {
+	ret := []string{}
+	for backend, paths := range m {
+		for path, cell := range paths {
+			if cell.DisruptedNodePairs > 0 {
+				ret = append(ret, fmt.Sprintf("%s %s", backend, path))
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// missingPaths returns the required paths that a backend never polled.
+func (m disruptionMatrix) missingPaths(required []networkPath) []string {
+	ret := []string{}
+	for backend, paths := range m {
+		for _, path := range required {
+			if cell, ok := paths[path]; !ok || cell.NodePairs == 0 {
+				ret = append(ret, fmt.Sprintf("%s %s", backend, path))
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func writeDisruptionMatrix(filename string, matrix disruptionMatrix) error {
+	jsonContent, err := json.MarshalIndent(matrix, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, jsonContent, 0644)
+}
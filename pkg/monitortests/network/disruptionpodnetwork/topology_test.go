@@ -0,0 +1,101 @@
+package disruptionpodnetwork
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestDisruptionMatrix(t *testing.T) {
+	node := func(name, zone string, master bool) corev1.Node {
+		labels := map[string]string{corev1.LabelTopologyZone: zone}
+		if master {
+			labels["node-role.kubernetes.io/master"] = ""
+		}
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	nodes := nodeTopologies([]corev1.Node{
+		node("master-a", "us-east-1a", true),
+		node("worker-a", "us-east-1a", false),
+		node("worker-a2", "us-east-1a", false),
+		node("worker-b", "us-east-1b", false),
+	})
+
+	assert.Equal(t, sameNodePath, classifyNetworkPath("worker-a", "worker-a", nodes))
+	assert.Equal(t, crossNodePath, classifyNetworkPath("worker-a", "worker-a2", nodes))
+	assert.Equal(t, crossZonePath, classifyNetworkPath("worker-a", "worker-b", nodes))
+	assert.Equal(t, workerToMasterPath, classifyNetworkPath("worker-a", "master-a", nodes))
+	assert.Equal(t, crossZonePath, classifyNetworkPath("worker-b", "master-a", nodes), "zones are checked before roles")
+	assert.Equal(t, crossNodePath, classifyNetworkPath("master-a", "worker-a", nodes))
+	assert.Equal(t, allNetworkPaths, requiredNetworkPaths(nodes))
+	assert.Equal(t, []networkPath{sameNodePath, crossNodePath}, requiredNetworkPaths(nodeTopologies([]corev1.Node{
+		node("worker-a", "us-east-1a", false),
+		node("worker-a2", "us-east-1a", false),
+	})))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := func(source, target string, level monitorapi.IntervalLevel, seconds int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceDisruption, level).
+			Locator(monitorapi.NewLocator().LocateDisruptionCheckBetweenNodes(
+				"pod-to-pod-new-connections", source+"-"+target, monitorapi.NewConnectionType, source, target)).
+			Message(monitorapi.NewMessage().HumanMessage("test")).
+			Build(start, start.Add(time.Duration(seconds)*time.Second))
+	}
+	intervals := monitorapi.Intervals{
+		interval("worker-a", "worker-a", monitorapi.Info, 60),
+		interval("worker-a", "worker-b", monitorapi.Error, 5),
+		interval("worker-a", "worker-b", monitorapi.Error, 3),
+		interval("worker-b", "worker-a", monitorapi.Info, 60),
+		interval("worker-a", "master-a", monitorapi.Info, 60),
+	}
+	addTopologyToIntervals(intervals, nodes)
+	assert.Equal(t, "us-east-1b", intervals[1].Locator.Keys[monitorapi.LocatorTargetZoneKey])
+	assert.Equal(t, string(crossZonePath), intervals[1].Locator.Keys[monitorapi.LocatorNetworkPathKey])
+
+	matrix := computeDisruptionMatrix(intervals)
+	require.Contains(t, matrix, "pod-to-pod-new-connections")
+	crossZone := matrix["pod-to-pod-new-connections"][crossZonePath]
+	require.NotNil(t, crossZone)
+	assert.Equal(t, 2, crossZone.NodePairs)
+	assert.Equal(t, 1, crossZone.DisruptedNodePairs)
+	assert.Equal(t, 8*time.Second, crossZone.Disruption)
+	assert.Equal(t, 0, matrix["pod-to-pod-new-connections"][sameNodePath].DisruptedNodePairs)
+	assert.Equal(t, []string{"pod-to-pod-new-connections cross-zone"}, matrix.disruptedPaths())
+	assert.Contains(t, matrix.String(), "1/2 pairs 8s")
+	assert.Empty(t, matrix.missingPaths([]networkPath{sameNodePath, crossZonePath, workerToMasterPath}))
+	assert.Equal(t, []string{"pod-to-pod-new-connections cross-node"}, matrix.missingPaths(allNetworkPaths))
+}
+
+func TestPlaceOnNodes(t *testing.T) {
+	tolerations := []corev1.Toleration{
+		{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}
+	node := func(name string, unschedulable bool, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: corev1.NodeSpec{Unschedulable: unschedulable, Taints: taints}}
+	}
+	nodes := schedulableNodes([]corev1.Node{
+		node("master-a", false, corev1.Taint{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}),
+		node("worker-a", false, corev1.Taint{Key: "example.com/soft", Effect: corev1.TaintEffectPreferNoSchedule}),
+		node("infra-a", false, corev1.Taint{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}),
+		node("worker-b", true),
+	}, tolerations)
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"master-a", "worker-a"}, names)
+
+	deployment := podNetworkTargetDeployment.DeepCopy()
+	placeOnNodes(deployment, names)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	require.NotNil(t, deployment.Spec.Template.Spec.Affinity.PodAntiAffinity, "pods stay on separate nodes")
+	terms := deployment.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, names, terms[0].MatchFields[0].Values)
+}
@@ -0,0 +1,261 @@
+package machineconfigrollout
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	exutil "github.com/openshift/origin/test/extended/util"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// queryResults is how long a single node in each pool took to roll out a machine config, aggregated over job runs
+// the same way as the alert and disruption data.  It is refreshed with
+// `openshift-tests historical-data --kind machine-config-rollout`.
+//
+//go:embed query_results.json
+var queryResults []byte
+
+var (
+	readResults    sync.Once
+	historicalData *historicaldata.MachineConfigRolloutBestMatcher
+)
+
+func GetHistoricalData() *historicaldata.MachineConfigRolloutBestMatcher {
+	readResults.Do(
+		func() {
+			var err error
+			historicalData, err = historicaldata.NewMachineConfigRolloutMatcher(queryResults)
+			if err != nil {
+				panic(err)
+			}
+		})
+
+	return historicalData
+}
+
+// OverrideHistoricalData replaces the embedded historical data with the content of a file in the same format.  It must
+// be called before the monitor test is evaluated.
+func OverrideHistoricalData(historicalJSON []byte) error {
+	matcher, err := historicaldata.NewMachineConfigRolloutMatcher(historicalJSON)
+	if err != nil {
+		return err
+	}
+	readResults.Do(func() {})
+	historicalData = matcher
+	return nil
+}
+
+// wellKnownPools always get a test, so the test names do not depend on whether the run rolled out a config.
+var wellKnownPools = []string{"master", "worker"}
+
+type monitorTest struct {
+	adminRESTConfig    *rest.Config
+	kubeClient         kubernetes.Interface
+	historicalData     *historicaldata.MachineConfigRolloutBestMatcher
+	notSupportedReason error
+
+	// instanceTypes maps node names to their instance type, so slow nodes can be tied to the hardware they run on.
+	instanceTypes map[string]string
+	nodeRollouts  []nodeRollout
+	poolRollouts  []poolRollout
+}
+
+// NewMachineConfigRolloutMonitorTest times every node moving to a new rendered config, broken down into drain,
+// reboot, and kubelet ready, and produces an interval for each pool rollout.  Once there is historical data for
+// rollouts, the time each node took is compared to it for the pool and job type.
+func NewMachineConfigRolloutMonitorTest() monitortestframework.MonitorTest {
+	return &monitorTest{
+		instanceTypes: map[string]string{},
+	}
+}
+
+func (test *monitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	test.adminRESTConfig = adminRESTConfig
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	isMicroShift, err := exutil.IsMicroShiftCluster(kubeClient)
+	if err != nil {
+		return fmt.Errorf("unable to determine if cluster is MicroShift: %v", err)
+	}
+	if isMicroShift {
+		test.notSupportedReason = &monitortestframework.NotSupportedError{
+			Reason: "platform MicroShift not supported",
+		}
+		return test.notSupportedReason
+	}
+	test.kubeClient = kubeClient
+	test.historicalData = GetHistoricalData()
+	return nil
+}
+
+func (test *monitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, nil, test.notSupportedReason
+	}
+
+	nodes, err := test.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// the rollout is still worth timing without the instance types
+		logrus.WithError(err).Warn("unable to list nodes for their instance types")
+		return nil, nil, nil
+	}
+	for _, node := range nodes.Items {
+		test.instanceTypes[node.Name] = node.Labels[corev1.LabelInstanceTypeStable]
+	}
+	return nil, nil, nil
+}
+
+func (test *monitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if test.notSupportedReason != nil {
+		return nil, test.notSupportedReason
+	}
+
+	test.nodeRollouts = nodeRolloutsFrom(startingIntervals, test.instanceTypes, end)
+	test.poolRollouts = poolRolloutsFrom(test.nodeRollouts)
+	return poolRolloutIntervals(test.poolRollouts), nil
+}
+
+func (test *monitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if test.notSupportedReason != nil {
+		return nil, test.notSupportedReason
+	}
+	// the per node breakdown is in the artifact either way, the pool tests wait for rollout durations to be checked
+	// in or passed with --historical-data-file
+	if test.historicalData == nil || len(test.historicalData.HistoricalData) == 0 {
+		return nil, nil
+	}
+
+	jobType, err := platformidentification.GetJobType(ctx, test.adminRESTConfig)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to determine job type for machine config rollouts, skipping the comparison to historical data")
+	}
+
+	return evaluateNodeRollouts(test.nodeRollouts, jobType, test.historicalData), nil
+}
+
+func evaluateNodeRollouts(nodeRollouts []nodeRollout, jobType *platformidentification.JobType, historicalData *historicaldata.MachineConfigRolloutBestMatcher) []*junitapi.JUnitTestCase {
+	pools := sets.New[string](wellKnownPools...)
+	byPool := map[string][]nodeRollout{}
+	for _, curr := range nodeRollouts {
+		pools.Insert(curr.Pool)
+		byPool[curr.Pool] = append(byPool[curr.Pool], curr)
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	for _, pool := range sets.List(pools) {
+		junits = append(junits, comparePoolToHistoricalData(pool, byPool[pool], jobType, historicalData)...)
+	}
+	return junits
+}
+
+func comparePoolToHistoricalData(pool string, nodeRollouts []nodeRollout, jobType *platformidentification.JobType, historicalData *historicaldata.MachineConfigRolloutBestMatcher) []*junitapi.JUnitTestCase {
+	testName := fmt.Sprintf("[bz-Machine Config Operator] nodes in pool/%s should roll out machine configs within historical durations", pool)
+
+	skip := func(message string) []*junitapi.JUnitTestCase {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:        testName,
+				SkipMessage: &junitapi.SkipMessage{Message: message},
+			},
+		}
+	}
+	if len(nodeRollouts) == 0 {
+		return skip(fmt.Sprintf("no nodes in pool/%s rolled out a machine config", pool))
+	}
+	if jobType == nil || historicalData == nil {
+		return skip("unable to determine job type")
+	}
+
+	historical, details, err := historicalData.BestMatch(historicaldata.MachineConfigRolloutDataKey{
+		PoolName: pool,
+		JobType:  *jobType,
+	})
+	if err != nil {
+		return skip(fmt.Sprintf("unable to find historical data: %v", err))
+	}
+	if historical == (historicaldata.Percentiles{}) {
+		return skip(fmt.Sprintf("no historical data %s", details))
+	}
+
+	slow := []string{}
+	for _, curr := range nodeRollouts {
+		if curr.TotalSeconds <= historical.P99 {
+			continue
+		}
+		message := curr.String()
+		if !curr.Completed {
+			message += ", and had not finished by the end of the run"
+		}
+		slow = append(slow, message)
+	}
+
+	summary := fmt.Sprintf("%d nodes rolled out, historical P99 is %s %s", len(nodeRollouts), secondsToDuration(historical.P99), details)
+	passing := &junitapi.JUnitTestCase{
+		Name:      testName,
+		SystemOut: summary,
+	}
+	if len(slow) == 0 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	// one slow node is usually its instance type or a slow reboot on that run, not the machine config operator, so
+	// this lists the slow nodes and flakes
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: strings.Join(slow, "\n"),
+				Output:  summary,
+			},
+		},
+		passing,
+	}
+}
+
+// rolloutData is the content of the machine-config-rollout artifact.
+type rolloutData struct {
+	Pools []poolRollout `json:"pools"`
+	Nodes []nodeRollout `json:"nodes"`
+}
+
+func (test *monitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if test.notSupportedReason != nil {
+		return test.notSupportedReason
+	}
+
+	return writeRolloutData(filepath.Join(storageDir, fmt.Sprintf("machine-config-rollout%s.json", timeSuffix)), rolloutData{
+		Pools: test.poolRollouts,
+		Nodes: test.nodeRollouts,
+	})
+}
+
+func writeRolloutData(filename string, data rolloutData) error {
+	jsonContent, err := json.MarshalIndent(data, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, jsonContent, 0644)
+}
+
+func (test *monitorTest) Cleanup(ctx context.Context) error {
+	return test.notSupportedReason
+}
@@ -0,0 +1,267 @@
+package machineconfigrollout
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// nodeRollout is a single node moving to a new rendered config, from the MachineConfigChange to the
+// MachineConfigReached the node monitor observed.  The phases come from the events the MCD and kubelet report on the
+// node, since events are best effort a phase is zero when its events were missed.
+type nodeRollout struct {
+	Node         string    `json:"node"`
+	Roles        string    `json:"roles,omitempty"`
+	InstanceType string    `json:"instanceType,omitempty"`
+	Pool         string    `json:"pool"`
+	Config       string    `json:"config"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	// Completed is false when the node had not reached the config by the end of the run.
+	Completed bool `json:"completed"`
+
+	DrainSeconds        float64 `json:"drainSeconds"`
+	RebootSeconds       float64 `json:"rebootSeconds"`
+	KubeletReadySeconds float64 `json:"kubeletReadySeconds"`
+	TotalSeconds        float64 `json:"totalSeconds"`
+}
+
+func (r nodeRollout) String() string {
+	return fmt.Sprintf("node/%s (%s) took %s to roll out %s: drain %s, reboot %s, kubelet ready %s",
+		r.Node, r.InstanceType, secondsToDuration(r.TotalSeconds), r.Config,
+		secondsToDuration(r.DrainSeconds), secondsToDuration(r.RebootSeconds), secondsToDuration(r.KubeletReadySeconds))
+}
+
+// nodePhases are the times the MCD and kubelet reported for each step of the update.
+//
+//	Cordon/Drain -> OSUpdateStarted/Reboot: drain
+//	Reboot -> Starting (kubelet): reboot
+//	Starting -> node Ready: kubelet ready
+type nodePhases struct {
+	drainStart   time.Time
+	drainEnd     time.Time
+	rebootStart  time.Time
+	kubeletStart time.Time
+	ready        time.Time
+}
+
+func (p *nodePhases) observe(interval monitorapi.Interval) {
+	switch {
+	case interval.Source == monitorapi.SourceKubeEvent:
+		switch interval.Message.Reason {
+		case "Cordon", "Drain":
+			if p.drainStart.IsZero() {
+				p.drainStart = interval.From
+			}
+		case "OSUpdateStarted":
+			if !p.drainStart.IsZero() && p.drainEnd.IsZero() {
+				p.drainEnd = interval.From
+			}
+		case "Reboot":
+			if !p.drainStart.IsZero() && p.drainEnd.IsZero() {
+				p.drainEnd = interval.From
+			}
+			if p.rebootStart.IsZero() {
+				p.rebootStart = interval.From
+			}
+		case "Starting":
+			if !p.rebootStart.IsZero() && p.kubeletStart.IsZero() {
+				p.kubeletStart = interval.From
+			}
+		}
+	case interval.Source == monitorapi.SourceNodeMonitor && interval.Message.Reason == "Ready":
+		if !p.kubeletStart.IsZero() && p.ready.IsZero() {
+			p.ready = interval.From
+		}
+	}
+}
+
+func secondsBetween(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Seconds()
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
+
+// poolFromRenderedConfig returns the pool a rendered config belongs to, the MCO names them rendered-<pool>-<hash>.
+func poolFromRenderedConfig(config string) (string, bool) {
+	if !strings.HasPrefix(config, "rendered-") {
+		return "", false
+	}
+	trimmed := strings.TrimPrefix(config, "rendered-")
+	i := strings.LastIndex(trimmed, "-")
+	if i <= 0 {
+		return "", false
+	}
+	return trimmed[:i], true
+}
+
+// nodeRolloutsFrom pairs the MachineConfigChange and MachineConfigReached intervals of every node and times the
+// phases in between.  A node still updating at the end of the run is reported as not completed.
+func nodeRolloutsFrom(intervals monitorapi.Intervals, instanceTypes map[string]string, end time.Time) []nodeRollout {
+	type openRollout struct {
+		rollout nodeRollout
+		phases  nodePhases
+	}
+	finish := func(curr *openRollout, to time.Time, completed bool) nodeRollout {
+		ret := curr.rollout
+		ret.To = to
+		ret.Completed = completed
+		ret.DrainSeconds = secondsBetween(curr.phases.drainStart, curr.phases.drainEnd)
+		ret.RebootSeconds = secondsBetween(curr.phases.rebootStart, curr.phases.kubeletStart)
+		ret.KubeletReadySeconds = secondsBetween(curr.phases.kubeletStart, curr.phases.ready)
+		ret.TotalSeconds = secondsBetween(ret.From, to)
+		return ret
+	}
+
+	ret := []nodeRollout{}
+	open := map[string]*openRollout{}
+	for _, interval := range intervals {
+		node, ok := interval.Locator.Keys[monitorapi.LocatorNodeKey]
+		if !ok || interval.Locator.Type != monitorapi.LocatorTypeNode {
+			continue
+		}
+
+		if interval.Source == monitorapi.SourceNodeMonitor {
+			switch interval.Message.Reason {
+			case monitorapi.MachineConfigChangeReason:
+				if _, ok := open[node]; ok {
+					// a new config before the last one was reached, the clock keeps running
+					continue
+				}
+				config := interval.Message.Annotations[monitorapi.AnnotationConfig]
+				roles := interval.Message.Annotations[monitorapi.AnnotationRoles]
+				pool, ok := poolFromRenderedConfig(config)
+				if !ok {
+					pool = roles
+				}
+				open[node] = &openRollout{
+					rollout: nodeRollout{
+						Node:         node,
+						Roles:        roles,
+						InstanceType: instanceTypes[node],
+						Pool:         pool,
+						Config:       config,
+						From:         interval.From,
+					},
+				}
+				continue
+			case monitorapi.MachineConfigReachedReason:
+				curr, ok := open[node]
+				if !ok {
+					continue
+				}
+				if config := interval.Message.Annotations[monitorapi.AnnotationConfig]; len(config) > 0 {
+					curr.rollout.Config = config
+				}
+				ret = append(ret, finish(curr, interval.From, true))
+				delete(open, node)
+				continue
+			}
+		}
+
+		if curr, ok := open[node]; ok {
+			curr.phases.observe(interval)
+		}
+	}
+	for _, curr := range open {
+		ret = append(ret, finish(curr, end, false))
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].From.Equal(ret[j].From) {
+			return ret[i].From.Before(ret[j].From)
+		}
+		return ret[i].Node < ret[j].Node
+	})
+	return ret
+}
+
+// poolRollout is a pool moving every one of its nodes to a rendered config.
+type poolRollout struct {
+	Pool      string    `json:"pool"`
+	Config    string    `json:"config"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Nodes     int       `json:"nodes"`
+	Completed bool      `json:"completed"`
+	// SlowestNode is the node that took the longest, usually the one holding up the pool.
+	SlowestNode string `json:"slowestNode"`
+}
+
+func poolRolloutsFrom(nodeRollouts []nodeRollout) []poolRollout {
+	pools := map[string]*poolRollout{}
+	slowest := map[string]float64{}
+	for _, curr := range nodeRollouts {
+		key := curr.Pool + "/" + curr.Config
+		pool, ok := pools[key]
+		if !ok {
+			pool = &poolRollout{
+				Pool:      curr.Pool,
+				Config:    curr.Config,
+				From:      curr.From,
+				To:        curr.To,
+				Completed: true,
+			}
+			pools[key] = pool
+		}
+		if curr.From.Before(pool.From) {
+			pool.From = curr.From
+		}
+		if curr.To.After(pool.To) {
+			pool.To = curr.To
+		}
+		pool.Nodes++
+		pool.Completed = pool.Completed && curr.Completed
+		if curr.TotalSeconds >= slowest[key] {
+			slowest[key] = curr.TotalSeconds
+			pool.SlowestNode = curr.Node
+		}
+	}
+
+	ret := []poolRollout{}
+	for _, pool := range pools {
+		ret = append(ret, *pool)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].From.Equal(ret[j].From) {
+			return ret[i].From.Before(ret[j].From)
+		}
+		return ret[i].Pool < ret[j].Pool
+	})
+	return ret
+}
+
+func poolRolloutIntervals(poolRollouts []poolRollout) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, pool := range poolRollouts {
+		level := monitorapi.Info
+		message := fmt.Sprintf("%d nodes rolled out %s in %s, slowest was node/%s",
+			pool.Nodes, pool.Config, pool.To.Sub(pool.From).Round(time.Second), pool.SlowestNode)
+		if !pool.Completed {
+			level = monitorapi.Warning
+			message = fmt.Sprintf("%d nodes were still rolling out %s at the end of the run", pool.Nodes, pool.Config)
+		}
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceMachineConfigRollout, level).
+				Locator(monitorapi.NewLocator().MachineConfigPool(pool.Pool)).
+				Message(monitorapi.NewMessage().
+					Reason(monitorapi.ReasonMachineConfigPoolRollout).
+					Constructed(monitorapi.ConstructionOwnerPoolRollout).
+					WithAnnotation(monitorapi.AnnotationConfig, pool.Config).
+					WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(pool.Nodes)).
+					HumanMessage(message),
+				).
+				Display().
+				Build(pool.From, pool.To))
+	}
+	return ret
+}
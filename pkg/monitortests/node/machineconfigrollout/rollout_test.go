@@ -0,0 +1,187 @@
+package machineconfigrollout
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func configInterval(node string, reason monitorapi.IntervalReason, config string, at time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason(reason).
+			WithAnnotations(map[monitorapi.AnnotationKey]string{
+				monitorapi.AnnotationRoles:  "worker",
+				monitorapi.AnnotationConfig: config,
+			}).
+			HumanMessage("config change")).
+		Build(at, at)
+}
+
+func nodeEvent(node string, source monitorapi.IntervalSource, reason monitorapi.IntervalReason, at time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(source, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason(reason).HumanMessage(string(reason))).
+		Build(at, at)
+}
+
+func workerRollout(node string, start time.Time, reboot time.Duration) monitorapi.Intervals {
+	return monitorapi.Intervals{
+		configInterval(node, monitorapi.MachineConfigChangeReason, "rendered-worker-abc123", start),
+		nodeEvent(node, monitorapi.SourceKubeEvent, "Cordon", start.Add(10*time.Second)),
+		nodeEvent(node, monitorapi.SourceKubeEvent, "Drain", start.Add(11*time.Second)),
+		nodeEvent(node, monitorapi.SourceKubeEvent, "OSUpdateStarted", start.Add(time.Minute)),
+		nodeEvent(node, monitorapi.SourceKubeEvent, "Reboot", start.Add(2*time.Minute)),
+		nodeEvent(node, monitorapi.SourceKubeEvent, "Starting", start.Add(2*time.Minute+reboot)),
+		nodeEvent(node, monitorapi.SourceNodeMonitor, "Ready", start.Add(2*time.Minute+reboot+30*time.Second)),
+		configInterval(node, monitorapi.MachineConfigReachedReason, "rendered-worker-abc123", start.Add(3*time.Minute+reboot)),
+	}
+}
+
+func TestNodeRollouts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	intervals := workerRollout("worker-a", start, 2*time.Minute)
+	intervals = append(intervals, workerRollout("worker-b", start.Add(6*time.Minute), 20*time.Minute)...)
+	// the node was ready before the update, that is not the kubelet coming back
+	intervals = append(intervals, nodeEvent("worker-c", monitorapi.SourceNodeMonitor, "Ready", start))
+	intervals = append(intervals, configInterval("worker-c", monitorapi.MachineConfigChangeReason, "rendered-worker-abc123", start.Add(40*time.Minute)))
+	nodeRollouts := nodeRolloutsFrom(intervals, map[string]string{"worker-b": "m6a.xlarge"}, end)
+
+	require.Len(t, nodeRollouts, 3)
+	assert.Equal(t, nodeRollout{
+		Node:                "worker-a",
+		Roles:               "worker",
+		Pool:                "worker",
+		Config:              "rendered-worker-abc123",
+		From:                start,
+		To:                  start.Add(5 * time.Minute),
+		Completed:           true,
+		DrainSeconds:        50,
+		RebootSeconds:       120,
+		KubeletReadySeconds: 30,
+		TotalSeconds:        300,
+	}, nodeRollouts[0])
+	assert.Equal(t, "m6a.xlarge", nodeRollouts[1].InstanceType)
+	assert.Equal(t, float64(20*60), nodeRollouts[1].RebootSeconds)
+	assert.False(t, nodeRollouts[2].Completed)
+	assert.Equal(t, end, nodeRollouts[2].To)
+	assert.Zero(t, nodeRollouts[2].KubeletReadySeconds)
+
+	poolRollouts := poolRolloutsFrom(nodeRollouts)
+	require.Len(t, poolRollouts, 1)
+	assert.Equal(t, 3, poolRollouts[0].Nodes)
+	assert.False(t, poolRollouts[0].Completed)
+	assert.Equal(t, "worker-b", poolRollouts[0].SlowestNode)
+
+	poolIntervals := poolRolloutIntervals(poolRollouts)
+	require.Len(t, poolIntervals, 1)
+	assert.Equal(t, "worker", poolIntervals[0].Locator.Keys[monitorapi.LocatorMachineConfigPoolKey])
+	assert.Equal(t, start, poolIntervals[0].From)
+	assert.Equal(t, end, poolIntervals[0].To)
+	assert.Equal(t, "3", poolIntervals[0].Message.Annotations[monitorapi.AnnotationCount])
+}
+
+func TestPoolFromRenderedConfig(t *testing.T) {
+	pool, ok := poolFromRenderedConfig("rendered-worker-4a5d8cb7e3a1d2c0f9b6e8d7c6b5a4f3")
+	assert.True(t, ok)
+	assert.Equal(t, "worker", pool)
+	pool, ok = poolFromRenderedConfig("rendered-worker-rt-abc")
+	assert.True(t, ok)
+	assert.Equal(t, "worker-rt", pool)
+	_, ok = poolFromRenderedConfig("00-worker")
+	assert.False(t, ok)
+}
+
+func TestEvaluateNodeRollouts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	intervals := workerRollout("worker-a", start, 2*time.Minute)
+	intervals = append(intervals, workerRollout("worker-b", start.Add(6*time.Minute), 20*time.Minute)...)
+	// worker-a took 5m and worker-b 23m
+	nodeRollouts := nodeRolloutsFrom(intervals, map[string]string{"worker-b": "m6a.xlarge"}, start.Add(time.Hour))
+	upgrade := platformidentification.JobType{Release: "4.18", FromRelease: "4.17", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+
+	tests := []struct {
+		name          string
+		workerHistory historicaldata.Percentiles
+		jobType       *platformidentification.JobType
+		// expected outcome of the worker pool test, the master pool rolled out nothing and always skips
+		expectSkip string
+		expectSlow []string
+	}{
+		{
+			name:          "every node within history",
+			workerHistory: historicaldata.Percentiles{P99: 30 * 60, JobRuns: 1000},
+			jobType:       &upgrade,
+		},
+		{
+			name:          "one node slower than history",
+			workerHistory: historicaldata.Percentiles{P99: 10 * 60, JobRuns: 1000},
+			jobType:       &upgrade,
+			expectSlow:    []string{"node/worker-b (m6a.xlarge) took 23m0s to roll out rendered-worker-abc123: drain 50s, reboot 20m0s, kubelet ready 30s"},
+		},
+		{
+			name:          "too few job runs",
+			workerHistory: historicaldata.Percentiles{P99: 10 * 60, JobRuns: 20},
+			jobType:       &upgrade,
+			expectSkip:    "no historical data",
+		},
+		{
+			name:          "unknown job type",
+			workerHistory: historicaldata.Percentiles{P99: 10 * 60, JobRuns: 1000},
+			expectSkip:    "unable to determine job type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := historicaldata.NewMachineConfigRolloutMatcherWithHistoricalData(map[historicaldata.MachineConfigRolloutDataKey]historicaldata.Percentiles{
+				{PoolName: "worker", JobType: upgrade}: tt.workerHistory,
+			})
+			junits := evaluateNodeRollouts(nodeRollouts, tt.jobType, matcher)
+
+			require.NotEmpty(t, junits)
+			assert.Contains(t, junits[0].Name, "pool/master")
+			require.NotNil(t, junits[0].SkipMessage)
+			assert.Equal(t, "no nodes in pool/master rolled out a machine config", junits[0].SkipMessage.Message)
+
+			worker := junits[1:]
+			for _, junit := range worker {
+				assert.Contains(t, junit.Name, "pool/worker")
+			}
+			switch {
+			case len(tt.expectSkip) > 0:
+				require.Len(t, worker, 1)
+				require.NotNil(t, worker[0].SkipMessage)
+				assert.Contains(t, worker[0].SkipMessage.Message, tt.expectSkip)
+			case len(tt.expectSlow) > 0:
+				require.Len(t, worker, 2, "slow nodes flake")
+				require.NotNil(t, worker[0].FailureOutput)
+				assert.Equal(t, strings.Join(tt.expectSlow, "\n"), worker[0].FailureOutput.Message)
+				assert.Nil(t, worker[1].FailureOutput)
+			default:
+				require.Len(t, worker, 1)
+				assert.Nil(t, worker[0].FailureOutput)
+				assert.Nil(t, worker[0].SkipMessage)
+			}
+		})
+	}
+}
+
+func TestNoPoolTestsWithoutHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	test := &monitorTest{
+		historicalData: historicaldata.NewMachineConfigRolloutMatcherWithHistoricalData(nil),
+		nodeRollouts:   nodeRolloutsFrom(workerRollout("worker-a", start, time.Hour), nil, start.Add(2*time.Hour)),
+	}
+	junits, err := test.EvaluateTestsFromConstructedIntervals(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, junits, "pool tests wait for rollout history")
+}
@@ -0,0 +1,172 @@
+package pdbanalyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// evictionFailure is an eviction the API refused because of a PDB, as reported in an event.  The
+// machine-config-controller reports them on the node it is draining, like:
+//
+//	error when evicting pods/"router-default-5f7c9f8b8-abcde" -n "openshift-ingress" (will retry after 5s): Cannot evict pod as it would violate the pod's disruption budget.
+type evictionFailure struct {
+	namespace string
+	pod       string
+	node      string
+	at        time.Time
+}
+
+var evictingPodRegex = regexp.MustCompile(`evicting pods/"([^"]+)" -n "([^"]+)"`)
+
+func evictionFailuresFrom(intervals monitorapi.Intervals) []evictionFailure {
+	ret := []evictionFailure{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceKubeEvent || !strings.Contains(interval.Message.HumanMessage, "disruption budget") {
+			continue
+		}
+		failure := evictionFailure{
+			namespace: interval.Locator.Keys[monitorapi.LocatorNamespaceKey],
+			node:      interval.Locator.Keys[monitorapi.LocatorNodeKey],
+			at:        interval.From,
+		}
+		if match := evictingPodRegex.FindStringSubmatch(interval.Message.HumanMessage); match != nil {
+			failure.pod = match[1]
+			failure.namespace = match[2]
+		}
+		if len(failure.namespace) == 0 {
+			continue
+		}
+		ret = append(ret, failure)
+	}
+	return ret
+}
+
+// nodeUpdate is the time between the MachineConfigChange and MachineConfigReached of a node, the drain is part of it.
+type nodeUpdate struct {
+	node string
+	from time.Time
+	to   time.Time
+}
+
+func nodeUpdatesFrom(intervals monitorapi.Intervals, end time.Time) []nodeUpdate {
+	ret := []nodeUpdate{}
+	open := map[string]time.Time{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceNodeMonitor {
+			continue
+		}
+		node := interval.Locator.Keys[monitorapi.LocatorNodeKey]
+		switch interval.Message.Reason {
+		case monitorapi.MachineConfigChangeReason:
+			if _, ok := open[node]; !ok {
+				open[node] = interval.From
+			}
+		case monitorapi.MachineConfigReachedReason:
+			if from, ok := open[node]; ok {
+				ret = append(ret, nodeUpdate{node: node, from: from, to: interval.From})
+				delete(open, node)
+			}
+		}
+	}
+	for node, from := range open {
+		ret = append(ret, nodeUpdate{node: node, from: from, to: end})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].from.Equal(ret[j].from) {
+			return ret[i].from.Before(ret[j].from)
+		}
+		return ret[i].node < ret[j].node
+	})
+	return ret
+}
+
+// podLabels returns the labels of every recorded pod by namespace and name.
+func podLabels(recordedPods monitorapi.InstanceMap) map[string]labels.Set {
+	ret := map[string]labels.Set{}
+	for _, obj := range recordedPods {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			ret[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = pod.Labels
+		}
+	}
+	return ret
+}
+
+// refusedBy is true when the PDB with selector is the one that refused the eviction.  Namespaces often have more than
+// one PDB, so the evicted pod must be selected by it.  Evictions of pods we never saw, or of unnamed pods, can only be
+// matched to the namespace.
+func (f evictionFailure) refusedBy(namespace string, selector labels.Selector, pods map[string]labels.Set) bool {
+	if f.namespace != namespace {
+		return false
+	}
+	selected, ok := pods[fmt.Sprintf("%s/%s", f.namespace, f.pod)]
+	if len(f.pod) == 0 || !ok || selector == nil {
+		return true
+	}
+	return selector.Matches(selected)
+}
+
+// drainsBlockedByPDBs correlates the EvictionBlocked intervals with node updates.  A PDB blocked the drain of a node
+// when it was blocking evictions during the update and the eviction of a pod it selects was refused on that node.
+// selectors are the selectors of the PDBs by namespace and name.
+func drainsBlockedByPDBs(intervals monitorapi.Intervals, selectors map[string]labels.Selector, recordedPods monitorapi.InstanceMap, end time.Time) monitorapi.Intervals {
+	pods := podLabels(recordedPods)
+	updates := nodeUpdatesFrom(intervals, end)
+	failures := evictionFailuresFrom(intervals)
+	blocked := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Source == monitorapi.SourcePodDisruptionBudgetMonitor && interval.Message.Reason == monitorapi.ReasonEvictionBlocked
+	})
+
+	ret := monitorapi.Intervals{}
+	for _, pdbInterval := range blocked {
+		namespace := pdbInterval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+		selector := selectors[fmt.Sprintf("%s/%s", namespace, pdbInterval.Locator.Keys[monitorapi.LocatorPodDisruptionBudgetKey])]
+		for _, update := range updates {
+			from, to := pdbInterval.From, pdbInterval.To
+			if update.from.After(from) {
+				from = update.from
+			}
+			if update.to.Before(to) {
+				to = update.to
+			}
+			if !to.After(from) {
+				continue
+			}
+
+			refused := false
+			for _, failure := range failures {
+				if failure.at.Before(from) || failure.at.After(to) || !failure.refusedBy(namespace, selector, pods) {
+					continue
+				}
+				if len(failure.node) > 0 && failure.node != update.node {
+					continue
+				}
+				refused = true
+				break
+			}
+			if !refused {
+				continue
+			}
+
+			ret = append(ret,
+				monitorapi.NewInterval(monitorapi.SourcePodDisruptionBudgetMonitor, monitorapi.Warning).
+					Locator(pdbInterval.Locator).
+					Message(monitorapi.NewMessage().
+						Reason(monitorapi.ReasonDrainBlockedByPDB).
+						Constructed(monitorapi.ConstructionOwnerPDBChecker).
+						WithAnnotation(monitorapi.AnnotationNode, update.node).
+						HumanMessagef("blocked the drain of node/%s for %s", update.node, to.Sub(from).Round(time.Second)),
+					).
+					Display().
+					Build(from, to))
+		}
+	}
+	sort.Sort(ret)
+	return ret
+}
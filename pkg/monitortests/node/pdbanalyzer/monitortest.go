@@ -0,0 +1,123 @@
+package pdbanalyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// maxDrainBlockedDuration is how long a platform PDB may hold up the drain of a node.  The machine-config-controller
+// retries evictions until the drain timeout, so anything longer stalls the rollout.
+const maxDrainBlockedDuration = 10 * time.Minute
+
+type pdbAnalyzer struct {
+	tracker *pdbTracker
+}
+
+// NewPDBAnalyzer watches PodDisruptionBudgets and produces intervals for when each one blocked evictions or was below
+// minAvailable.  Blocked PDBs are correlated with node updates to find the ones that held up a drain.
+func NewPDBAnalyzer() monitortestframework.MonitorTest {
+	return &pdbAnalyzer{
+		tracker: newPDBTracker(),
+	}
+}
+
+func (w *pdbAnalyzer) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	kubeInformers := informers.NewSharedInformerFactory(kubeClient, 0)
+	pdbInformer := kubeInformers.Policy().V1().PodDisruptionBudgets().Informer()
+	pdbInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+					w.tracker.observe(pdb, time.Now())
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+					w.tracker.observe(pdb, time.Now())
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+					w.tracker.deleted(pdb, time.Now())
+				}
+			},
+		},
+	)
+	go kubeInformers.Start(ctx.Done())
+	return nil
+}
+
+func (w *pdbAnalyzer) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return w.tracker.intervals(beginning, end), nil, nil
+}
+
+func (w *pdbAnalyzer) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return drainsBlockedByPDBs(startingIntervals, w.tracker.selectors(), recordedResources["pods"], end), nil
+}
+
+func (w *pdbAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return evaluateDrainsBlockedByPDBs(finalIntervals), nil
+}
+
+func evaluateDrainsBlockedByPDBs(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	testName := fmt.Sprintf("[sig-node] platform PodDisruptionBudgets must not block a node drain for more than %s", maxDrainBlockedDuration)
+
+	failures := []string{}
+	for _, interval := range finalIntervals {
+		if interval.Message.Reason != monitorapi.ReasonDrainBlockedByPDB {
+			continue
+		}
+		if !platformidentification.IsPlatformNamespace(interval.Locator.Keys[monitorapi.LocatorNamespaceKey]) {
+			continue
+		}
+		if interval.To.Sub(interval.From) <= maxDrainBlockedDuration {
+			continue
+		}
+		failures = append(failures, interval.String())
+	}
+
+	passing := &junitapi.JUnitTestCase{Name: testName}
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{passing}
+	}
+
+	// flake until we know how often CI drains stall
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: strings.Join(failures, "\n"),
+				Output:  "the PodDisruptionBudget refused evictions while the node was updating",
+			},
+		},
+		passing,
+	}
+}
+
+func (w *pdbAnalyzer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (w *pdbAnalyzer) Cleanup(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,213 @@
+package pdbanalyzer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// pdbState is the part of a PodDisruptionBudget status that decides whether evictions are possible.
+type pdbState struct {
+	disruptionsAllowed int32
+	currentHealthy     int32
+	desiredHealthy     int32
+	expectedPods       int32
+}
+
+func stateOf(pdb *policyv1.PodDisruptionBudget) pdbState {
+	return pdbState{
+		disruptionsAllowed: pdb.Status.DisruptionsAllowed,
+		currentHealthy:     pdb.Status.CurrentHealthy,
+		desiredHealthy:     pdb.Status.DesiredHealthy,
+		expectedPods:       pdb.Status.ExpectedPods,
+	}
+}
+
+// evictionBlocked is true when the eviction API will refuse to evict any pod covered by the PDB.
+func (s pdbState) evictionBlocked() bool {
+	return s.expectedPods > 0 && s.disruptionsAllowed == 0
+}
+
+func (s pdbState) belowMinAvailable() bool {
+	return s.currentHealthy < s.desiredHealthy
+}
+
+type pdbRange struct {
+	from    time.Time
+	to      time.Time
+	message string
+}
+
+// pdbHistory is every range of time a single PDB blocked evictions or was below minAvailable.
+type pdbHistory struct {
+	namespace string
+	name      string
+	// selector is the latest selector of the PDB, nil when it is invalid
+	selector labels.Selector
+
+	blockedSince *time.Time
+	blockedState pdbState
+	belowSince   *time.Time
+	belowState   pdbState
+
+	blocked []pdbRange
+	below   []pdbRange
+}
+
+func (h *pdbHistory) observe(state pdbState, at time.Time) {
+	switch {
+	case state.evictionBlocked() && h.blockedSince == nil:
+		h.blockedSince = &at
+		h.blockedState = state
+	case !state.evictionBlocked() && h.blockedSince != nil:
+		h.closeBlocked(at)
+	}
+	switch {
+	case state.belowMinAvailable() && h.belowSince == nil:
+		h.belowSince = &at
+		h.belowState = state
+	case state.belowMinAvailable() && state.currentHealthy < h.belowState.currentHealthy:
+		// remember the worst it got
+		h.belowState = state
+	case !state.belowMinAvailable() && h.belowSince != nil:
+		h.closeBelow(at)
+	}
+}
+
+func (h *pdbHistory) blockedUntil(at time.Time) pdbRange {
+	return pdbRange{
+		from: *h.blockedSince,
+		to:   at,
+		message: fmt.Sprintf("no disruptions allowed, %d of %d expected pods healthy",
+			h.blockedState.currentHealthy, h.blockedState.expectedPods),
+	}
+}
+
+func (h *pdbHistory) belowUntil(at time.Time) pdbRange {
+	return pdbRange{
+		from: *h.belowSince,
+		to:   at,
+		message: fmt.Sprintf("%d healthy pods, below the %d desired",
+			h.belowState.currentHealthy, h.belowState.desiredHealthy),
+	}
+}
+
+func (h *pdbHistory) closeBlocked(at time.Time) {
+	h.blocked = append(h.blocked, h.blockedUntil(at))
+	h.blockedSince = nil
+}
+
+func (h *pdbHistory) closeBelow(at time.Time) {
+	h.below = append(h.below, h.belowUntil(at))
+	h.belowSince = nil
+}
+
+func (h *pdbHistory) intervals(beginning, end time.Time) monitorapi.Intervals {
+	locator := monitorapi.NewLocator().PodDisruptionBudget(h.namespace, h.name)
+
+	blocked := append([]pdbRange{}, h.blocked...)
+	below := append([]pdbRange{}, h.below...)
+	// still open at the end of the run
+	if h.blockedSince != nil {
+		blocked = append(blocked, h.blockedUntil(end))
+	}
+	if h.belowSince != nil {
+		below = append(below, h.belowUntil(end))
+	}
+
+	ret := monitorapi.Intervals{}
+	build := func(ranges []pdbRange, level monitorapi.IntervalLevel, reason monitorapi.IntervalReason) {
+		for _, curr := range ranges {
+			if curr.to.Before(beginning) || curr.from.After(end) {
+				continue
+			}
+			if curr.from.Before(beginning) {
+				curr.from = beginning
+			}
+			ret = append(ret,
+				monitorapi.NewInterval(monitorapi.SourcePodDisruptionBudgetMonitor, level).
+					Locator(locator).
+					Message(monitorapi.NewMessage().
+						Reason(reason).
+						HumanMessage(curr.message),
+					).
+					Display().
+					Build(curr.from, curr.to))
+		}
+	}
+	build(blocked, monitorapi.Info, monitorapi.ReasonEvictionBlocked)
+	build(below, monitorapi.Warning, monitorapi.ReasonBelowMinAvailable)
+	return ret
+}
+
+// pdbTracker follows the status of every PodDisruptionBudget in the cluster.
+type pdbTracker struct {
+	lock sync.Mutex
+	pdbs map[string]*pdbHistory
+}
+
+func newPDBTracker() *pdbTracker {
+	return &pdbTracker{
+		pdbs: map[string]*pdbHistory{},
+	}
+}
+
+func (t *pdbTracker) observe(pdb *policyv1.PodDisruptionBudget, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name)
+	history, ok := t.pdbs[key]
+	if !ok {
+		history = &pdbHistory{namespace: pdb.Namespace, name: pdb.Name}
+		t.pdbs[key] = history
+	}
+	if selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector); err == nil {
+		history.selector = selector
+	}
+	history.observe(stateOf(pdb), at)
+}
+
+// selectors returns the selector of every PDB by namespace and name.
+func (t *pdbTracker) selectors() map[string]labels.Selector {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := map[string]labels.Selector{}
+	for key, history := range t.pdbs {
+		if history.selector != nil {
+			ret[key] = history.selector
+		}
+	}
+	return ret
+}
+
+// deleted closes everything still open for the PDB, a deleted PDB blocks nothing.
+func (t *pdbTracker) deleted(pdb *policyv1.PodDisruptionBudget, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	history, ok := t.pdbs[fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name)]
+	if !ok {
+		return
+	}
+	history.observe(pdbState{}, at)
+}
+
+func (t *pdbTracker) intervals(beginning, end time.Time) monitorapi.Intervals {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := monitorapi.Intervals{}
+	for _, history := range t.pdbs {
+		ret = append(ret, history.intervals(beginning, end)...)
+	}
+	sort.Sort(ret)
+	return ret
+}
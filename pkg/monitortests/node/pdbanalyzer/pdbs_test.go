@@ -0,0 +1,132 @@
+package pdbanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pdbWithStatus(namespace string, disruptionsAllowed, currentHealthy, desiredHealthy, expectedPods int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "pdb"},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: disruptionsAllowed,
+			CurrentHealthy:     currentHealthy,
+			DesiredHealthy:     desiredHealthy,
+			ExpectedPods:       expectedPods,
+		},
+	}
+}
+
+func withSelector(pdb *policyv1.PodDisruptionBudget, name string, matchLabels map[string]string) *policyv1.PodDisruptionBudget {
+	pdb.Name = name
+	pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return pdb
+}
+
+func nodeInterval(node string, reason monitorapi.IntervalReason, at time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason(reason).HumanMessage("config change")).
+		Build(at, at)
+}
+
+func evictionEvent(node, namespace, pod string, at time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason("Drain").
+			HumanMessagef(`error when evicting pods/"%s" -n "%s" (will retry after 5s): Cannot evict pod as it would violate the pod's disruption budget.`, pod, namespace)).
+		Build(at, at)
+}
+
+func TestPDBTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tracker := newPDBTracker()
+	ingress := pdbWithStatus("openshift-ingress", 1, 2, 1, 2)
+	tracker.observe(ingress, start.Add(-time.Hour))
+	tracker.observe(pdbWithStatus("openshift-ingress", 0, 1, 1, 2), start.Add(10*time.Minute))
+	tracker.observe(pdbWithStatus("openshift-ingress", 0, 0, 1, 2), start.Add(11*time.Minute))
+	tracker.observe(pdbWithStatus("openshift-ingress", 1, 2, 1, 2), start.Add(30*time.Minute))
+	// blocked since before the run, and still blocked at the end
+	tracker.observe(pdbWithStatus("openshift-foo", 0, 1, 1, 1), start.Add(-time.Hour))
+	// deleted while blocked
+	tracker.observe(pdbWithStatus("e2e-test-bar", 0, 1, 1, 1), start.Add(5*time.Minute))
+	tracker.deleted(pdbWithStatus("e2e-test-bar", 0, 1, 1, 1), start.Add(6*time.Minute))
+
+	intervals := tracker.intervals(start, end)
+	blocked := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonEvictionBlocked
+	})
+	require.Len(t, blocked, 3)
+	assert.Equal(t, "openshift-foo", blocked[0].Locator.Keys[monitorapi.LocatorNamespaceKey])
+	assert.Equal(t, start, blocked[0].From)
+	assert.Equal(t, end, blocked[0].To)
+	assert.Equal(t, "e2e-test-bar", blocked[1].Locator.Keys[monitorapi.LocatorNamespaceKey])
+	assert.Equal(t, start.Add(6*time.Minute), blocked[1].To)
+	assert.Equal(t, "openshift-ingress", blocked[2].Locator.Keys[monitorapi.LocatorNamespaceKey])
+	assert.Equal(t, "pdb", blocked[2].Locator.Keys[monitorapi.LocatorPodDisruptionBudgetKey])
+	assert.Equal(t, start.Add(10*time.Minute), blocked[2].From)
+	assert.Equal(t, start.Add(30*time.Minute), blocked[2].To)
+
+	below := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonBelowMinAvailable
+	})
+	require.Len(t, below, 1)
+	assert.Equal(t, start.Add(11*time.Minute), below[0].From)
+	assert.Equal(t, start.Add(30*time.Minute), below[0].To)
+	assert.Equal(t, "0 healthy pods, below the 1 desired", below[0].Message.HumanMessage)
+}
+
+func TestDrainsBlockedByPDBs(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tracker := newPDBTracker()
+	router := map[string]string{"app": "router"}
+	tracker.observe(withSelector(pdbWithStatus("openshift-ingress", 0, 1, 1, 2), "pdb", router), start.Add(5*time.Minute))
+	tracker.observe(withSelector(pdbWithStatus("openshift-ingress", 1, 2, 1, 2), "pdb", router), start.Add(40*time.Minute))
+	// blocked while worker-a drained, but it does not select the router pods that could not be evicted
+	canary := map[string]string{"app": "canary"}
+	tracker.observe(withSelector(pdbWithStatus("openshift-ingress", 0, 1, 1, 1), "canary", canary), start)
+	// blocked the whole time, but its pods were never on a draining node
+	tracker.observe(pdbWithStatus("openshift-foo", 0, 1, 1, 1), start)
+
+	recordedPods := monitorapi.InstanceMap{
+		{Namespace: "openshift-ingress", Name: "router-default-1", UID: "1"}: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default-1", Labels: router},
+		},
+	}
+
+	intervals := tracker.intervals(start, end)
+	intervals = append(intervals,
+		nodeInterval("worker-a", monitorapi.MachineConfigChangeReason, start.Add(10*time.Minute)),
+		evictionEvent("worker-a", "openshift-ingress", "router-default-1", start.Add(11*time.Minute)),
+		nodeInterval("worker-a", monitorapi.MachineConfigReachedReason, start.Add(45*time.Minute)),
+		// worker-b drained after the router PDB allowed disruptions again, the canary PDB never selected the router
+		nodeInterval("worker-b", monitorapi.MachineConfigChangeReason, start.Add(50*time.Minute)),
+		evictionEvent("worker-b", "openshift-ingress", "router-default-1", start.Add(51*time.Minute)),
+	)
+
+	drains := drainsBlockedByPDBs(intervals, tracker.selectors(), recordedPods, end)
+	require.Len(t, drains, 1)
+	assert.Equal(t, "pdb", drains[0].Locator.Keys[monitorapi.LocatorPodDisruptionBudgetKey])
+	assert.Equal(t, monitorapi.ReasonDrainBlockedByPDB, drains[0].Message.Reason)
+	assert.Equal(t, "openshift-ingress", drains[0].Locator.Keys[monitorapi.LocatorNamespaceKey])
+	assert.Equal(t, "worker-a", drains[0].Message.Annotations[monitorapi.AnnotationNode])
+	assert.Equal(t, start.Add(10*time.Minute), drains[0].From)
+	assert.Equal(t, start.Add(40*time.Minute), drains[0].To)
+
+	junits := evaluateDrainsBlockedByPDBs(append(intervals, drains...))
+	require.Len(t, junits, 2, "blocked drains should flake")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, "blocked the drain of node/worker-a for 30m0s")
+	assert.Nil(t, junits[1].FailureOutput)
+}
@@ -0,0 +1,259 @@
+package disruptioncustombackends
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+const MonitorName = "custom-backend-availability"
+
+type availability struct {
+	configFile string
+
+	disruptionCheckers []*disruptionlibrary.Availability
+}
+
+// NewAvailabilityInvariant creates a disruption sampler and junit for every backend in the configuration file.
+// The file is read in StartCollection so that a bad file is reported as a junit failure instead of preventing
+// the run from starting.
+func NewAvailabilityInvariant(configFile string) monitortestframework.MonitorTest {
+	return &availability{
+		configFile: configFile,
+	}
+}
+
+func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	config, err := LoadBackendConfiguration(w.configFile)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range config.Backends {
+		backend := config.Backends[i]
+		disruptionChecker, err := newDisruptionChecker(ctx, adminRESTConfig, &backend)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("backend %q: %w", backend.Name, err))
+			continue
+		}
+		w.disruptionCheckers = append(w.disruptionCheckers, disruptionChecker)
+	}
+
+	for i := range w.disruptionCheckers {
+		if err := w.disruptionCheckers[i].StartCollection(ctx, adminRESTConfig, recorder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (w *availability) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	intervals := monitorapi.Intervals{}
+	junits := []*junitapi.JUnitTestCase{}
+	errs := []error{}
+
+	for i := range w.disruptionCheckers {
+		localIntervals, localJunits, localErr := w.disruptionCheckers[i].CollectData(ctx)
+		intervals = append(intervals, localIntervals...)
+		junits = append(junits, localJunits...)
+		if localErr != nil {
+			errs = append(errs, localErr)
+		}
+	}
+
+	return intervals, junits, utilerrors.NewAggregate(errs)
+}
+
+func (*availability) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (w *availability) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	junits := []*junitapi.JUnitTestCase{}
+	errs := []error{}
+
+	for i := range w.disruptionCheckers {
+		localJunits, localErr := w.disruptionCheckers[i].EvaluateTestsFromConstructedIntervals(ctx, finalIntervals)
+		junits = append(junits, localJunits...)
+		if localErr != nil {
+			errs = append(errs, localErr)
+		}
+	}
+
+	return junits, utilerrors.NewAggregate(errs)
+}
+
+func (*availability) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (*availability) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+func newDisruptionChecker(ctx context.Context, adminRESTConfig *rest.Config, backend *Backend) (*disruptionlibrary.Availability, error) {
+	host := backend.URL
+	if backend.Service != nil {
+		var err error
+		host, err = getServiceHost(ctx, adminRESTConfig, backend.Service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsConfig, bearerToken, bearerTokenFile, err := getAuth(adminRESTConfig, backend.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var newConnectionTestName, reusedConnectionTestName string
+	var newConnectionDisruptionSampler, reusedConnectionDisruptionSampler *backenddisruption.BackendSampler
+	for _, connectionType := range backend.getConnectionTypes() {
+		var sampler *backenddisruption.BackendSampler
+		if backend.Route != nil {
+			sampler = backenddisruption.NewRouteBackend(
+				adminRESTConfig,
+				backend.Route.Namespace,
+				backend.Route.Name,
+				backend.Name,
+				backend.getPath(),
+				connectionType)
+		} else {
+			sampler = backenddisruption.NewSimpleBackendFromOpenshiftTests(
+				host,
+				backend.disruptionBackendName(connectionType),
+				backend.getPath(),
+				connectionType)
+		}
+
+		if backend.ExpectedStatusCode != 0 {
+			sampler = sampler.WithExpectedStatusCode(backend.ExpectedStatusCode)
+		}
+		if len(backend.ExpectedBody) > 0 {
+			sampler = sampler.WithExpectedBody(backend.ExpectedBody)
+		}
+		if len(backend.ExpectedBodyRegex) > 0 {
+			sampler = sampler.WithExpectedBodyRegex(backend.ExpectedBodyRegex)
+		}
+		if tlsConfig != nil {
+			sampler = sampler.WithTLSConfig(tlsConfig)
+		}
+		if len(bearerToken) > 0 || len(bearerTokenFile) > 0 {
+			sampler = sampler.WithBearerTokenAuth(bearerToken, bearerTokenFile)
+		}
+
+		switch connectionType {
+		case monitorapi.NewConnectionType:
+			newConnectionTestName = backend.testName(connectionType)
+			newConnectionDisruptionSampler = sampler
+		case monitorapi.ReusedConnectionType:
+			reusedConnectionTestName = backend.testName(connectionType)
+			reusedConnectionDisruptionSampler = sampler
+		}
+	}
+
+	disruptionChecker := disruptionlibrary.NewAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+	)
+	if backend.AllowedDisruption != nil {
+		disruptionChecker = disruptionChecker.WithAllowedDisruption(backend.AllowedDisruption.Duration)
+	}
+	return disruptionChecker, nil
+}
+
+// getServiceHost returns scheme://host:port for the first load balancer ingress of the service.
+func getServiceHost(ctx context.Context, adminRESTConfig *rest.Config, ref *ServiceReference) (string, error) {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return "", err
+	}
+	service, err := kubeClient.CoreV1().Services(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return "", fmt.Errorf("service %s/%s is type %q, only LoadBalancer services are reachable from openshift-tests", ref.Namespace, ref.Name, service.Spec.Type)
+	}
+
+	port := ref.Port
+	if port == 0 {
+		if len(service.Spec.Ports) == 0 {
+			return "", fmt.Errorf("service %s/%s has no ports", ref.Namespace, ref.Name)
+		}
+		port = service.Spec.Ports[0].Port
+	}
+	scheme := ref.Scheme
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		address := ingress.Hostname
+		if len(address) == 0 {
+			address = ingress.IP
+		}
+		if len(address) > 0 {
+			return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(address, strconv.Itoa(int(port)))), nil
+		}
+	}
+	return "", fmt.Errorf("service %s/%s has no load balancer ingress", ref.Namespace, ref.Name)
+}
+
+// getAuth returns the TLS config and bearer token settings for a backend.  A nil tls.Config means the sampler
+// default of skipping verification is used.
+func getAuth(adminRESTConfig *rest.Config, auth *BackendAuth) (*tls.Config, string, string, error) {
+	if auth == nil {
+		return nil, "", "", nil
+	}
+
+	if auth.UseClusterCredentials {
+		kubeTransportConfig, err := adminRESTConfig.TransportConfig()
+		if err != nil {
+			return nil, "", "", err
+		}
+		tlsConfig, err := transport.TLSConfigFor(kubeTransportConfig)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return tlsConfig, kubeTransportConfig.BearerToken, kubeTransportConfig.BearerTokenFile, nil
+	}
+
+	var tlsConfig *tls.Config
+	if len(auth.CAFile) > 0 {
+		caData, err := os.ReadFile(auth.CAFile)
+		if err != nil {
+			return nil, "", "", err
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caData) {
+			return nil, "", "", fmt.Errorf("no certificates found in %q", auth.CAFile)
+		}
+		tlsConfig = &tls.Config{RootCAs: roots}
+	}
+	if len(auth.BearerTokenFile) > 0 && tlsConfig == nil {
+		// the sampler requires an explicit TLS config before it will send a token.
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return tlsConfig, "", auth.BearerTokenFile, nil
+}
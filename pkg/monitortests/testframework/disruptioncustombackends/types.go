@@ -0,0 +1,228 @@
+package disruptioncustombackends
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// BackendConfiguration is the format of the file passed to --disruption-backends.  It describes additional
+// endpoints to poll for disruption during the run.
+//
+//	backends:
+//	- name: my-product-api
+//	  route:
+//	    namespace: my-product
+//	    name: api
+//	  path: /healthz
+//	  expectedBodyRegex: "^ok$"
+//	  allowedDisruption: 10s
+type BackendConfiguration struct {
+	Backends []Backend `json:"backends"`
+}
+
+// Backend describes a single endpoint to monitor.  Exactly one of URL, Route, or Service must be set.
+type Backend struct {
+	// Name is used to build the disruption backend name, the locator, and the junit test names.
+	// It must be a valid DNS-1123 label.
+	Name string `json:"name"`
+	// SigName is the sig that owns the junit tests.  Defaults to sig-trt.
+	SigName string `json:"sig,omitempty"`
+
+	// URL is the scheme and host of an endpoint that is reachable from where openshift-tests is running.
+	URL string `json:"url,omitempty"`
+	// Route refers to a routes.route.openshift.io in the cluster.  Routes are always contacted using https.
+	Route *ObjectReference `json:"route,omitempty"`
+	// Service refers to a LoadBalancer service in the cluster.  The first ingress hostname or IP is used.
+	Service *ServiceReference `json:"service,omitempty"`
+
+	// Path is the /path part of the URL.  Defaults to /.
+	Path string `json:"path,omitempty"`
+
+	// ConnectionTypes lists which connection types to monitor.  Valid values are "new" and "reused".
+	// Defaults to both.
+	ConnectionTypes []monitorapi.BackendConnectionType `json:"connectionTypes,omitempty"`
+
+	// ExpectedStatusCode allows status codes other than 2xx and 3xx.
+	ExpectedStatusCode int `json:"expectedStatusCode,omitempty"`
+	// ExpectedBody is a substring that must be present in the response body.
+	ExpectedBody string `json:"expectedBody,omitempty"`
+	// ExpectedBodyRegex is a regular expression that must match the response body.
+	ExpectedBodyRegex string `json:"expectedBodyRegex,omitempty"`
+
+	// Auth describes how to authenticate to the endpoint.
+	Auth *BackendAuth `json:"auth,omitempty"`
+
+	// AllowedDisruption is the maximum disruption tolerated before the junit fails.  When unset, historical data
+	// is consulted using the disruption backend name, which usually means the junit is skipped.
+	AllowedDisruption *metav1.Duration `json:"allowedDisruption,omitempty"`
+}
+
+type ObjectReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type ServiceReference struct {
+	ObjectReference `json:",inline"`
+	// Port defaults to the first port of the service.
+	Port int32 `json:"port,omitempty"`
+	// Scheme is http or https.  Defaults to http.
+	Scheme string `json:"scheme,omitempty"`
+}
+
+type BackendAuth struct {
+	// BearerTokenFile is a file containing a token to send as Authorization: Bearer.
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+	// CAFile is a PEM bundle used to verify the server.  When unset, server certificates are not verified.
+	CAFile string `json:"caFile,omitempty"`
+	// UseClusterCredentials sends the bearer token and CA of the admin kubeconfig.
+	UseClusterCredentials bool `json:"useClusterCredentials,omitempty"`
+}
+
+// LoadBackendConfiguration reads and validates the file at path.
+func LoadBackendConfiguration(path string) (*BackendConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read disruption backend configuration: %w", err)
+	}
+	config := &BackendConfiguration{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse disruption backend configuration %q: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid disruption backend configuration %q: %w", path, err)
+	}
+	return config, nil
+}
+
+func (c *BackendConfiguration) Validate() error {
+	errs := []string{}
+	names := sets.NewString()
+	for i, backend := range c.Backends {
+		for _, err := range backend.validate() {
+			errs = append(errs, fmt.Sprintf("backends[%d]: %s", i, err))
+		}
+		if names.Has(backend.Name) {
+			errs = append(errs, fmt.Sprintf("backends[%d]: duplicate name %q", i, backend.Name))
+		}
+		names.Insert(backend.Name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (b *Backend) validate() []string {
+	errs := []string{}
+	for _, msg := range validation.IsDNS1123Label(b.Name) {
+		errs = append(errs, fmt.Sprintf("name: %s", msg))
+	}
+
+	targets := 0
+	if len(b.URL) > 0 {
+		targets++
+		u, err := url.Parse(b.URL)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Sprintf("url: %v", err))
+		case u.Scheme != "http" && u.Scheme != "https":
+			errs = append(errs, fmt.Sprintf("url: scheme must be http or https, not %q", u.Scheme))
+		case len(u.Host) == 0:
+			errs = append(errs, "url: missing host")
+		case len(u.Path) > 0 && u.Path != "/":
+			errs = append(errs, "url: must not contain a path, use path instead")
+		}
+	}
+	if b.Route != nil {
+		targets++
+		if len(b.Route.Namespace) == 0 || len(b.Route.Name) == 0 {
+			errs = append(errs, "route: namespace and name are required")
+		}
+	}
+	if b.Service != nil {
+		targets++
+		if len(b.Service.Namespace) == 0 || len(b.Service.Name) == 0 {
+			errs = append(errs, "service: namespace and name are required")
+		}
+		switch b.Service.Scheme {
+		case "", "http", "https":
+		default:
+			errs = append(errs, fmt.Sprintf("service: scheme must be http or https, not %q", b.Service.Scheme))
+		}
+	}
+	if targets != 1 {
+		errs = append(errs, "exactly one of url, route, or service is required")
+	}
+
+	if len(b.Path) > 0 && !strings.HasPrefix(b.Path, "/") {
+		errs = append(errs, "path: must start with /")
+	}
+	for _, connectionType := range b.ConnectionTypes {
+		switch connectionType {
+		case monitorapi.NewConnectionType, monitorapi.ReusedConnectionType:
+		default:
+			errs = append(errs, fmt.Sprintf("connectionTypes: unknown connection type %q", connectionType))
+		}
+	}
+	if len(b.ExpectedBodyRegex) > 0 {
+		if _, err := regexp.Compile(b.ExpectedBodyRegex); err != nil {
+			errs = append(errs, fmt.Sprintf("expectedBodyRegex: %v", err))
+		}
+	}
+	if b.Auth != nil && b.Auth.UseClusterCredentials && len(b.Auth.BearerTokenFile) > 0 {
+		errs = append(errs, "auth: bearerTokenFile and useClusterCredentials are mutually exclusive")
+	}
+	if b.AllowedDisruption != nil && b.AllowedDisruption.Duration < 0 {
+		errs = append(errs, "allowedDisruption: must not be negative")
+	}
+
+	return errs
+}
+
+func (b *Backend) getSigName() string {
+	if len(b.SigName) == 0 {
+		return "sig-trt"
+	}
+	return b.SigName
+}
+
+func (b *Backend) getPath() string {
+	if len(b.Path) == 0 {
+		return "/"
+	}
+	return b.Path
+}
+
+func (b *Backend) getConnectionTypes() []monitorapi.BackendConnectionType {
+	if len(b.ConnectionTypes) == 0 {
+		return []monitorapi.BackendConnectionType{monitorapi.NewConnectionType, monitorapi.ReusedConnectionType}
+	}
+	return b.ConnectionTypes
+}
+
+// disruptionBackendName matches the naming used by NewRouteBackend so all custom backends look alike in
+// backend-disruption.json.
+func (b *Backend) disruptionBackendName(connectionType monitorapi.BackendConnectionType) string {
+	return fmt.Sprintf("%s-%v-connections", b.Name, connectionType)
+}
+
+func (b *Backend) testName(connectionType monitorapi.BackendConnectionType) string {
+	target := ""
+	switch {
+	case b.Route != nil:
+		target = fmt.Sprintf("ns/%s route/%s ", b.Route.Namespace, b.Route.Name)
+	case b.Service != nil:
+		target = fmt.Sprintf("ns/%s service/%s ", b.Service.Namespace, b.Service.Name)
+	}
+	return fmt.Sprintf("[%s] %sdisruption/%s connection/%v should be available throughout the test", b.getSigName(), target, b.Name, connectionType)
+}
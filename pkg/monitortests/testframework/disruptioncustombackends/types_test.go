@@ -0,0 +1,115 @@
+package disruptioncustombackends
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBackendConfiguration(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name: "url and route",
+			content: `
+backends:
+- name: product-api
+  url: https://api.example.com
+  path: /healthz
+  expectedBodyRegex: "^ok$"
+  allowedDisruption: 10s
+- name: product-console
+  route:
+    namespace: product
+    name: console
+  connectionTypes: [new]
+  auth:
+    useClusterCredentials: true
+`,
+		},
+		{
+			name: "missing target",
+			content: `
+backends:
+- name: product-api
+`,
+			expectedErr: "exactly one of url, route, or service is required",
+		},
+		{
+			name: "url with path",
+			content: `
+backends:
+- name: product-api
+  url: https://api.example.com/healthz
+`,
+			expectedErr: "url: must not contain a path, use path instead",
+		},
+		{
+			name: "duplicate names",
+			content: `
+backends:
+- name: product-api
+  url: https://api.example.com
+- name: product-api
+  url: https://other.example.com
+`,
+			expectedErr: `duplicate name "product-api"`,
+		},
+		{
+			name: "bad connection type and regex",
+			content: `
+backends:
+- name: product-api
+  url: https://api.example.com
+  connectionTypes: [sometimes]
+  expectedBodyRegex: "(("
+`,
+			expectedErr: `connectionTypes: unknown connection type "sometimes"`,
+		},
+		{
+			name: "unknown field",
+			content: `
+backends:
+- name: product-api
+  url: https://api.example.com
+  expectedBodyRegexp: ok
+`,
+			expectedErr: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "backends.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			_, err := LoadBackendConfiguration(path)
+			if len(tt.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestBackendNames(t *testing.T) {
+	backend := Backend{
+		Name: "product-console",
+		Route: &ObjectReference{
+			Namespace: "product",
+			Name:      "console",
+		},
+	}
+	assert.Equal(t, "product-console-new-connections", backend.disruptionBackendName(monitorapi.NewConnectionType))
+	assert.Equal(t,
+		"[sig-trt] ns/product route/console disruption/product-console connection/reused should be available throughout the test",
+		backend.testName(monitorapi.ReusedConnectionType))
+}
@@ -6,17 +6,26 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/openshift/origin/pkg/dataloader"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 )
 
 type disruptionSummarySerializer struct {
+	adminRESTConfig *rest.Config
+	// jobType is determined while collecting data, so writing the scores does not need the cluster.
+	jobType *platformidentification.JobType
 }
 
 func NewDisruptionSummarySerializer() monitortestframework.MonitorTest {
@@ -24,10 +33,21 @@ func NewDisruptionSummarySerializer() monitortestframework.MonitorTest {
 }
 
 func (w *disruptionSummarySerializer) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
 	return nil
 }
 
 func (w *disruptionSummarySerializer) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	// scores are best effort, the disruption data is written without them.
+	if w.adminRESTConfig == nil {
+		return nil, nil, nil
+	}
+	jobType, err := platformidentification.GetJobType(ctx, w.adminRESTConfig)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to determine job type, not scoring disruption")
+		return nil, nil, nil
+	}
+	w.jobType = jobType
 	return nil, nil, nil
 }
 
@@ -39,9 +59,21 @@ func (*disruptionSummarySerializer) EvaluateTestsFromConstructedIntervals(ctx co
 	return nil, nil
 }
 
-func (*disruptionSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+func (w *disruptionSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
 	backendDisruption := computeDisruptionData(finalIntervals)
-	return writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption)
+	if err := writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption); err != nil {
+		return err
+	}
+
+	// scores are best effort, the disruption data above is what matters.
+	if w.jobType == nil {
+		return nil
+	}
+	fileName := filepath.Join(storageDir, fmt.Sprintf("backend-disruption-scores%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	if err := dataloader.WriteDataFile(fileName, computeDisruptionScores(backendDisruption, *w.jobType)); err != nil {
+		logrus.WithError(err).Warnf("unable to write data file: %s", fileName)
+	}
+	return nil
 }
 
 func (*disruptionSummarySerializer) Cleanup(ctx context.Context) error {
@@ -126,3 +158,53 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 
 	return ret
 }
+
+// computeDisruptionScores records how close each backend came to failing so the trend is visible over many runs,
+// not only when a run crosses the threshold.
+func computeDisruptionScores(disruption *BackendDisruptionList, jobType platformidentification.JobType) dataloader.DataFile {
+	rows := []map[string]string{}
+	for _, backend := range disruption.BackendDisruptions {
+		score, historical, details, ok, err := allowedbackenddisruption.ScoreHistoricalDisruption(backend.BackendName, jobType, backend.DisruptedDuration.Duration)
+		if err != nil {
+			logrus.WithError(err).Warnf("unable to score disruption for %s", backend.BackendName)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		rows = append(rows, map[string]string{
+			"BackendName":        backend.BackendName,
+			"ConnectionType":     backend.ConnectionType,
+			"ObservedSeconds":    strconv.FormatFloat(score.ObservedSeconds, 'f', -1, 64),
+			"HistoricalP95":      strconv.FormatFloat(historical.P95.Seconds(), 'f', -1, 64),
+			"HistoricalP99":      strconv.FormatFloat(historical.P99.Seconds(), 'f', -1, 64),
+			"HistoricalJobRuns":  strconv.FormatInt(historical.JobRuns, 10),
+			"HistoricalMatch":    details,
+			"PercentileRank":     strconv.FormatFloat(score.PercentileRank, 'f', -1, 64),
+			"ProbabilityAtLeast": strconv.FormatFloat(score.ProbabilityAtLeast, 'f', -1, 64),
+			"ZScore":             strconv.FormatFloat(score.ZScore, 'f', -1, 64),
+			"Classification":     string(score.Classification),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i]["BackendName"] < rows[j]["BackendName"]
+	})
+
+	return dataloader.DataFile{
+		TableName: "backend_disruption_scores",
+		Schema: map[string]dataloader.DataType{
+			"BackendName":        dataloader.DataTypeString,
+			"ConnectionType":     dataloader.DataTypeString,
+			"ObservedSeconds":    dataloader.DataTypeFloat64,
+			"HistoricalP95":      dataloader.DataTypeFloat64,
+			"HistoricalP99":      dataloader.DataTypeFloat64,
+			"HistoricalJobRuns":  dataloader.DataTypeInteger,
+			"HistoricalMatch":    dataloader.DataTypeString,
+			"PercentileRank":     dataloader.DataTypeFloat64,
+			"ProbabilityAtLeast": dataloader.DataTypeFloat64,
+			"ZScore":             dataloader.DataTypeFloat64,
+			"Classification":     dataloader.DataTypeString,
+		},
+		Rows: rows,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/openshift/origin/pkg/alerts"
 	"github.com/openshift/origin/pkg/monitortestframework"
 	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
 	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 
@@ -26,7 +27,7 @@ import (
 	"k8s.io/kubernetes/test/e2e/framework"
 )
 
-type AllowedAlertsFunc func(featureSet configv1.FeatureSet) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending alerts.MetricConditions)
+type AllowedAlertsFunc func(env exceptionregistry.Environment) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending alerts.MetricConditions)
 
 func testAlerts(events monitorapi.Intervals,
 	allowancesFunc AllowedAlertsFunc,
@@ -34,7 +35,8 @@ func testAlerts(events monitorapi.Intervals,
 	clusterStability *monitortestframework.ClusterStabilityDuringTest,
 	restConfig *rest.Config,
 	duration time.Duration,
-	recordedResource monitorapi.ResourcesMap) []*junitapi.JUnitTestCase {
+	recordedResource monitorapi.ResourcesMap,
+	usage *exceptionregistry.Usage) []*junitapi.JUnitTestCase {
 
 	// Work with the cluster under test before we run the alert tests. For testing the tests purposes,
 	// please keep any use of the rest.Config isolated to this function and do not have the actual
@@ -72,19 +74,21 @@ func testAlerts(events monitorapi.Intervals,
 		}
 	}
 
-	ret := RunAlertTests(jobType, clusterStability, allowancesFunc, featureSet, etcdAllowance, events, recordedResource)
+	ret := RunAlertTests(jobType, clusterStability, allowancesFunc, featureSet, etcdAllowance, events, recordedResource, usage)
 	return ret
 }
 
 // RunAlertTests is a key entry point for running all per-Alert tests we've defined in all.go AllAlertTests,
 // as well as backstop tests on things we observe outside those specific tests.
+// Every alert excused by an allowance is recorded in usage, which may be nil.
 func RunAlertTests(jobType *platformidentification.JobType,
 	clusterStability *monitortestframework.ClusterStabilityDuringTest,
 	allowancesFunc AllowedAlertsFunc,
 	featureSet configv1.FeatureSet,
 	etcdAllowance allowedalerts.AlertTestAllowanceCalculator,
 	events monitorapi.Intervals,
-	recordedResource monitorapi.ResourcesMap) []*junitapi.JUnitTestCase {
+	recordedResource monitorapi.ResourcesMap,
+	usage *exceptionregistry.Usage) []*junitapi.JUnitTestCase {
 
 	ret := []*junitapi.JUnitTestCase{}
 	alertTests := allowedalerts.AllAlertTests(jobType, clusterStability, etcdAllowance)
@@ -110,7 +114,8 @@ func RunAlertTests(jobType *platformidentification.JobType,
 	firingIntervals := events.Filter(monitorapi.AlertFiring())
 
 	// Run the backstop catch all for all other alerts:
-	ret = append(ret, runBackstopTest(allowancesFunc, featureSet, pendingIntervals, firingIntervals, alertTests)...)
+	env := exceptionregistry.NewEnvironment(featureSet, jobType)
+	ret = append(ret, runBackstopTest(allowancesFunc, env, pendingIntervals, firingIntervals, alertTests, usage)...)
 
 	// TODO: Run a test to ensure no new alerts fired:
 	ret = append(ret, runNoNewAlertsFiringTest(allowedalerts.GetHistoricalData(), firingIntervals)...)
@@ -122,13 +127,14 @@ func RunAlertTests(jobType *platformidentification.JobType,
 // and look for any pending/firing intervals that are not within sufficient range.
 func runBackstopTest(
 	allowancesFunc AllowedAlertsFunc,
-	featureSet configv1.FeatureSet,
+	env exceptionregistry.Environment,
 	pendingIntervals monitorapi.Intervals,
 	firingIntervals monitorapi.Intervals,
-	alertTests []allowedalerts.AlertTest) []*junitapi.JUnitTestCase {
+	alertTests []allowedalerts.AlertTest,
+	usage *exceptionregistry.Usage) []*junitapi.JUnitTestCase {
 
 	firingAlertsWithBugs, allowedFiringAlerts, pendingAlertsWithBugs, allowedPendingAlerts :=
-		allowancesFunc(featureSet)
+		allowancesFunc(env)
 
 	logrus.Infof("filtered down to %d pending intervals", len(pendingIntervals))
 	logrus.Infof("filtered down to %d firing intervals", len(firingIntervals))
@@ -143,12 +149,14 @@ func runBackstopTest(
 			// a pending test covers pending and everything above (firing)
 			allowedPendingAlerts = append(allowedPendingAlerts,
 				alerts.MetricCondition{
+					Name:      alertTest.AlertName() + "HasSeparateTest",
 					AlertName: alertTest.AlertName(),
 					Text:      "has a separate e2e test",
 				},
 			)
 			allowedFiringAlerts = append(allowedFiringAlerts,
 				alerts.MetricCondition{
+					Name:      alertTest.AlertName() + "HasSeparateTest",
 					AlertName: alertTest.AlertName(),
 					Text:      "has a separate e2e test",
 				},
@@ -157,6 +165,7 @@ func runBackstopTest(
 			// an info test covers all firing
 			allowedFiringAlerts = append(allowedFiringAlerts,
 				alerts.MetricCondition{
+					Name:      alertTest.AlertName() + "HasSeparateTest",
 					AlertName: alertTest.AlertName(),
 					Text:      "has a separate e2e test",
 				},
@@ -180,10 +189,12 @@ func runBackstopTest(
 		if cause := allowedFiringAlerts.MatchesInterval(firing); cause != nil {
 			// TODO: this seems to never be happening? no search.ci results show allowed
 			debug.Insert(fmt.Sprintf("%s result=allow (%s)", violation, cause.Text))
+			recordAlertUsage(usage, cause)
 			continue
 		}
 		if cause := firingAlertsWithBugs.MatchesInterval(firing); cause != nil {
 			knownViolations.Insert(fmt.Sprintf("%s result=allow bug=%s", violation, cause.Text))
+			recordAlertUsage(usage, cause)
 		} else {
 			unexpectedViolations.Insert(fmt.Sprintf("%s result=reject", violation))
 		}
@@ -199,10 +210,12 @@ func runBackstopTest(
 		if cause := allowedPendingAlerts.MatchesInterval(pending); cause != nil {
 			// TODO: this seems to never be happening? no search.ci results show allowed
 			debug.Insert(fmt.Sprintf("%s result=allow (%s)", violation, cause.Text))
+			recordAlertUsage(usage, cause)
 			continue
 		}
 		if cause := pendingAlertsWithBugs.MatchesInterval(pending); cause != nil {
 			knownViolations.Insert(fmt.Sprintf("%s result=allow bug=%s", violation, cause.Text))
+			recordAlertUsage(usage, cause)
 		} else {
 			// treat pending errors as a flake right now because we are still trying to determine the scope
 			// TODO: move this to unexpectedViolations later
@@ -216,6 +229,9 @@ func runBackstopTest(
 			Name: "[sig-trt][invariant] No alerts without an explicit test should be firing/pending more than historically",
 		},
 	}
+	if excused := sets.NewString().Union(debug).Union(knownViolations); len(excused) > 0 {
+		ret[0].SystemOut = fmt.Sprintf("Alerts excused by an allowance:\n\n%s", strings.Join(excused.List(), "\n"))
+	}
 
 	if len(debug) > 0 {
 		framework.Logf("Alerts were detected which are allowed:\n\n%s", strings.Join(debug.List(), "\n"))
@@ -236,6 +252,14 @@ func runBackstopTest(
 	return ret
 }
 
+func recordAlertUsage(usage *exceptionregistry.Usage, cause *alerts.MetricCondition) {
+	name := cause.Name
+	if len(name) == 0 {
+		name = cause.AlertName
+	}
+	usage.Record(exceptionregistry.AlertKind, name, cause.Bug)
+}
+
 func isSkippedAlert(alertName string) bool {
 	// Some alerts we always skip over in CI:
 	for _, a := range allowedalerts.AllowedAlertNames {
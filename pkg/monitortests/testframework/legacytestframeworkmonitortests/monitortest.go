@@ -2,6 +2,8 @@ package legacytestframeworkmonitortests
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitortestframework"
@@ -10,7 +12,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/origin/pkg/alerts"
+	"github.com/openshift/origin/pkg/dataloader"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"k8s.io/client-go/rest"
@@ -21,6 +25,7 @@ type legacyMonitorTests struct {
 	duration                   time.Duration
 	recordedResources          monitorapi.ResourcesMap
 	clusterStabilityDuringTest *monitortestframework.ClusterStabilityDuringTest
+	exceptionUsage             *exceptionregistry.Usage
 }
 
 func NewLegacyTests(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
@@ -50,23 +55,29 @@ func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.C
 	}
 
 	junits := []*junitapi.JUnitTestCase{}
+	w.exceptionUsage = exceptionregistry.NewUsage()
 
 	isUpgrade := platformidentification.DidUpgradeHappenDuringCollection(finalIntervals, time.Time{}, time.Time{})
 	if isUpgrade {
-		junits = append(junits, pathologicaleventlibrary.TestDuplicatedEventForUpgrade(finalIntervals, w.adminRESTConfig)...)
+		junits = append(junits, pathologicaleventlibrary.TestDuplicatedEventForUpgrade(finalIntervals, w.adminRESTConfig, w.exceptionUsage)...)
 		junits = append(junits, testAlerts(finalIntervals, alerts.AllowedAlertsDuringUpgrade, jobType, w.clusterStabilityDuringTest,
-			w.adminRESTConfig, w.duration, w.recordedResources)...)
+			w.adminRESTConfig, w.duration, w.recordedResources, w.exceptionUsage)...)
 	} else {
-		junits = append(junits, pathologicaleventlibrary.TestDuplicatedEventForStableSystem(finalIntervals, w.adminRESTConfig)...)
+		junits = append(junits, pathologicaleventlibrary.TestDuplicatedEventForStableSystem(finalIntervals, w.adminRESTConfig, w.exceptionUsage)...)
 		junits = append(junits, testAlerts(finalIntervals, alerts.AllowedAlertsDuringConformance, jobType, w.clusterStabilityDuringTest,
-			w.adminRESTConfig, w.duration, w.recordedResources)...)
+			w.adminRESTConfig, w.duration, w.recordedResources, w.exceptionUsage)...)
 	}
 
 	return junits, nil
 }
 
-func (*legacyMonitorTests) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
-	return nil
+func (w *legacyMonitorTests) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if w.exceptionUsage == nil {
+		return nil
+	}
+	// An empty file is still written, a run where nothing was excused is what proves an exception can be removed.
+	fileName := filepath.Join(storageDir, fmt.Sprintf("exception-usage%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix))
+	return dataloader.WriteDataFile(fileName, w.exceptionUsage.DataFile())
 }
 
 func (*legacyMonitorTests) Cleanup(ctx context.Context) error {
@@ -0,0 +1,249 @@
+package watchleases
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	coordinationv1 "k8s.io/api/coordination/v1"
+)
+
+// leaseSnapshot is the leader election state of a lease at one point in time.
+type leaseSnapshot struct {
+	holder        string
+	acquireTime   time.Time
+	renewTime     time.Time
+	leaseDuration time.Duration
+}
+
+func snapshotOf(lease *coordinationv1.Lease) (leaseSnapshot, bool) {
+	// leases without a renew time were never held, they are not leader election leases
+	if lease.Spec.RenewTime == nil {
+		return leaseSnapshot{}, false
+	}
+	ret := leaseSnapshot{
+		renewTime: lease.Spec.RenewTime.Time,
+	}
+	if lease.Spec.HolderIdentity != nil {
+		ret.holder = *lease.Spec.HolderIdentity
+	}
+	if lease.Spec.AcquireTime != nil {
+		ret.acquireTime = lease.Spec.AcquireTime.Time
+	}
+	if lease.Spec.LeaseDurationSeconds != nil {
+		ret.leaseDuration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return ret, true
+}
+
+// leaderlessFrom is when nobody held the lease anymore.  A released lease has no holder from the moment it was
+// released, otherwise the holder keeps it until it fails to renew for the lease duration.
+func (s leaseSnapshot) leaderlessFrom() time.Time {
+	if len(s.holder) == 0 {
+		return s.renewTime
+	}
+	return s.renewTime.Add(s.leaseDuration)
+}
+
+// leaderSince is when the holder of s started leading, given the previous snapshot.
+func (s leaseSnapshot) leaderSince(previous leaseSnapshot) time.Time {
+	if s.holder != previous.holder && !s.acquireTime.IsZero() {
+		return s.acquireTime
+	}
+	return s.renewTime
+}
+
+type timeRange struct {
+	from           time.Time
+	to             time.Time
+	previousHolder string
+}
+
+type leaderChange struct {
+	at             time.Time
+	holder         string
+	previousHolder string
+}
+
+// leaseHistory is every leaderless range and leader change of a single lease.
+type leaseHistory struct {
+	namespace string
+	name      string
+
+	last       *leaseSnapshot
+	lastHolder string
+	leaderless []timeRange
+	changes    []leaderChange
+	// deleted is true once the lease was deleted, until it is created again.  A deleted lease is not leaderless, its
+	// component was removed or stopped on purpose.
+	deleted bool
+}
+
+func (h *leaseHistory) observe(curr leaseSnapshot) {
+	// a recreated lease starts over, the time it did not exist was not leaderless
+	recreated := h.deleted
+	h.deleted = false
+	if h.last != nil && len(curr.holder) > 0 && !recreated {
+		from := h.last.leaderlessFrom()
+		to := curr.leaderSince(*h.last)
+		if to.After(from) {
+			h.leaderless = append(h.leaderless, timeRange{from: from, to: to, previousHolder: h.lastHolder})
+		}
+	}
+	if len(curr.holder) > 0 && len(h.lastHolder) > 0 && curr.holder != h.lastHolder {
+		at := curr.acquireTime
+		if at.IsZero() {
+			at = curr.renewTime
+		}
+		h.changes = append(h.changes, leaderChange{at: at, holder: curr.holder, previousHolder: h.lastHolder})
+	}
+	if len(curr.holder) > 0 {
+		h.lastHolder = curr.holder
+	}
+	h.last = &curr
+}
+
+// delete closes the history of the lease when it is deleted at, it was leaderless at most until then.
+func (h *leaseHistory) delete(at time.Time) {
+	if h.deleted || h.last == nil {
+		return
+	}
+	if from := h.last.leaderlessFrom(); from.Before(at) {
+		h.leaderless = append(h.leaderless, timeRange{from: from, to: at, previousHolder: h.lastHolder})
+	}
+	h.deleted = true
+}
+
+// intervals returns the leaderless and leader changed intervals between beginning and end.  A lease that nobody
+// renewed during that time belongs to a component that is not running, it has no intervals.
+func (h *leaseHistory) intervals(beginning, end time.Time) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	if h.last == nil || h.last.renewTime.Before(beginning) {
+		return ret
+	}
+	locator := monitorapi.NewLocator().Lease(h.namespace, h.name)
+
+	leaderless := append([]timeRange{}, h.leaderless...)
+	if from := h.last.leaderlessFrom(); from.Before(end) && !h.deleted {
+		leaderless = append(leaderless, timeRange{from: from, to: end, previousHolder: h.lastHolder})
+	}
+	for _, curr := range leaderless {
+		if curr.to.Before(beginning) || curr.from.After(end) {
+			continue
+		}
+		if curr.from.Before(beginning) {
+			curr.from = beginning
+		}
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceLeaseMonitor, monitorapi.Warning).
+				Locator(locator).
+				Message(monitorapi.NewMessage().
+					Reason(monitorapi.ReasonLeaderless).
+					WithAnnotation(monitorapi.AnnotationPreviousHolder, curr.previousHolder).
+					HumanMessagef("no leader for %s, previous holder was %q", curr.to.Sub(curr.from).Round(time.Second), curr.previousHolder),
+				).
+				Display().
+				Build(curr.from, curr.to))
+	}
+
+	for _, change := range h.changes {
+		if change.at.Before(beginning) || change.at.After(end) {
+			continue
+		}
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceLeaseMonitor, monitorapi.Info).
+				Locator(locator).
+				Message(monitorapi.NewMessage().
+					Reason(monitorapi.ReasonLeaderChanged).
+					WithAnnotation(monitorapi.AnnotationHolder, change.holder).
+					WithAnnotation(monitorapi.AnnotationPreviousHolder, change.previousHolder).
+					HumanMessagef("leader changed from %q to %q", change.previousHolder, change.holder),
+				).
+				Display().
+				Build(change.at, change.at))
+	}
+	return ret
+}
+
+// leaseTracker follows the leader election leases of every platform namespace.
+type leaseTracker struct {
+	lock   sync.Mutex
+	leases map[string]*leaseHistory
+}
+
+func newLeaseTracker() *leaseTracker {
+	return &leaseTracker{
+		leases: map[string]*leaseHistory{},
+	}
+}
+
+// isLeaderElectionNamespace is true for the namespaces we track leases in.
+func isLeaderElectionNamespace(namespace string) bool {
+	// node heartbeats are leases too, they have nothing to do with leader election
+	return namespace != "kube-node-lease" && platformidentification.IsPlatformNamespace(namespace)
+}
+
+func (t *leaseTracker) observe(lease *coordinationv1.Lease) {
+	if !isLeaderElectionNamespace(lease.Namespace) {
+		return
+	}
+	snapshot, ok := snapshotOf(lease)
+	if !ok {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := fmt.Sprintf("%s/%s", lease.Namespace, lease.Name)
+	history, ok := t.leases[key]
+	if !ok {
+		history = &leaseHistory{namespace: lease.Namespace, name: lease.Name}
+		t.leases[key] = history
+	}
+	history.observe(snapshot)
+}
+
+// delete closes the history of a lease that was deleted at.
+func (t *leaseTracker) delete(lease *coordinationv1.Lease, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if history, ok := t.leases[fmt.Sprintf("%s/%s", lease.Namespace, lease.Name)]; ok {
+		history.delete(at)
+	}
+}
+
+func (t *leaseTracker) intervals(beginning, end time.Time) monitorapi.Intervals {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := monitorapi.Intervals{}
+	for _, history := range t.leases {
+		ret = append(ret, history.intervals(beginning, end)...)
+	}
+	sort.Sort(ret)
+	return ret
+}
+
+// namespaces returns every namespace with a lease that was renewed between beginning and end.
+func (t *leaseTracker) namespaces(beginning time.Time) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := map[string]bool{}
+	for _, history := range t.leases {
+		if history.last != nil && !history.last.renewTime.Before(beginning) {
+			ret[history.namespace] = true
+		}
+	}
+	namespaces := []string{}
+	for namespace := range ret {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
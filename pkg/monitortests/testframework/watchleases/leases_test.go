@@ -0,0 +1,143 @@
+package watchleases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func leaseAt(namespace, holder string, acquired, renewed time.Time) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "leader"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(holder),
+			LeaseDurationSeconds: ptr.To[int32](60),
+			AcquireTime:          ptr.To(metav1.NewMicroTime(acquired)),
+			RenewTime:            ptr.To(metav1.NewMicroTime(renewed)),
+		},
+	}
+}
+
+func TestLeaseTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	tracker := newLeaseTracker()
+	ns := "openshift-foo"
+	tracker.observe(leaseAt(ns, "a", start.Add(-time.Hour), start))
+	tracker.observe(leaseAt(ns, "a", start.Add(-time.Hour), start.Add(time.Minute)))
+	// a released the lease gracefully and b picked it up five seconds later
+	tracker.observe(leaseAt(ns, "", start.Add(-time.Hour), start.Add(2*time.Minute)))
+	tracker.observe(leaseAt(ns, "b", start.Add(2*time.Minute+5*time.Second), start.Add(2*time.Minute+5*time.Second)))
+	// b crashed, a took over once the lease expired, after five minutes
+	tracker.observe(leaseAt(ns, "a", start.Add(8*time.Minute+5*time.Second), start.Add(8*time.Minute+5*time.Second)))
+	tracker.observe(leaseAt(ns, "a", start.Add(8*time.Minute+5*time.Second), start.Add(9*time.Minute)))
+
+	// a stale lease that nobody renewed during the run
+	tracker.observe(leaseAt("openshift-bar", "c", start.Add(-2*time.Hour), start.Add(-2*time.Hour)))
+	// node heartbeats are not leader election
+	tracker.observe(leaseAt("kube-node-lease", "node", start, start))
+	// e2e namespaces are not platform namespaces
+	tracker.observe(leaseAt("e2e-test-foo", "d", start, start))
+
+	assert.Equal(t, []string{ns}, tracker.namespaces(start))
+
+	intervals := tracker.intervals(start, end)
+	leaderless := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonLeaderless
+	})
+	require.Len(t, leaderless, 2)
+	assert.Equal(t, start.Add(2*time.Minute), leaderless[0].From)
+	assert.Equal(t, start.Add(2*time.Minute+5*time.Second), leaderless[0].To)
+	assert.Equal(t, "a", leaderless[0].Message.Annotations[monitorapi.AnnotationPreviousHolder])
+	assert.Equal(t, start.Add(3*time.Minute+5*time.Second), leaderless[1].From)
+	assert.Equal(t, start.Add(8*time.Minute+5*time.Second), leaderless[1].To)
+	assert.Equal(t, "b", leaderless[1].Message.Annotations[monitorapi.AnnotationPreviousHolder])
+
+	changes := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonLeaderChanged
+	})
+	require.Len(t, changes, 2)
+	assert.Equal(t, "b", changes[0].Message.Annotations[monitorapi.AnnotationHolder])
+	assert.Equal(t, "a", changes[1].Message.Annotations[monitorapi.AnnotationHolder])
+	assert.Equal(t, ns, changes[1].Locator.Keys[monitorapi.LocatorNamespaceKey])
+	assert.Equal(t, "leader", changes[1].Locator.Keys[monitorapi.LocatorLeaseKey])
+
+	// nobody renewed after the last update, so the lease is leaderless once it expires
+	intervals = tracker.intervals(start, end.Add(time.Hour))
+	leaderless = intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonLeaderless
+	})
+	require.Len(t, leaderless, 3)
+	assert.Equal(t, end, leaderless[2].From)
+	assert.Equal(t, end.Add(time.Hour), leaderless[2].To)
+}
+
+func TestDeletedLease(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tracker := newLeaseTracker()
+	ns := "openshift-foo"
+	tracker.observe(leaseAt(ns, "a", start, start))
+	// the component was removed, its lease is not leaderless for the rest of the run
+	tracker.delete(leaseAt(ns, "a", start, start), start.Add(time.Minute+30*time.Second))
+	tracker.observe(leaseAt("openshift-bar", "b", start, start))
+	tracker.delete(leaseAt("openshift-bar", "b", start, start), start.Add(10*time.Second))
+
+	leaderless := tracker.intervals(start, end).Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonLeaderless
+	})
+	require.Len(t, leaderless, 1, "a lease deleted while it was held was never leaderless")
+	assert.Equal(t, start.Add(time.Minute), leaderless[0].From)
+	assert.Equal(t, start.Add(time.Minute+30*time.Second), leaderless[0].To)
+
+	// recreated, the time it did not exist is not leaderless either
+	at := start.Add(30 * time.Minute)
+	tracker.observe(leaseAt(ns, "c", at, at))
+	leaderless = tracker.intervals(start, at.Add(time.Minute)).Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Reason == monitorapi.ReasonLeaderless && interval.Locator.Keys[monitorapi.LocatorNamespaceKey] == ns
+	})
+	require.Len(t, leaderless, 1)
+	assert.Equal(t, start.Add(time.Minute+30*time.Second), leaderless[0].To)
+}
+
+func TestEvaluateLeaseIntervals(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := newLeaseTracker()
+	ns := "openshift-foo"
+	for i := 0; i <= maxLeaderChanges; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		tracker.observe(leaseAt(ns, []string{"a", "b"}[i%2], at, at))
+	}
+	// the last holder stays leader until the end
+	intervals := tracker.intervals(start, start.Add(time.Duration(maxLeaderChanges+1)*time.Minute))
+
+	junits := evaluateLeaseIntervals(tracker.namespaces(start), intervals, false)
+	require.Len(t, junits, 2)
+	assert.Nil(t, junits[0].FailureOutput, "exactly the maximum number of changes is allowed")
+	assert.Nil(t, junits[1].FailureOutput)
+
+	at := start.Add(time.Duration(maxLeaderChanges+1) * time.Minute)
+	tracker.observe(leaseAt(ns, "c", at, at))
+	// held by c until the end, then leaderless for longer than allowed
+	intervals = tracker.intervals(start, at.Add(time.Minute+maxLeaderlessDuration+time.Second))
+
+	junits = evaluateLeaseIntervals(tracker.namespaces(start), intervals, false)
+	require.Len(t, junits, 2, "unstable leases fail without a passing run of the same test")
+	require.NotNil(t, junits[0].FailureOutput)
+	assert.Contains(t, junits[0].FailureOutput.Message, "lease/leader changed leader 6 times")
+	require.NotNil(t, junits[1].FailureOutput)
+
+	junits = evaluateLeaseIntervals(tracker.namespaces(start), intervals, true)
+	require.Len(t, junits, 2)
+	assert.Nil(t, junits[0].FailureOutput, "upgrades do not fail")
+	assert.Contains(t, junits[0].SystemOut, "ignored during upgrade")
+}
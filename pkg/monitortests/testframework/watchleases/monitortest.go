@@ -0,0 +1,183 @@
+package watchleases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// maxLeaderChanges is how often a lease may change hands outside of an upgrade.  The control plane does not
+	// restart on its own, but some e2e tests disrupt it.
+	maxLeaderChanges = 5
+	// maxLeaderlessDuration is how long a lease may go without a leader outside of an upgrade.  The longest lease
+	// duration in the platform is kube-controller-manager's 137s, and a graceful release is picked up in seconds.
+	maxLeaderlessDuration = 2 * time.Minute
+)
+
+type leaseWatcher struct {
+	tracker    *leaseTracker
+	namespaces []string
+}
+
+// NewLeaseWatcher watches the leader election leases of the control plane components and operators.  It produces
+// intervals when a lease has no leader and when it changes hands, and fails when leadership flaps or stays
+// leaderless for too long outside of an upgrade.
+func NewLeaseWatcher() monitortestframework.MonitorTest {
+	return &leaseWatcher{
+		tracker: newLeaseTracker(),
+	}
+}
+
+func (w *leaseWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	// node heartbeats are most of the leases in a cluster and change every few seconds, do not even watch them
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermNotEqualSelector("metadata.namespace", "kube-node-lease").String()
+		}))
+	leaseInformer := kubeInformers.Coordination().V1().Leases().Informer()
+	leaseInformer.AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				lease, ok := leaseFrom(obj)
+				return ok && isLeaderElectionNamespace(lease.Namespace)
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					if lease, ok := leaseFrom(obj); ok {
+						w.tracker.observe(lease)
+					}
+				},
+				UpdateFunc: func(_, obj interface{}) {
+					if lease, ok := leaseFrom(obj); ok {
+						w.tracker.observe(lease)
+					}
+				},
+				DeleteFunc: func(obj interface{}) {
+					if lease, ok := leaseFrom(obj); ok {
+						w.tracker.delete(lease, time.Now())
+					}
+				},
+			},
+		},
+	)
+	go kubeInformers.Start(ctx.Done())
+	return nil
+}
+
+func leaseFrom(obj interface{}) (*coordinationv1.Lease, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	lease, ok := obj.(*coordinationv1.Lease)
+	return lease, ok
+}
+
+func (w *leaseWatcher) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	w.namespaces = w.tracker.namespaces(beginning)
+	return w.tracker.intervals(beginning, end), nil, nil
+}
+
+func (w *leaseWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (w *leaseWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	isUpgrade := platformidentification.DidUpgradeHappenDuringCollection(finalIntervals, time.Time{}, time.Time{})
+	return evaluateLeaseIntervals(w.namespaces, finalIntervals, isUpgrade), nil
+}
+
+func evaluateLeaseIntervals(namespaces []string, finalIntervals monitorapi.Intervals, isUpgrade bool) []*junitapi.JUnitTestCase {
+	leaseIntervals := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+		return eventInterval.Source == monitorapi.SourceLeaseMonitor
+	})
+
+	type leaseKey struct {
+		namespace string
+		name      string
+	}
+	changes := map[leaseKey][]string{}
+	namespaceToLeaderless := map[string][]string{}
+	for _, interval := range leaseIntervals {
+		key := leaseKey{
+			namespace: interval.Locator.Keys[monitorapi.LocatorNamespaceKey],
+			name:      interval.Locator.Keys[monitorapi.LocatorLeaseKey],
+		}
+		switch interval.Message.Reason {
+		case monitorapi.ReasonLeaderChanged:
+			changes[key] = append(changes[key], interval.String())
+		case monitorapi.ReasonLeaderless:
+			if interval.To.Sub(interval.From) > maxLeaderlessDuration {
+				namespaceToLeaderless[key.namespace] = append(namespaceToLeaderless[key.namespace], interval.String())
+			}
+		}
+	}
+	namespaceToFlapping := map[string][]string{}
+	for key, leaseChanges := range changes {
+		if len(leaseChanges) > maxLeaderChanges {
+			namespaceToFlapping[key.namespace] = append(namespaceToFlapping[key.namespace],
+				fmt.Sprintf("lease/%s changed leader %d times\n\t%s", key.name, len(leaseChanges), strings.Join(leaseChanges, "\n\t")))
+		}
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, namespace := range namespaces {
+		ret = append(ret, leaseJunit(
+			fmt.Sprintf("[sig-api-machinery] leader election leases in ns/%s must not change leader more than %d times", namespace, maxLeaderChanges),
+			namespaceToFlapping[namespace], isUpgrade)...)
+		ret = append(ret, leaseJunit(
+			fmt.Sprintf("[sig-api-machinery] leader election leases in ns/%s must not be without a leader for more than %s", namespace, maxLeaderlessDuration),
+			namespaceToLeaderless[namespace], isUpgrade)...)
+	}
+	return ret
+}
+
+func leaseJunit(testName string, failures []string, isUpgrade bool) []*junitapi.JUnitTestCase {
+	if len(failures) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	// every component restarts during an upgrade, so leadership moving around is expected there
+	if isUpgrade {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:      testName,
+				SystemOut: fmt.Sprintf("ignored during upgrade:\n%s", strings.Join(failures, "\n")),
+			},
+		}
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Message: strings.Join(failures, "\n"),
+				Output:  "leader election was unstable outside of an upgrade",
+			},
+		},
+	}
+}
+
+func (w *leaseWatcher) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (w *leaseWatcher) Cleanup(ctx context.Context) error {
+	return nil
+}
@@ -395,7 +395,10 @@ func runDisruptionAnalysis(opt *Options, jobType platformidentification.JobType)
 		}
 	}
 
-	matcher := allowedbackenddisruption.GetCurrentResults()
+	matcher, err := allowedbackenddisruption.GetCurrentResults()
+	if err != nil {
+		return nil, err
+	}
 	for i, ba := range analysis.Backends {
 		// Inject the percentiles:
 		percentiles, details, err := matcher.BestMatchDuration(ba.BackendName, jobType, 1)
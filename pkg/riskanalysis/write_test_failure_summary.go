@@ -9,7 +9,7 @@ import (
 	"strconv"
 
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
-
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 )
 
@@ -18,6 +18,17 @@ import (
 // This is intended to be later submitted to sippy for a risk analysis of how unusual the
 // test failures were, but that final step is handled elsewhere.
 func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteResults *junitapi.JUnitTestSuite, wasMasterNodeUpdated, outputFileSubStr string) error {
+	restConfig, err := clusterinfo.GetMonitorRESTConfig()
+	if err != nil {
+		return err
+	}
+	clusterData := clusterinfo.CollectClusterData(restConfig, wasMasterNodeUpdated)
+	return WriteJobRunTestFailureSummaryForCluster(artifactDir, timeSuffix, finalSuiteResults, clusterData, outputFileSubStr)
+}
+
+// WriteJobRunTestFailureSummaryForCluster writes the same summary as WriteJobRunTestFailureSummary for cluster data
+// that was already collected, like when the results of several runs are merged without a cluster.
+func WriteJobRunTestFailureSummaryForCluster(artifactDir, timeSuffix string, finalSuiteResults *junitapi.JUnitTestSuite, clusterData platformidentification.ClusterData, outputFileSubStr string) error {
 
 	tests := map[string]*passFail{}
 
@@ -39,14 +50,10 @@ func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteRes
 	// If we can't parse this, we submit without it, it is not required.
 	jobRunID, _ := strconv.Atoi(os.Getenv("BUILD_ID"))
 
-	restConfig, err := clusterinfo.GetMonitorRESTConfig()
-	if err != nil {
-		return err
-	}
 	jr := ProwJobRun{
 		ID:          jobRunID,
 		ProwJob:     ProwJob{Name: os.Getenv("JOB_NAME")},
-		ClusterData: clusterinfo.CollectClusterData(restConfig, wasMasterNodeUpdated),
+		ClusterData: clusterData,
 		Tests:       []ProwJobRunTest{},
 		TestCount:   len(tests),
 	}
@@ -94,3 +101,24 @@ func getSippyStatusCode(pf *passFail) int {
 	// we should not hit this given the above filtering
 	return 0
 }
+
+// ReadJobRunTestFailureSummaries reads every summary WriteJobRunTestFailureSummary wrote to artifactDir.
+func ReadJobRunTestFailureSummaries(artifactDir string) ([]*ProwJobRun, error) {
+	resultFiles, err := filepath.Glob(filepath.Join(artifactDir, fmt.Sprintf("%s*.json", testFailureSummaryFilePrefix)))
+	if err != nil {
+		return nil, err
+	}
+	var jobRuns []*ProwJobRun
+	for _, resultFile := range resultFiles {
+		data, err := os.ReadFile(resultFile)
+		if err != nil {
+			return nil, err
+		}
+		jobRun := &ProwJobRun{}
+		if err := json.Unmarshal(data, jobRun); err != nil {
+			return nil, fmt.Errorf("unable to decode test failure summary %q: %w", resultFile, err)
+		}
+		jobRuns = append(jobRuns, jobRun)
+	}
+	return jobRuns, nil
+}
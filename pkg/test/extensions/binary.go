@@ -1,6 +1,7 @@
 package extensions
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	k8simage "k8s.io/kubernetes/test/utils/image"
 
+	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/test/extended/util"
 )
 
@@ -33,6 +35,8 @@ type TestBinary struct {
 	imageTag string
 	// The binary path to extract from the image
 	binaryPath string
+	// The component a local binary must report, if configured
+	component *Component
 
 	// Cache the info after gathering it
 	info *ExtensionInfo
@@ -60,6 +64,10 @@ var extensionBinaries = []TestBinary{
 // Info returns information about this particular extension.
 func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	if b.info != nil {
+		// the info of incompatible extensions is kept for their compatibility report
+		if err := b.checkCompatibility(); err != nil {
+			return nil, err
+		}
 		return b.info, nil
 	}
 
@@ -79,6 +87,9 @@ func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "couldn't unmarshal extension info: %s", string(infoJson))
 	}
+	if err := b.checkComponent(&info); err != nil {
+		return nil, err
+	}
 	b.info = &info
 
 	// Set fields origin knows or calculates:
@@ -87,6 +98,10 @@ func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	b.info.ExtensionArtifactDir = path.Join(os.Getenv("ARTIFACT_DIR"), safeComponentPath(&b.info.Component))
 
 	logrus.Infof("Fetched info for %s in %v", binName, time.Since(start))
+	if err := b.checkCompatibility(); err != nil {
+		return nil, err
+	}
+	b.logCompatibility()
 	return b.info, nil
 }
 
@@ -130,8 +145,22 @@ func (b *TestBinary) ListTests(ctx context.Context, envFlags EnvironmentFlags) (
 	return tests, nil
 }
 
+// ResultHandler is called with each result an extension binary streams while it runs its tests, before the binary
+// exits, so progress and failures can be reported as they happen.
+type ResultHandler func(result *ExtensionTestResult)
+
 // RunTests executes the named tests and returns the results.
 func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []string,
+	names ...string) []*ExtensionTestResult {
+	return b.RunTestsStreaming(ctx, timeout, env, nil, names...)
+}
+
+// RunTestsStreaming executes the named tests and returns the results.  The binary writes a line of JSON for each
+// result as its test finishes, which is passed to onResult, if set, as soon as it is read.  The timeout applies to
+// each test, it restarts whenever a result is read.  When the binary crashes or is stopped before writing the result
+// of every test, the results already read are kept and the remaining tests fail with the output that was not part
+// of any result.
+func (b *TestBinary) RunTestsStreaming(ctx context.Context, timeout time.Duration, env []string, onResult ResultHandler,
 	names ...string) []*ExtensionTestResult {
 	var results []*ExtensionTestResult
 	expectedTests := sets.New[string](names...)
@@ -143,10 +172,16 @@ func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []
 	if err != nil {
 		// unlikely to happen in reality, we'll have always run info and cached it before running tests
 		logrus.Warningf("Failed to fetch info for %s: %v", binName, err)
+		info = &ExtensionInfo{}
 	}
 	// Example: k8s-tests-ext's extension will be $ARTIFACT_DIR/openshift/payload/hyperkube
 	env = append(env, fmt.Sprintf("EXTENSION_ARTIFACT_DIR=%s", info.ExtensionArtifactDir))
 
+	// Without streaming, no result is read until every test has run
+	if !b.Supports(CapabilityStreamingResults) {
+		timeout *= time.Duration(len(names))
+	}
+
 	// Build command
 	args := []string{"run-test"}
 	for _, name := range names {
@@ -159,21 +194,19 @@ func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []
 	}
 	command.Env = env
 
-	// Run test
-	testResult, _ := runWithTimeout(ctx, command, timeout) // error is ignored because external binaries return non-zero when a test fails, we only need to process the output
-	buf := bytes.NewBuffer(testResult)
-	for {
-		line, err := buf.ReadString('\n')
-		if err == io.EOF {
-			break
-		}
+	// Run test, output that is not a result is kept for the tests the binary does not produce a result for
+	var unattributedOutput bytes.Buffer
+	runErr := runStreamingWithTimeout(ctx, command, timeout, func(line string) bool {
 		if !strings.HasPrefix(line, "{") {
-			continue
+			unattributedOutput.WriteString(line)
+			return false
 		}
 		result := new(ExtensionTestResult)
-		err = json.Unmarshal([]byte(line), &result)
-		if err != nil {
-			panic(fmt.Sprintf("test binary %q returned unmarshallable result", binName))
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			// a binary that crashes while writing a result leaves a partial line
+			logrus.WithError(err).Warningf("test binary %q returned unmarshallable result", binName)
+			unattributedOutput.WriteString(line)
+			return false
 		}
 		// expectedTests starts with the list of test names we expect, and as we see them, we
 		// remove them from the set. If we encounter a test result that's not in expectedTests,
@@ -185,19 +218,30 @@ func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []
 		if !expectedTests.Has(result.Name) {
 			result.Result = ResultFailed
 			result.Error = fmt.Sprintf("test binary %q returned unexpected result: %s", binName, result.Name)
+		} else if onResult != nil {
+			onResult(result)
 		}
 		expectedTests.Delete(result.Name)
 		results = append(results, result)
-	}
+		return true
+	})
 
 	// If we end up with anything left in expected tests, generate failures for them because
-	// we didn't get results for them.
-	for _, expectedTest := range expectedTests.UnsortedList() {
+	// we didn't get results for them.  External binaries return non-zero when a test fails, so
+	// the error of the binary only explains the tests without results.
+	message := "external binary did not produce a result for this test"
+	switch {
+	case errors.Is(runErr, errStreamingTimeout):
+		message = fmt.Sprintf("external binary did not produce a result for this test within %s", timeout)
+	case runErr != nil:
+		message = fmt.Sprintf("external binary did not produce a result for this test: %v", runErr)
+	}
+	for _, expectedTest := range sets.List(expectedTests) {
 		results = append(results, &ExtensionTestResult{
 			Name:   expectedTest,
 			Result: ResultFailed,
-			Output: string(testResult),
-			Error:  "external binary did not produce a result for this test",
+			Output: unattributedOutput.String(),
+			Error:  message,
 		})
 	}
 
@@ -208,6 +252,14 @@ func (b *TestBinary) ListImages(ctx context.Context) (ImageSet, error) {
 	start := time.Now()
 	binName := filepath.Base(b.binaryPath)
 
+	if _, err := b.Info(ctx); err != nil {
+		return nil, err
+	}
+	if !b.Supports(CapabilityImages) {
+		logrus.Infof("Not listing images for %q, it does not support the %s capability", binName, CapabilityImages)
+		return ImageSet{}, nil
+	}
+
 	logrus.Infof("Listing images for %q", binName)
 	command := exec.Command(b.binaryPath, "images")
 	output, err := runWithTimeout(ctx, command, 10*time.Minute)
@@ -282,6 +334,8 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 		if !os.IsNotExist(err) {
 			logrus.Infof("Detected %v; using cluster profile for image access", ciProfilePullSecretPath)
 			registryAuthFilePath = ciProfilePullSecretPath
+		} else if clusterdiscovery.ReplayedClusterState() != nil {
+			logrus.Warningf("Replaying a cluster state, there is no cluster to read secret/pull-secret from; set REGISTRY_AUTH_FILE for authenticated image access")
 		} else {
 			// Inspect the cluster-under-test and read its cluster pull-secret dockerconfigjson value.
 			clusterPullSecret, err := oc.AdminKubeClient().CoreV1().Secrets("openshift-config").Get(context.Background(), "pull-secret", metav1.GetOptions{})
@@ -306,7 +360,7 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 		}
 	}
 
-	externalBinaryProvider, err := NewExternalBinaryProvider(releaseImage, registryAuthFilePath)
+	externalBinaryProvider, err := NewExternalBinaryProvider(ctx, releaseImage, registryAuthFilePath)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "could not create external binary provider")
 	}
@@ -344,7 +398,7 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 					if !ok {
 						return // Channel is closed
 					}
-					testBinary, err := externalBinaryProvider.ExtractBinaryFromReleaseImage(b.imageTag, b.binaryPath)
+					testBinary, err := externalBinaryProvider.ExtractBinaryFromReleaseImage(ctx, b.imageTag, b.binaryPath)
 					if err != nil {
 						errCh <- err
 						continue
@@ -372,7 +426,17 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 		return nil, nil, fmt.Errorf("encountered errors while extracting binaries: %s", strings.Join(errs, ";"))
 	}
 
-	return externalBinaryProvider.Cleanup, binaries, nil
+	local, err := newLocalTestBinaries()
+	if err != nil {
+		externalBinaryProvider.Cleanup()
+		return nil, nil, err
+	}
+	withLocal, err := withLocalBinaries(ctx, binaries, local, parallelism)
+	if err != nil {
+		externalBinaryProvider.Cleanup()
+		return nil, nil, err
+	}
+	return externalBinaryProvider.Cleanup, withLocal, nil
 }
 
 type TestBinaries []*TestBinary
@@ -594,6 +658,96 @@ func runWithTimeout(ctx context.Context, c *exec.Cmd, timeout time.Duration) ([]
 	return c.CombinedOutput()
 }
 
+// errStreamingTimeout is returned by runStreamingWithTimeout when the command was stopped because it did not
+// make progress within the timeout.
+var errStreamingTimeout = fmt.Errorf("timed out waiting for progress")
+
+// runStreamingWithTimeout runs the command and passes each line of its combined output to onLine as it is written.
+// onLine returns whether the line shows progress, which restarts the timeout.  Like runWithTimeout, a command that
+// does not make progress is interrupted, and aborted for a stack dump if it does not exit within a minute.  The
+// error is errStreamingTimeout when the command timed out, otherwise the error of the command, if any.
+func runStreamingWithTimeout(ctx context.Context, c *exec.Cmd, timeout time.Duration, onLine func(line string) bool) error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	c.Stdout = writer
+	c.Stderr = writer
+	if err := c.Start(); err != nil {
+		writer.Close()
+		return err
+	}
+	// only the command writes now, so the reader sees EOF when it exits
+	writer.Close()
+
+	progress := make(chan struct{}, 1)
+	done := make(chan struct{})
+	defer close(done)
+	var timedOut bool
+	var timedOutLock sync.Mutex
+	go func() {
+		var expired <-chan time.Time
+		var timer *time.Timer
+		if timeout > 0 {
+			timer = time.NewTimer(timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-progress:
+				if timer != nil {
+					if !timer.Stop() {
+						<-timer.C
+					}
+					timer.Reset(timeout)
+				}
+			case <-expired:
+				timedOutLock.Lock()
+				timedOut = true
+				timedOutLock.Unlock()
+				c.Process.Signal(syscall.SIGINT)
+				// if the process appears to be hung a significant amount of time after the timeout
+				// send an ABRT so we get a stack dump
+				select {
+				case <-time.After(time.Minute):
+					c.Process.Signal(syscall.SIGABRT)
+				case <-done:
+				}
+				return
+			case <-ctx.Done():
+				c.Process.Signal(syscall.SIGINT)
+				return
+			}
+		}
+	}()
+
+	buf := bufio.NewReader(reader)
+	for {
+		line, err := buf.ReadString('\n')
+		if len(line) > 0 && onLine(line) {
+			select {
+			case progress <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	waitErr := c.Wait()
+	timedOutLock.Lock()
+	defer timedOutLock.Unlock()
+	if timedOut {
+		return errStreamingTimeout
+	}
+	return waitErr
+}
+
 var safePathRegexp = regexp.MustCompile(`[<>:"/\\|?*\s]+`)
 
 // safeComponentPath sanitizes a component identifier to be safe for use as a file or directory name.
@@ -610,6 +764,9 @@ func safeComponentPath(c *Component) string {
 func (b *TestBinary) filterToApplicableEnvironmentFlags(envFlags EnvironmentFlags) EnvironmentFlags {
 	apiVersion := b.info.APIVersion
 	filtered := EnvironmentFlags{}
+	if !b.Supports(CapabilityEnvironmentFlags) {
+		return filtered
+	}
 	for _, flag := range envFlags {
 		if semver.Compare(apiVersion, flag.SinceVersion) >= 0 {
 			filtered = append(filtered, flag)
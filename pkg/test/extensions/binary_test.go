@@ -0,0 +1,87 @@
+package extensions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExtensionBinary returns a binary that runs the script, in place of an extension binary's run-test command.
+func fakeExtensionBinary(t *testing.T, script string) *TestBinary {
+	path := filepath.Join(t.TempDir(), "fake-tests-ext")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return &TestBinary{binaryPath: path, info: &ExtensionInfo{
+		APIVersion:   CurrentAPIVersion,
+		Capabilities: []Capability{CapabilityStreamingResults},
+	}}
+}
+
+func TestRunTestsStreaming(t *testing.T) {
+	binary := fakeExtensionBinary(t, `
+echo 'starting tests'
+echo '{"name":"a","result":"passed","output":"a passed"}'
+echo '{"name":"b","result":"failed","output":"b failed","error":"boom"}'
+echo '{"name":"unexpected","result":"passed"}'
+exit 1
+`)
+
+	var streamed []string
+	results := binary.RunTestsStreaming(context.Background(), time.Minute, nil, func(result *ExtensionTestResult) {
+		streamed = append(streamed, result.Name)
+	}, "a", "b")
+
+	assert.Equal(t, []string{"a", "b"}, streamed, "only expected results are streamed")
+	require.Len(t, results, 3)
+	assert.Equal(t, ResultPassed, results[0].Result)
+	assert.Equal(t, "boom", results[1].Error)
+	assert.Equal(t, ResultFailed, results[2].Result)
+	assert.Contains(t, results[2].Error, "returned unexpected result")
+}
+
+func TestRunTestsStreamingCrash(t *testing.T) {
+	binary := fakeExtensionBinary(t, `
+echo '{"name":"a","result":"passed"}'
+echo 'panic: runtime error'
+printf '{"name":"b","res'
+exit 2
+`)
+
+	results := binary.RunTests(context.Background(), time.Minute, nil, "a", "b", "c")
+	require.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].Name)
+	assert.Equal(t, ResultPassed, results[0].Result)
+	for _, result := range results[1:] {
+		assert.Equal(t, ResultFailed, result.Result)
+		assert.Equal(t, "external binary did not produce a result for this test: exit status 2", result.Error)
+		assert.Equal(t, "panic: runtime error\n{\"name\":\"b\",\"res", result.Output)
+	}
+	assert.Equal(t, []string{"b", "c"}, []string{results[1].Name, results[2].Name})
+}
+
+func TestRunTestsStreamingTimeout(t *testing.T) {
+	// each result restarts the timeout, so only the test that never finishes times out
+	binary := fakeExtensionBinary(t, `
+echo '{"name":"a","result":"passed"}'
+sleep 0.6
+echo '{"name":"b","result":"passed"}'
+sleep 0.6
+echo '{"name":"c","result":"passed"}'
+exec sleep 30
+`)
+
+	start := time.Now()
+	results := binary.RunTests(context.Background(), time.Second, nil, "a", "b", "c", "d")
+	assert.Less(t, time.Since(start), 10*time.Second)
+	require.Len(t, results, 4)
+	for _, result := range results[:3] {
+		assert.Equal(t, ResultPassed, result.Result, result.Name)
+	}
+	assert.Equal(t, "d", results[3].Name)
+	assert.Equal(t, ResultFailed, results[3].Result)
+	assert.Equal(t, "external binary did not produce a result for this test within 1s", results[3].Error)
+}
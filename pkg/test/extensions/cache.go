@@ -0,0 +1,367 @@
+package extensions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// binaryCacheDirName is the directory of the cache binaries are extracted to, keyed by image digest and path.
+	binaryCacheDirName = "binaries"
+	// cacheMetadataFile describes the binary of a cache entry, and is written once the binary is complete.
+	cacheMetadataFile = "metadata.json"
+	// cacheLockFile is locked by every process using a cache entry, and its modification time is when it was last used.
+	cacheLockFile = ".lock"
+	// cacheExtractLockFile is locked by the process extracting the binary of a cache entry, which processes that find
+	// the entry incomplete wait for.
+	cacheExtractLockFile = ".extract.lock"
+	// cacheLockPollInterval is how often a process waiting for another retries a lock.
+	cacheLockPollInterval = 250 * time.Millisecond
+
+	// DefaultCacheMaxAge is how long a cache entry may go unused before it is pruned.
+	DefaultCacheMaxAge = 7 * 24 * time.Hour
+)
+
+// CacheDir returns the directory external binaries are cached in.
+func CacheDir() string {
+	if cacheHome := os.Getenv("XDG_CACHE_HOME"); len(cacheHome) > 0 {
+		return path.Join(cacheHome, "openshift-tests")
+	}
+	return path.Join(os.Getenv("HOME"), ".cache", "openshift-tests")
+}
+
+// CachedBinary is a binary extracted from an image into the cache.
+type CachedBinary struct {
+	// Image the binary was extracted from.
+	Image string `json:"image"`
+	// Path of the binary in the image.
+	Path string `json:"path"`
+	// SHA256 and Size of the extracted, and decompressed, binary.
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+
+	ExtractedAt time.Time `json:"extractedAt"`
+
+	// Dir is the cache entry of the binary.
+	Dir string `json:"-"`
+	// LastUsed is when openshift-tests last used the binary.
+	LastUsed time.Time `json:"-"`
+}
+
+// binaryCacheKey returns the cache entry of binary in image, relative to the binary cache.  Images are nearly always
+// referenced by digest in a release payload, images referenced by tag are keyed by their pull spec instead.
+func binaryCacheKey(image, binary string) string {
+	imageKey := pullSpecToDirName(image)
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		imageKey = strings.ReplaceAll(image[i+1:], ":", "-")
+	}
+	return filepath.Join(imageKey, pullSpecToDirName(strings.TrimPrefix(binary, "/")))
+}
+
+// BinaryPath returns the path of the cached binary.
+func (c *CachedBinary) BinaryPath() string {
+	return filepath.Join(c.Dir, strings.TrimSuffix(filepath.Base(c.Path), ".gz"))
+}
+
+// readCachedBinary reads the cache entry in dir, which is incomplete when the metadata does not exist.
+func readCachedBinary(dir string) (*CachedBinary, error) {
+	cached := &CachedBinary{Dir: dir}
+	if lockInfo, err := os.Stat(filepath.Join(dir, cacheLockFile)); err == nil {
+		cached.LastUsed = lockInfo.ModTime()
+	} else if dirInfo, err := os.Stat(dir); err == nil {
+		cached.LastUsed = dirInfo.ModTime()
+	}
+	content, err := os.ReadFile(filepath.Join(dir, cacheMetadataFile))
+	if err != nil {
+		return cached, err
+	}
+	if err := json.Unmarshal(content, cached); err != nil {
+		return cached, fmt.Errorf("invalid cache metadata: %w", err)
+	}
+	return cached, nil
+}
+
+// writeCachedBinary records the checksum of the binary extracted to dir, completing the cache entry.
+func writeCachedBinary(dir, image, binary, binaryPath string) (*CachedBinary, error) {
+	checksum, size, err := sha256File(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	cached := &CachedBinary{
+		Image:       image,
+		Path:        binary,
+		SHA256:      checksum,
+		Size:        size,
+		ExtractedAt: time.Now().UTC(),
+		Dir:         dir,
+		LastUsed:    time.Now(),
+	}
+	content, err := json.MarshalIndent(cached, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	// written with a rename, so the metadata of an entry is never partially written
+	tmpFile := filepath.Join(dir, cacheMetadataFile+".tmp")
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpFile, filepath.Join(dir, cacheMetadataFile)); err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// Verify checks the cached binary matches its checksum, waiting for another process extracting it to finish.
+func (c *CachedBinary) Verify() error {
+	ctx := context.Background()
+	lock, err := lockCacheEntry(ctx, c.Dir, syscall.LOCK_SH)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	extraction, err := lockCacheExtraction(ctx, c.Dir, syscall.LOCK_SH)
+	if err != nil {
+		return err
+	}
+	defer extraction.Unlock()
+	cached, err := readCachedBinary(c.Dir)
+	if err != nil {
+		return fmt.Errorf("incomplete cache entry: %w", err)
+	}
+	return cached.verify()
+}
+
+// readVerifiedCachedBinary reads the cache entry in dir, and checks its binary matches its checksum.
+func readVerifiedCachedBinary(dir string) (*CachedBinary, error) {
+	cached, err := readCachedBinary(dir)
+	if err != nil {
+		return nil, err
+	}
+	return cached, cached.verify()
+}
+
+// cachedOrExtract returns the binary of the cache entry in dir, calling extract when the entry is incomplete or
+// invalid.  The caller must hold a shared lock of the entry.  Only one process extracts the binary of an entry at a
+// time, the others wait for it and then use the binary it extracted, until ctx is done.
+func cachedOrExtract(ctx context.Context, dir string, extract func() (*CachedBinary, error)) (cached *CachedBinary, extracted bool, err error) {
+	if cached, err := readVerifiedCachedBinary(dir); err == nil {
+		return cached, false, nil
+	}
+
+	extraction, err := lockCacheExtraction(ctx, dir, syscall.LOCK_EX)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed waiting to extract %s: %w", dir, err)
+	}
+	defer extraction.Unlock()
+
+	// another process may have extracted the binary while this one waited
+	cached, err = readVerifiedCachedBinary(dir)
+	switch {
+	case err == nil:
+		return cached, false, nil
+	case !os.IsNotExist(err):
+		logrus.WithError(err).Warningf("Extracting the binary of %s again, the cached binary is invalid", dir)
+	}
+	cached, err = extract()
+	if err != nil {
+		return nil, false, err
+	}
+	return cached, true, nil
+}
+
+// verify checks the cached binary matches its checksum.  The caller must hold the lock of the entry.
+func (c *CachedBinary) verify() error {
+	checksum, size, err := sha256File(c.BinaryPath())
+	if err != nil {
+		return err
+	}
+	if size != c.Size || checksum != c.SHA256 {
+		return fmt.Errorf("%s has checksum sha256:%s and size %d, expected sha256:%s and size %d",
+			c.BinaryPath(), checksum, size, c.SHA256, c.Size)
+	}
+	return nil
+}
+
+func sha256File(filename string) (string, int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// ListCachedBinaries returns the binaries in the cache in dir, including incomplete entries, which have only their Dir
+// and LastUsed set.
+func ListCachedBinaries(dir string) ([]*CachedBinary, error) {
+	entryDirs, err := filepath.Glob(filepath.Join(dir, binaryCacheDirName, "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(entryDirs)
+	var binaries []*CachedBinary
+	for _, entryDir := range entryDirs {
+		cached, err := readCachedBinary(entryDir)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.WithError(err).Warningf("Unable to read cache entry %s", entryDir)
+		}
+		binaries = append(binaries, cached)
+	}
+	return binaries, nil
+}
+
+// PruneCache removes the entries of the cache in dir that were not used for maxAge, and returns the removed
+// directories.  Entries in use by another process are kept.
+func PruneCache(dir string, maxAge time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// release payload image references, and anything cached before binaries were keyed by digest
+	var candidates []*CachedBinary
+	for _, entry := range entries {
+		if entry.Name() == binaryCacheDirName {
+			continue
+		}
+		entryPath := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			if err := os.Remove(entryPath); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cached, _ := readCachedBinary(entryPath)
+		candidates = append(candidates, cached)
+	}
+	binaries, err := ListCachedBinaries(dir)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, binaries...)
+
+	var removed []string
+	for _, cached := range candidates {
+		if time.Since(cached.LastUsed) < maxAge {
+			continue
+		}
+		lock, err := lockCacheEntry(context.Background(), cached.Dir, syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == syscall.EWOULDBLOCK {
+			logrus.Infof("Keeping cache entry %s, which is in use", cached.Dir)
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		err = os.RemoveAll(cached.Dir)
+		lock.Unlock()
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, cached.Dir)
+		// remove the directory of the image once none of its binaries are left
+		if imageDir := filepath.Dir(cached.Dir); filepath.Base(filepath.Dir(imageDir)) == binaryCacheDirName {
+			_ = os.Remove(imageDir)
+		}
+	}
+	return removed, nil
+}
+
+// cacheLock is a lock on a cache entry, shared by the openshift-tests processes on a host.
+type cacheLock struct {
+	file *os.File
+}
+
+// lockCacheEntry locks the cache entry in dir, creating it when it does not exist, and marks it as used.  how is
+// LOCK_SH to use the entry, which keeps it from being pruned, or LOCK_EX to remove it, optionally with LOCK_NB to fail
+// with EWOULDBLOCK instead of waiting for another process.  Waiting stops when ctx is done.
+func lockCacheEntry(ctx context.Context, dir string, how int) (*cacheLock, error) {
+	for {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		lockPath := filepath.Join(dir, cacheLockFile)
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := flock(ctx, file, how); err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		// the entry is pruned by whoever held the lock while this process waited for it, so lock the new entry
+		locked, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(lockPath); err != nil || !os.SameFile(locked, current) {
+			file.Close()
+			continue
+		}
+
+		now := time.Now()
+		if err := os.Chtimes(lockPath, now, now); err != nil {
+			logrus.WithError(err).Warningf("Unable to mark cache entry %s used", dir)
+		}
+		return &cacheLock{file: file}, nil
+	}
+}
+
+// lockCacheExtraction locks the extraction of the binary of the cache entry in dir, whose lock the caller must hold so
+// the entry is not pruned.  how is LOCK_EX to extract the binary, or LOCK_SH to wait for another process extracting it.
+// Waiting stops when ctx is done.
+func lockCacheExtraction(ctx context.Context, dir string, how int) (*cacheLock, error) {
+	file, err := os.OpenFile(filepath.Join(dir, cacheExtractLockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := flock(ctx, file, how); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &cacheLock{file: file}, nil
+}
+
+// flock locks file, polling while another process holds it, since a blocked flock cannot be interrupted when ctx is
+// done.  With LOCK_NB it fails with EWOULDBLOCK instead.
+func flock(ctx context.Context, file *os.File, how int) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), how|syscall.LOCK_NB)
+		if err != syscall.EWOULDBLOCK || how&syscall.LOCK_NB != 0 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cacheLockPollInterval):
+		}
+	}
+}
+
+func (l *cacheLock) Unlock() {
+	// closing the file releases the lock
+	l.file.Close()
+}
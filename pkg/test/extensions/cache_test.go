@@ -0,0 +1,216 @@
+package extensions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryCacheKey(t *testing.T) {
+	assert.Equal(t,
+		filepath.Join("sha256-0123abcd", pullSpecToDirName("usr/bin/k8s-tests-ext.gz")),
+		binaryCacheKey("quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0123abcd", "/usr/bin/k8s-tests-ext.gz"))
+	assert.Equal(t,
+		filepath.Join(pullSpecToDirName("registry.example.com/hyperkube:latest"), pullSpecToDirName("usr/bin/k8s-tests-ext.gz")),
+		binaryCacheKey("registry.example.com/hyperkube:latest", "/usr/bin/k8s-tests-ext.gz"))
+}
+
+func TestCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	assert.Equal(t, "/cache/openshift-tests", CacheDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "/home/sally")
+	assert.Equal(t, "/home/sally/.cache/openshift-tests", CacheDir())
+}
+
+// cacheBinary adds a binary to the cache in dir, as if extracted from image.
+func cacheBinary(t *testing.T, dir, image string) *CachedBinary {
+	entryDir := filepath.Join(dir, binaryCacheDirName, binaryCacheKey(image, "/usr/bin/k8s-tests-ext.gz"))
+	lock, err := lockCacheEntry(context.Background(), entryDir, syscall.LOCK_EX)
+	require.NoError(t, err)
+	defer lock.Unlock()
+	binaryPath := filepath.Join(entryDir, "k8s-tests-ext")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("binary of "+image), 0755))
+	cached, err := writeCachedBinary(entryDir, image, "/usr/bin/k8s-tests-ext.gz", binaryPath)
+	require.NoError(t, err)
+	return cached
+}
+
+// lastUsed makes the cache entry in dir look unused since t.
+func lastUsed(t *testing.T, dir string, used time.Time) {
+	require.NoError(t, os.Chtimes(filepath.Join(dir, cacheLockFile), used, used))
+}
+
+func TestCachedBinaryVerify(t *testing.T) {
+	dir := t.TempDir()
+	cached := cacheBinary(t, dir, "example.com/hyperkube@sha256:0123abcd")
+	assert.Equal(t, filepath.Join(cached.Dir, "k8s-tests-ext"), cached.BinaryPath())
+	require.NoError(t, cached.Verify())
+
+	require.NoError(t, os.WriteFile(cached.BinaryPath(), []byte("truncated"), 0755))
+	assert.ErrorContains(t, cached.Verify(), "has checksum sha256:")
+
+	require.NoError(t, os.Remove(filepath.Join(cached.Dir, cacheMetadataFile)))
+	assert.ErrorContains(t, cached.Verify(), "incomplete cache entry")
+}
+
+func TestListCachedBinaries(t *testing.T) {
+	dir := t.TempDir()
+	cacheBinary(t, dir, "example.com/hyperkube@sha256:0123abcd")
+	incomplete := filepath.Join(dir, binaryCacheDirName, binaryCacheKey("example.com/tests@sha256:4567cdef", "/usr/bin/tests-ext"))
+	require.NoError(t, os.MkdirAll(incomplete, 0755))
+
+	binaries, err := ListCachedBinaries(dir)
+	require.NoError(t, err)
+	require.Len(t, binaries, 2)
+	assert.Equal(t, "example.com/hyperkube@sha256:0123abcd", binaries[0].Image)
+	assert.Equal(t, "/usr/bin/k8s-tests-ext.gz", binaries[0].Path)
+	assert.NotEmpty(t, binaries[0].SHA256)
+	assert.WithinDuration(t, time.Now(), binaries[0].LastUsed, time.Minute)
+	assert.Equal(t, incomplete, binaries[1].Dir)
+	assert.Empty(t, binaries[1].SHA256)
+}
+
+func TestPruneCache(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-8 * 24 * time.Hour)
+
+	recent := cacheBinary(t, dir, "example.com/recent@sha256:01")
+	unused := cacheBinary(t, dir, "example.com/unused@sha256:02")
+	lastUsed(t, unused.Dir, old)
+	inUse := cacheBinary(t, dir, "example.com/in-use@sha256:03")
+	lock, err := lockCacheEntry(context.Background(), inUse.Dir, syscall.LOCK_SH)
+	require.NoError(t, err)
+	defer lock.Unlock()
+	lastUsed(t, inUse.Dir, old)
+
+	// release payload image references, and binaries cached before they were keyed by digest
+	releaseDir := filepath.Join(dir, "quay_io_openshift-release-dev_ocp-release_4_18_0")
+	require.NoError(t, os.MkdirAll(releaseDir, 0755))
+	require.NoError(t, os.Chtimes(releaseDir, old, old))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cache_test.sh"), nil, 0755))
+
+	removed, err := PruneCache(dir, DefaultCacheMaxAge)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{releaseDir, unused.Dir}, removed)
+	assert.NoDirExists(t, filepath.Dir(unused.Dir), "the directory of an image without binaries is removed")
+	assert.NoFileExists(t, filepath.Join(dir, "cache_test.sh"))
+	assert.DirExists(t, recent.Dir)
+	assert.DirExists(t, inUse.Dir)
+
+	removed, err = PruneCache(dir, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent.Dir}, removed, "binaries in use are never removed")
+
+	removed, err = PruneCache(filepath.Join(dir, "missing"), 0)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestLockCacheEntryAfterPrune(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "entry")
+	lock, err := lockCacheEntry(context.Background(), dir, syscall.LOCK_EX)
+	require.NoError(t, err)
+
+	locked := make(chan *cacheLock)
+	go func() {
+		waiting, err := lockCacheEntry(context.Background(), dir, syscall.LOCK_EX)
+		assert.NoError(t, err)
+		locked <- waiting
+	}()
+
+	// the entry is pruned while the other lock waits, which then locks the entry it recreates
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.RemoveAll(dir))
+	lock.Unlock()
+
+	waiting := <-locked
+	defer waiting.Unlock()
+	assert.FileExists(t, filepath.Join(dir, cacheLockFile))
+	_, err = lockCacheEntry(context.Background(), dir, syscall.LOCK_EX|syscall.LOCK_NB)
+	assert.Equal(t, syscall.EWOULDBLOCK, err)
+}
+
+func TestConcurrentCacheUsers(t *testing.T) {
+	image := "example.com/hyperkube@sha256:0123abcd"
+	dir := filepath.Join(t.TempDir(), binaryCacheDirName, binaryCacheKey(image, "/usr/bin/k8s-tests-ext.gz"))
+	var extractions atomic.Int32
+	extract := func() (*CachedBinary, error) {
+		extractions.Add(1)
+		// long enough for the other user to find the entry incomplete and wait for this extraction
+		time.Sleep(500 * time.Millisecond)
+		binaryPath := filepath.Join(dir, "k8s-tests-ext")
+		if err := os.WriteFile(binaryPath, []byte("binary of "+image), 0755); err != nil {
+			return nil, err
+		}
+		return writeCachedBinary(dir, image, "/usr/bin/k8s-tests-ext.gz", binaryPath)
+	}
+
+	// two users of the same entry, which both keep it locked while they run the binary
+	var wg sync.WaitGroup
+	binaries := make([]*CachedBinary, 2)
+	for i := range binaries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := lockCacheEntry(context.Background(), dir, syscall.LOCK_SH)
+			if !assert.NoError(t, err) {
+				return
+			}
+			t.Cleanup(lock.Unlock)
+			binaries[i], _, err = cachedOrExtract(context.Background(), dir, extract)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), extractions.Load(), "only one user extracts the binary")
+	require.NotNil(t, binaries[0])
+	require.NotNil(t, binaries[1])
+	assert.Equal(t, binaries[0].BinaryPath(), binaries[1].BinaryPath())
+	require.NoError(t, binaries[0].Verify())
+
+	// another user does not wait for the users already holding the entry
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	lock, err := lockCacheEntry(ctx, dir, syscall.LOCK_SH)
+	require.NoError(t, err)
+	defer lock.Unlock()
+	cached, extracted, err := cachedOrExtract(ctx, dir, extract)
+	require.NoError(t, err)
+	assert.False(t, extracted)
+	assert.Equal(t, binaries[0].BinaryPath(), cached.BinaryPath())
+
+	cacheDir := filepath.Dir(filepath.Dir(filepath.Dir(dir)))
+	listed, err := ListCachedBinaries(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	removed, err := PruneCache(cacheDir, 0)
+	require.NoError(t, err)
+	assert.Empty(t, removed, "binaries in use are never removed")
+}
+
+func TestCachedOrExtractStopsWaiting(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "entry")
+	lock, err := lockCacheEntry(context.Background(), dir, syscall.LOCK_SH)
+	require.NoError(t, err)
+	defer lock.Unlock()
+	extraction, err := lockCacheExtraction(context.Background(), dir, syscall.LOCK_EX)
+	require.NoError(t, err)
+	defer extraction.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err = cachedOrExtract(ctx, dir, func() (*CachedBinary, error) {
+		t.Error("extracted while another process was extracting")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
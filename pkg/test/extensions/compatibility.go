@@ -0,0 +1,189 @@
+package extensions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// The range of extension API versions openshift-tests supports.  Extensions with a newer minor version are run with
+// the features of CurrentAPIVersion, extensions with an older or a newer major version are not run at all.
+const (
+	MinimumAPIVersion = "v1.0"
+	CurrentAPIVersion = "v1.1"
+)
+
+// Capability is an optional feature of the extension interface that an extension declares it supports.
+type Capability string
+
+const (
+	// CapabilityStreamingResults extensions write the result of each test as soon as it finishes.
+	CapabilityStreamingResults Capability = "streaming-results"
+	// CapabilityImages extensions list the images their tests use with the images command.
+	CapabilityImages Capability = "images"
+	// CapabilityEnvironmentFlags extensions filter the tests they list by environment flags.
+	CapabilityEnvironmentFlags Capability = "environment-flags"
+)
+
+// knownCapabilities are the capabilities openshift-tests makes use of.
+var knownCapabilities = sets.New(
+	CapabilityStreamingResults,
+	CapabilityImages,
+	CapabilityEnvironmentFlags,
+)
+
+// defaultCapabilities are assumed for extensions that do not declare their capabilities, which every extension of
+// the first version of the API supports.
+var defaultCapabilities = []Capability{
+	CapabilityImages,
+	CapabilityEnvironmentFlags,
+}
+
+// degradedBehavior describes how openshift-tests works with an extension without a capability.
+var degradedBehavior = map[Capability]string{
+	CapabilityStreamingResults: "results are read when the binary exits, the timeout covers every test of the run",
+	CapabilityImages:           "the images of the extension are not listed or mirrored",
+	CapabilityEnvironmentFlags: "tests are listed without environment flags and filtered only by openshift-tests",
+}
+
+// CompatibilityReport describes how openshift-tests works with an extension.
+type CompatibilityReport struct {
+	Component            Component `json:"component"`
+	Source               Source    `json:"source"`
+	APIVersion           string    `json:"apiVersion"`
+	SupportedAPIVersions string    `json:"supportedAPIVersions"`
+	Compatible           bool      `json:"compatible"`
+	// Reason explains why an extension is not compatible, or why it is only partially supported.
+	Reason string `json:"reason,omitempty"`
+	// DeclaredCapabilities is nil when the extension does not declare capabilities and the defaults are assumed.
+	DeclaredCapabilities []Capability `json:"declaredCapabilities"`
+	Capabilities         []Capability `json:"capabilities"`
+	UnknownCapabilities  []Capability `json:"unknownCapabilities,omitempty"`
+	// Degraded maps each capability the extension lacks to how openshift-tests works without it.
+	Degraded map[Capability]string `json:"degraded,omitempty"`
+}
+
+// checkAPIVersion returns an error when openshift-tests cannot run an extension using apiVersion, and a warning when
+// it can, but not every feature of the extension is understood.
+func checkAPIVersion(apiVersion string) (warning string, err error) {
+	supported := fmt.Sprintf("openshift-tests supports %s through %s.x", MinimumAPIVersion, semver.Major(CurrentAPIVersion))
+	switch {
+	case !semver.IsValid(apiVersion):
+		return "", fmt.Errorf("invalid API version %q, %s", apiVersion, supported)
+	case semver.Compare(apiVersion, MinimumAPIVersion) < 0:
+		return "", fmt.Errorf("API version %s is too old, %s, rebuild the extension with a newer openshift-tests-extension", apiVersion, supported)
+	case semver.Compare(semver.Major(apiVersion), semver.Major(CurrentAPIVersion)) > 0:
+		return "", fmt.Errorf("API version %s is too new, %s, use a newer openshift-tests", apiVersion, supported)
+	case semver.Compare(semver.MajorMinor(apiVersion), CurrentAPIVersion) > 0:
+		return fmt.Sprintf("API version %s is newer than %s, features added since are not used", apiVersion, CurrentAPIVersion), nil
+	}
+	return "", nil
+}
+
+// capabilities returns the capabilities of the extension that openshift-tests uses, which requires its info.
+func (b *TestBinary) capabilities() sets.Set[Capability] {
+	if b.info.Capabilities == nil {
+		return sets.New(defaultCapabilities...)
+	}
+	return sets.New(b.info.Capabilities...).Intersection(knownCapabilities)
+}
+
+// Supports returns whether the extension supports the capability.  The info of the extension must have been fetched.
+func (b *TestBinary) Supports(capability Capability) bool {
+	if b.info == nil {
+		return false
+	}
+	return b.capabilities().Has(capability)
+}
+
+// checkCompatibility returns an error when openshift-tests cannot run the extension.
+func (b *TestBinary) checkCompatibility() error {
+	if _, err := checkAPIVersion(b.info.APIVersion); err != nil {
+		return fmt.Errorf("extension %s in %s:%s is not compatible: %w",
+			componentID(&b.info.Component), b.info.Source.SourceImage, b.info.Source.SourceBinary, err)
+	}
+	return nil
+}
+
+// logCompatibility logs how openshift-tests works with a compatible extension that is not fully supported.
+func (b *TestBinary) logCompatibility() {
+	report := b.CompatibilityReport()
+	logger := logrus.WithField("extension", componentID(&report.Component))
+	if len(report.Reason) > 0 {
+		logger.Warning(report.Reason)
+	}
+	for _, capability := range report.UnknownCapabilities {
+		logger.Infof("Ignoring unknown capability %s", capability)
+	}
+	for _, capability := range sets.List(sets.KeySet(report.Degraded)) {
+		logger.Infof("Extension does not support %s, %s", capability, report.Degraded[capability])
+	}
+}
+
+// CompatibilityReport describes how openshift-tests works with the extension, or nil when its info was not fetched.
+func (b *TestBinary) CompatibilityReport() *CompatibilityReport {
+	if b.info == nil {
+		return nil
+	}
+	report := &CompatibilityReport{
+		Component:            b.info.Component,
+		Source:               b.info.Source,
+		APIVersion:           b.info.APIVersion,
+		SupportedAPIVersions: fmt.Sprintf("%s-%s.x", MinimumAPIVersion, semver.Major(CurrentAPIVersion)),
+		Compatible:           true,
+		DeclaredCapabilities: b.info.Capabilities,
+		UnknownCapabilities:  sets.List(sets.New(b.info.Capabilities...).Difference(knownCapabilities)),
+	}
+
+	warning, err := checkAPIVersion(b.info.APIVersion)
+	if err != nil {
+		report.Compatible = false
+		report.Reason = err.Error()
+		return report
+	}
+	report.Reason = warning
+
+	capabilities := b.capabilities()
+	report.Capabilities = sets.List(capabilities)
+	for _, capability := range sets.List(knownCapabilities.Difference(capabilities)) {
+		if report.Degraded == nil {
+			report.Degraded = map[Capability]string{}
+		}
+		report.Degraded[capability] = degradedBehavior[capability]
+	}
+	return report
+}
+
+func componentID(c *Component) string {
+	return fmt.Sprintf("%s:%s:%s", c.Product, c.Kind, c.Name)
+}
+
+// WriteCompatibilityReports writes the compatibility report of every extension whose info was fetched to
+// compatibility.json in the artifact directory of the extension.  Nothing is written without an ARTIFACT_DIR.
+func (binaries TestBinaries) WriteCompatibilityReports() error {
+	if len(os.Getenv("ARTIFACT_DIR")) == 0 {
+		return nil
+	}
+	for _, binary := range binaries {
+		report := binary.CompatibilityReport()
+		if report == nil {
+			continue
+		}
+		content, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(binary.info.ExtensionArtifactDir, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(binary.info.ExtensionArtifactDir, "compatibility.json"), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package extensions
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		apiVersion string
+		warning    string
+		err        string
+	}{
+		{apiVersion: "v1.0"},
+		{apiVersion: "v1.1"},
+		{apiVersion: "v1.1.3"},
+		{apiVersion: "v1.2", warning: "API version v1.2 is newer than v1.1, features added since are not used"},
+		{apiVersion: "v0.9", err: "API version v0.9 is too old, openshift-tests supports v1.0 through v1.x, rebuild the extension with a newer openshift-tests-extension"},
+		{apiVersion: "v2.0", err: "API version v2.0 is too new, openshift-tests supports v1.0 through v1.x, use a newer openshift-tests"},
+		{apiVersion: "", err: `invalid API version "", openshift-tests supports v1.0 through v1.x`},
+	}
+	for _, test := range tests {
+		t.Run(test.apiVersion, func(t *testing.T) {
+			warning, err := checkAPIVersion(test.apiVersion)
+			assert.Equal(t, test.warning, warning)
+			if len(test.err) > 0 {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCompatibilityReport(t *testing.T) {
+	undeclared := &TestBinary{info: &ExtensionInfo{APIVersion: "v1.0"}}
+	assert.Equal(t, &CompatibilityReport{
+		APIVersion:           "v1.0",
+		SupportedAPIVersions: "v1.0-v1.x",
+		Compatible:           true,
+		Capabilities:         []Capability{CapabilityEnvironmentFlags, CapabilityImages},
+		UnknownCapabilities:  []Capability{},
+		Degraded:             map[Capability]string{CapabilityStreamingResults: degradedBehavior[CapabilityStreamingResults]},
+	}, undeclared.CompatibilityReport())
+	assert.True(t, undeclared.Supports(CapabilityEnvironmentFlags))
+	assert.False(t, undeclared.Supports(CapabilityStreamingResults))
+
+	declared := &TestBinary{info: &ExtensionInfo{
+		APIVersion:   "v1.2",
+		Capabilities: []Capability{CapabilityStreamingResults, "teleportation"},
+	}}
+	report := declared.CompatibilityReport()
+	assert.True(t, report.Compatible)
+	assert.Equal(t, "API version v1.2 is newer than v1.1, features added since are not used", report.Reason)
+	assert.Equal(t, []Capability{CapabilityStreamingResults}, report.Capabilities)
+	assert.Equal(t, []Capability{"teleportation"}, report.UnknownCapabilities)
+	assert.Len(t, report.Degraded, 2)
+	assert.Empty(t, declared.filterToApplicableEnvironmentFlags(EnvironmentFlags{newEnvironmentFlag(platform, "aws")}))
+
+	incompatible := &TestBinary{info: &ExtensionInfo{
+		APIVersion: "v2.0",
+		Component:  Component{Product: "openshift", Kind: "payload", Name: "example"},
+		Source:     Source{SourceImage: "tests", SourceBinary: "example-tests-ext"},
+	}}
+	report = incompatible.CompatibilityReport()
+	assert.False(t, report.Compatible)
+	assert.Contains(t, report.Reason, "too new")
+	_, err := incompatible.Info(context.Background())
+	assert.EqualError(t, err, "extension openshift:payload:example in tests:example-tests-ext is not compatible: API version v2.0 is too new, openshift-tests supports v1.0 through v1.x, use a newer openshift-tests")
+
+	images, err := declared.ListImages(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestRunTestsWithoutStreaming(t *testing.T) {
+	binary := fakeExtensionBinary(t, `
+sleep 1.5
+echo '{"name":"a","result":"passed"}'
+echo '{"name":"b","result":"passed"}'
+`)
+	binary.info.Capabilities = []Capability{}
+
+	// the timeout covers both tests, since no result is read until the binary exits
+	results := binary.RunTests(context.Background(), time.Second, nil, "a", "b")
+	require.Len(t, results, 2)
+	assert.Equal(t, ResultPassed, results[0].Result)
+	assert.Equal(t, ResultPassed, results[1].Result)
+}
+
+func TestWriteCompatibilityReports(t *testing.T) {
+	artifactDir := t.TempDir()
+	t.Setenv("ARTIFACT_DIR", artifactDir)
+	extensionArtifactDir := filepath.Join(artifactDir, "openshift", "payload", "example")
+	binaries := TestBinaries{
+		{info: &ExtensionInfo{APIVersion: "v0.1", ExtensionArtifactDir: extensionArtifactDir}},
+		{},
+	}
+	require.NoError(t, binaries.WriteCompatibilityReports())
+
+	content, err := os.ReadFile(filepath.Join(extensionArtifactDir, "compatibility.json"))
+	require.NoError(t, err)
+	report := &CompatibilityReport{}
+	require.NoError(t, json.Unmarshal(content, report))
+	assert.False(t, report.Compatible)
+	assert.Equal(t, "v0.1", report.APIVersion)
+}
@@ -0,0 +1,157 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// localImageTag is the source image reported for extension binaries that are not extracted from the release payload.
+const localImageTag = "local"
+
+// LocalBinary is a locally built extension binary, run instead of the extension with the same component in the
+// release payload, or in addition to the extensions of the payload when there is none.
+type LocalBinary struct {
+	// Path to the binary.
+	Path string `json:"path"`
+	// Component the binary must report, when set.
+	Component *Component `json:"component,omitempty"`
+}
+
+// LocalBinaryConfig is the file format of --extension-binaries-file.
+type LocalBinaryConfig struct {
+	Binaries []LocalBinary `json:"binaries"`
+}
+
+// localBinaries are added to the extensions of the release payload by ExtractAllTestBinaries.
+var localBinaries []LocalBinary
+
+// UseLocalBinaries makes ExtractAllTestBinaries add the binaries at paths, and the binaries listed in configFile,
+// to the extensions of the release payload.  Empty paths and configFile leave extraction alone.
+func UseLocalBinaries(paths []string, configFile string) error {
+	var binaries []LocalBinary
+	for _, path := range paths {
+		binaries = append(binaries, LocalBinary{Path: path})
+	}
+	if len(configFile) > 0 {
+		config, err := ReadLocalBinaryConfig(configFile)
+		if err != nil {
+			return err
+		}
+		binaries = append(binaries, config.Binaries...)
+	}
+	for _, binary := range binaries {
+		if _, err := newLocalTestBinary(binary); err != nil {
+			return err
+		}
+	}
+	localBinaries = binaries
+	return nil
+}
+
+// ReadLocalBinaryConfig reads a yaml or json file listing local extension binaries.  Relative paths are relative to
+// the directory of the file.
+func ReadLocalBinaryConfig(filename string) (*LocalBinaryConfig, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read extension binaries file: %w", err)
+	}
+	config := &LocalBinaryConfig{}
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return nil, fmt.Errorf("unable to parse extension binaries file %s: %w", filename, err)
+	}
+	for i := range config.Binaries {
+		binary := &config.Binaries[i]
+		if len(binary.Path) == 0 {
+			return nil, fmt.Errorf("extension binaries file %s: binary %d has no path", filename, i)
+		}
+		if !filepath.IsAbs(binary.Path) {
+			binary.Path = filepath.Join(filepath.Dir(filename), binary.Path)
+		}
+	}
+	return config, nil
+}
+
+// newLocalTestBinary checks the local binary can be run on this system.
+func newLocalTestBinary(binary LocalBinary) (*TestBinary, error) {
+	path, err := filepath.Abs(binary.Path)
+	if err != nil {
+		return nil, err
+	}
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("local extension binary: %w", err)
+	}
+	if !fileInfo.Mode().IsRegular() || fileInfo.Mode().Perm()&0111 == 0 {
+		return nil, fmt.Errorf("local extension binary %s is not an executable file", path)
+	}
+	if err := checkCompatibleArchitecture(path); err != nil {
+		return nil, fmt.Errorf("local extension binary %s: %w", path, err)
+	}
+	return &TestBinary{
+		imageTag:   localImageTag,
+		binaryPath: path,
+		component:  binary.Component,
+	}, nil
+}
+
+// checkComponent returns an error when a local binary does not report the component it was configured with.
+func (b *TestBinary) checkComponent(info *ExtensionInfo) error {
+	if b.component == nil || *b.component == info.Component {
+		return nil
+	}
+	return fmt.Errorf("local extension binary %s reports component %s, expected %s",
+		b.binaryPath, componentID(&info.Component), componentID(b.component))
+}
+
+// newLocalTestBinaries returns the binaries set by UseLocalBinaries.
+func newLocalTestBinaries() (TestBinaries, error) {
+	var binaries TestBinaries
+	for _, binary := range localBinaries {
+		testBinary, err := newLocalTestBinary(binary)
+		if err != nil {
+			return nil, err
+		}
+		binaries = append(binaries, testBinary)
+	}
+	return binaries, nil
+}
+
+// withLocalBinaries adds the local binaries to the binaries extracted from the release payload.  A payload binary
+// reporting the same component as a local binary is replaced by it.
+func withLocalBinaries(ctx context.Context, payloadBinaries, localBinaries TestBinaries, parallelism int) (TestBinaries, error) {
+	if len(localBinaries) == 0 {
+		return payloadBinaries, nil
+	}
+
+	if _, err := localBinaries.Info(ctx, parallelism); err != nil {
+		return nil, err
+	}
+	localComponents := map[Component]string{}
+	for _, binary := range localBinaries {
+		if other, ok := localComponents[binary.info.Component]; ok {
+			return nil, fmt.Errorf("local extension binaries %s and %s both report component %s",
+				other, binary.binaryPath, componentID(&binary.info.Component))
+		}
+		localComponents[binary.info.Component] = binary.binaryPath
+	}
+
+	// a payload binary whose info cannot be fetched is kept, so the error is reported with the info of every binary
+	_, _ = payloadBinaries.Info(ctx, parallelism)
+	binaries := append(TestBinaries{}, localBinaries...)
+	for _, binary := range payloadBinaries {
+		if binary.info != nil {
+			if path, ok := localComponents[binary.info.Component]; ok {
+				logrus.Infof("Using local extension binary %s instead of %s from the release payload for %s",
+					path, binary.info.Source.SourceBinary, componentID(&binary.info.Component))
+				continue
+			}
+		}
+		binaries = append(binaries, binary)
+	}
+	return binaries, nil
+}
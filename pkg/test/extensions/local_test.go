@@ -0,0 +1,95 @@
+package extensions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLocalBinaryConfig(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "extensions.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+binaries:
+- path: bin/example-tests-ext
+  component:
+    product: openshift
+    type: payload
+    name: example
+- path: /usr/local/bin/other-tests-ext
+`), 0644))
+
+	config, err := ReadLocalBinaryConfig(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []LocalBinary{
+		{
+			Path:      filepath.Join(dir, "bin", "example-tests-ext"),
+			Component: &Component{Product: "openshift", Kind: "payload", Name: "example"},
+		},
+		{Path: "/usr/local/bin/other-tests-ext"},
+	}, config.Binaries)
+
+	require.NoError(t, os.WriteFile(filename, []byte("binaries:\n- binary: example-tests-ext\n"), 0644))
+	_, err = ReadLocalBinaryConfig(filename)
+	assert.ErrorContains(t, err, `unknown field "binary"`)
+}
+
+func TestNewLocalTestBinary(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	binary, err := newLocalTestBinary(LocalBinary{Path: executable})
+	require.NoError(t, err)
+	assert.Equal(t, localImageTag, binary.imageTag)
+
+	script := fakeExtensionBinary(t, "exit 0").binaryPath
+	_, err = newLocalTestBinary(LocalBinary{Path: script})
+	assert.ErrorContains(t, err, "failed to parse ELF file")
+
+	notExecutable := filepath.Join(t.TempDir(), "example-tests-ext")
+	require.NoError(t, os.WriteFile(notExecutable, nil, 0644))
+	_, err = newLocalTestBinary(LocalBinary{Path: notExecutable})
+	assert.EqualError(t, err, "local extension binary "+notExecutable+" is not an executable file")
+
+	_, err = newLocalTestBinary(LocalBinary{Path: filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorContains(t, err, "no such file or directory")
+}
+
+func TestCheckComponent(t *testing.T) {
+	example := Component{Product: "openshift", Kind: "payload", Name: "example"}
+	info := &ExtensionInfo{Component: Component{Product: "openshift", Kind: "payload", Name: "other"}}
+
+	assert.NoError(t, (&TestBinary{}).checkComponent(info))
+	assert.NoError(t, (&TestBinary{component: &info.Component}).checkComponent(info))
+	assert.EqualError(t, (&TestBinary{binaryPath: "/bin/example-tests-ext", component: &example}).checkComponent(info),
+		"local extension binary /bin/example-tests-ext reports component openshift:payload:other, expected openshift:payload:example")
+}
+
+func TestWithLocalBinaries(t *testing.T) {
+	binary := func(path, name string) *TestBinary {
+		return &TestBinary{binaryPath: path, info: &ExtensionInfo{
+			APIVersion: CurrentAPIVersion,
+			Component:  Component{Product: "openshift", Kind: "payload", Name: name},
+		}}
+	}
+	payload := TestBinaries{binary("/payload/hyperkube", "hyperkube"), binary("/payload/example", "example")}
+
+	binaries, err := withLocalBinaries(context.Background(), payload, nil, 1)
+	require.NoError(t, err)
+	assert.Equal(t, payload, binaries)
+
+	local := TestBinaries{binary("/local/example", "example"), binary("/local/new", "new")}
+	binaries, err = withLocalBinaries(context.Background(), payload, local, 1)
+	require.NoError(t, err)
+	var paths []string
+	for _, b := range binaries {
+		paths = append(paths, b.binaryPath)
+	}
+	assert.Equal(t, []string{"/local/example", "/local/new", "/payload/hyperkube"}, paths)
+
+	_, err = withLocalBinaries(context.Background(), payload, TestBinaries{binary("/local/a", "example"), binary("/local/b", "example")}, 1)
+	assert.EqualError(t, err, "local extension binaries /local/a and /local/b both report component openshift:payload:example")
+}
@@ -1,11 +1,14 @@
 package extensions
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	imagev1 "github.com/openshift/api/image/v1"
@@ -19,31 +22,32 @@ import (
 // default, it uses a cache directory for extracted binaries assuming they'll be reused,
 // especially when developing locally. Set OPENSHIFT_TESTS_DISABLE_CACHE to any non-empty
 // value to use a temporary directory instead that will be removed at end of execution. When
-// using caching, files older than 7 days will be removed.
+// using caching, binaries are keyed by the digest of their image and their path, verified against
+// their checksum before use, and removed once unused for 7 days. Concurrent openshift-tests
+// processes share the cache through file locks.
 type ExternalBinaryProvider struct {
 	oc                   *util.CLI
+	cacheDir             string
 	binPath              string
 	tmpDir               string
 	registryAuthFilePath string
 	imageStream          *imagev1.ImageStream
+
+	// locks are held on the cache entries in use until cleanup
+	locks   []*cacheLock
+	locksMu sync.Mutex
 }
 
-func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*ExternalBinaryProvider,
+func NewExternalBinaryProvider(ctx context.Context, releaseImage, registryAuthfilePath string) (*ExternalBinaryProvider,
 	error) {
 	oc := util.NewCLIWithoutNamespace("default")
 
 	// Use a fixed cache or tmp directory for storing binaries
 	tmpDir := ""
-	binDir := pullSpecToDirName(releaseImage)
+	cacheDir := CacheDir()
 	if len(os.Getenv("OPENSHIFT_TESTS_DISABLE_CACHE")) == 0 {
-		// Determine cache path
-		cacheBase := os.Getenv("XDG_CACHE_HOME")
-		if cacheBase == "" {
-			cacheBase = path.Join(os.Getenv("HOME"), ".cache", "openshift-tests")
-		}
-		cleanOldCacheFiles(cacheBase)
-		binDir = path.Join(cacheBase, binDir)
-		logrus.WithField("cache_dir", cacheBase).Infof("External binary cache is enabled")
+		logrus.WithField("cache_dir", cacheDir).Infof("External binary cache is enabled")
+		cleanOldCacheFiles(cacheDir)
 	} else {
 		logrus.Infof("External binary cache is disabled, using a temp directory instead")
 		var err error
@@ -51,29 +55,54 @@ func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*Exte
 		if err != nil {
 			return nil, errors.Wrap(err, "couldn't create temp directory")
 		}
-		binDir = path.Join(tmpDir, binDir)
+		cacheDir = tmpDir
 	}
-	logrus.Infof("Using path for binaries %s", binDir)
+	binDir := path.Join(cacheDir, pullSpecToDirName(releaseImage))
+	logrus.Infof("Using path for binaries %s", cacheDir)
 
-	if err := createBinPath(binDir); err != nil {
-		return nil, errors.WithMessagef(err, "error creating cache path %s", binDir)
+	if err := createBinPath(path.Join(cacheDir, binaryCacheDirName)); err != nil {
+		return nil, errors.WithMessagef(err, "error creating cache path %s", cacheDir)
 	}
 
-	releasePayloadImageStream, releaseImage, err := extractReleaseImageStream(binDir, releaseImage, registryAuthfilePath)
-	if err != nil {
-		return nil, errors.WithMessage(err, "couldn't extract release payload image stream")
-	}
-
-	return &ExternalBinaryProvider{
+	provider := &ExternalBinaryProvider{
 		registryAuthFilePath: registryAuthfilePath,
 		oc:                   oc,
-		imageStream:          releasePayloadImageStream,
+		cacheDir:             cacheDir,
 		binPath:              binDir,
 		tmpDir:               tmpDir,
-	}, nil
+	}
+
+	lock, err := lockCacheEntry(ctx, binDir, syscall.LOCK_SH)
+	if err != nil {
+		provider.Cleanup()
+		return nil, errors.WithMessagef(err, "error locking cache path %s", binDir)
+	}
+	provider.keepLocked(lock)
+	releasePayloadImageStream, releaseImage, err := extractReleaseImageStream(ctx, binDir, releaseImage, registryAuthfilePath)
+	if err != nil {
+		provider.Cleanup()
+		return nil, errors.WithMessage(err, "couldn't extract release payload image stream")
+	}
+	provider.imageStream = releasePayloadImageStream
+
+	return provider, nil
+}
+
+// keepLocked holds the lock until cleanup, so the entry is not pruned while in use.
+func (provider *ExternalBinaryProvider) keepLocked(lock *cacheLock) {
+	provider.locksMu.Lock()
+	defer provider.locksMu.Unlock()
+	provider.locks = append(provider.locks, lock)
 }
 
 func (provider *ExternalBinaryProvider) Cleanup() {
+	provider.locksMu.Lock()
+	for _, lock := range provider.locks {
+		lock.Unlock()
+	}
+	provider.locks = nil
+	provider.locksMu.Unlock()
+
 	if provider.tmpDir != "" {
 		if err := os.RemoveAll(provider.tmpDir); err != nil {
 			logrus.Errorf("Failed to remove tmpDir %s: %v", provider.tmpDir, err)
@@ -93,7 +122,7 @@ func (provider *ExternalBinaryProvider) Cleanup() {
 // Note: When developing openshift-tests on a non-Linux non-AMD64 computer (i.e. on Apple Silicon), external
 // binaries won't work.  You would need to run it in a Linux environment (VM or container), and even then
 // override the payload selection with an aarch64 payload unless x86 emulation is enabled.
-func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(tag, binary string) (*TestBinary, error) {
+func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(ctx context.Context, tag, binary string) (*TestBinary, error) {
 	if provider.binPath == "" {
 		return nil, fmt.Errorf("extraction path is not set, cleanup was already run")
 	}
@@ -125,26 +154,43 @@ func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(tag, binar
 		return nil, fmt.Errorf("%s not found", tag)
 	}
 
-	// Define the path for the binary
-	binPath := filepath.Join(provider.binPath, strings.TrimSuffix(filepath.Base(binary), ".gz"))
+	// Every process using the binary shares the lock of its entry, and only one that finds the entry incomplete or
+	// invalid extracts it, while the others wait for it.
+	entryDir := filepath.Join(provider.cacheDir, binaryCacheDirName, binaryCacheKey(image, binary))
+	lock, err := lockCacheEntry(ctx, entryDir, syscall.LOCK_SH)
+	if err != nil {
+		return nil, fmt.Errorf("failed locking cache path %s: %w", entryDir, err)
+	}
+	provider.keepLocked(lock)
 
-	// Check if the binary already exists in the path
-	if _, err := os.Stat(binPath); err == nil {
-		logrus.Infof("Using existing binary %s for tag %s", binPath, tag)
-		return &TestBinary{
-			imageTag:   tag,
-			binaryPath: binPath,
-		}, nil
+	cached, extracted, err := cachedOrExtract(ctx, entryDir, func() (*CachedBinary, error) {
+		return provider.extractBinary(entryDir, tag, image, binary)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !extracted {
+		logrus.Infof("Using existing binary %s for tag %s", cached.BinaryPath(), tag)
 	}
+	return &TestBinary{
+		imageTag:   tag,
+		binaryPath: cached.BinaryPath(),
+	}, nil
+}
 
-	// Start the extraction process.
+// extractBinary extracts the binary from the image into the cache entry in entryDir, replacing whatever the entry
+// held.  The caller must hold the extraction lock of the entry.
+func (provider *ExternalBinaryProvider) extractBinary(entryDir, tag, image, binary string) (*CachedBinary, error) {
+	if err := clearCacheEntry(entryDir); err != nil {
+		return nil, fmt.Errorf("failed clearing cache path %s: %w", entryDir, err)
+	}
 	startTime := time.Now()
-	if err := runImageExtract(image, binary, provider.binPath, provider.registryAuthFilePath); err != nil {
+	if err := runImageExtract(image, binary, entryDir, provider.registryAuthFilePath); err != nil {
 		return nil, fmt.Errorf("failed extracting %q from %q: %w", binary, image, err)
 	}
 	extractDuration := time.Since(startTime)
 
-	extractedBinary := filepath.Join(provider.binPath, filepath.Base(binary))
+	extractedBinary := filepath.Join(entryDir, filepath.Base(binary))
 
 	// Support gzipped external binaries (handle decompression).
 	extractedBinary, err := ungzipFile(extractedBinary)
@@ -168,37 +214,46 @@ func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(tag, binar
 		return nil, errors.WithMessage(err, "error checking binary architecture compatability")
 	}
 
+	// Record the checksum, which completes the cache entry
+	cached, err := writeCachedBinary(entryDir, image, binary, extractedBinary)
+	if err != nil {
+		return nil, fmt.Errorf("failed caching the extracted binary %q: %w", extractedBinary, err)
+	}
+
 	logrus.Infof("Extracted %s for tag %s from %s (disk size %v, extraction duration %v)",
 		binary, tag, image, fileInfo.Size(), extractDuration)
 
-	return &TestBinary{
-		binaryPath: extractedBinary,
-	}, nil
+	return cached, nil
 }
 
-func cleanOldCacheFiles(dir string) {
-	maxAge := 24 * 7 * time.Hour // 7 days
-	logrus.Infof("Cleaning up older cached data...")
+// clearCacheEntry removes everything but the locks from a cache entry.
+func clearCacheEntry(dir string) error {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		logrus.Warningf("Failed to read cache directory '%s': %v", dir, err)
-		return
+		return err
 	}
-
-	start := time.Now()
 	for _, entry := range entries {
-		info, err := entry.Info()
-		if err != nil || start.Sub(info.ModTime()) < maxAge {
+		if entry.Name() == cacheLockFile || entry.Name() == cacheExtractLockFile {
 			continue
 		}
-
-		tgtPath := filepath.Join(dir, entry.Name())
-		if err := os.RemoveAll(tgtPath); err != nil {
-			logrus.Errorf("Failed to remove cache file '%s': %v", tgtPath, err)
-		} else {
-			logrus.Infof("Removed old cache file '%s'", tgtPath)
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+func cleanOldCacheFiles(dir string) {
+	logrus.Infof("Cleaning up older cached data...")
+	start := time.Now()
+	removed, err := PruneCache(dir, DefaultCacheMaxAge)
+	for _, entry := range removed {
+		logrus.Infof("Removed old cache entry '%s'", entry)
+	}
+	if err != nil {
+		logrus.Warningf("Failed to clean up cache directory '%s': %v", dir, err)
+		return
+	}
 	logrus.Infof("Cleaned up old cached data in %v", time.Since(start))
 }
 
@@ -19,6 +19,10 @@ type ExtensionInfo struct {
 	// Suites that the extension wants to advertise/participate in.
 	Suites []Suite `json:"suites"`
 
+	// Capabilities are the optional features of the API the extension supports.  When nil, the
+	// extension predates declaring them, and supports the features of the first API version.
+	Capabilities []Capability `json:"capabilities,omitempty"`
+
 	// -- origin specific info --
 	ExtensionArtifactDir string `json:"extension_artifact_dir"`
 }
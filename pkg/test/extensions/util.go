@@ -16,11 +16,13 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	imagev1 "github.com/openshift/api/image/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/test/extended/util"
 )
 
@@ -170,6 +172,14 @@ func determineReleasePayloadImage() (string, error) {
 		}
 	}
 
+	if replayed := clusterdiscovery.ReplayedClusterState(); len(releaseImage) == 0 && replayed != nil && replayed.Version != nil {
+		releaseImage = replayed.Version.Status.Desired.Image
+		if len(releaseImage) == 0 {
+			return "", fmt.Errorf("cannot determine release image from the ClusterVersion of the cluster state")
+		}
+		logrus.WithField("release_image", releaseImage).Infof("Using the release image of the cluster state")
+	}
+
 	if len(releaseImage) == 0 {
 		// Note that MicroShift does not have this resource. The test driver must use ENV vars.
 		oc := util.NewCLIWithoutNamespace("default")
@@ -196,15 +206,26 @@ func createBinPath(path string) error {
 		return fmt.Errorf("failed to create cache directory %s: %w", path, err)
 	}
 
-	// Create a simple shell script to test executability.
-	testFile := filepath.Join(path, "cache_test.sh")
+	// Create a simple shell script to test executability, named uniquely since the cache is shared by processes.
 	scriptContent := "#!/bin/sh\necho 'Executable test passed'"
+	file, err := os.CreateTemp(path, "cache_test_*.sh")
+	if err != nil {
+		return fmt.Errorf("failed to write test file in cache path %s: %w", path, err)
+	}
+	testFile := file.Name()
+	defer os.Remove(testFile)
 
 	// Write the script to the cache directory.
-	if err := os.WriteFile(testFile, []byte(scriptContent), 0755); err != nil {
+	_, err = file.WriteString(scriptContent)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(testFile, 0755)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to write test file in cache path %s: %w", path, err)
 	}
-	defer os.Remove(testFile)
 
 	// Attempt to execute the test script.
 	cmd := exec.Command(testFile)
@@ -223,12 +244,12 @@ func createBinPath(path string) error {
 
 // extractReleaseImageStream extracts image references from the given releaseImage and returns
 // an ImageStream object with tags associated with image-references from that payload.
-func extractReleaseImageStream(extractPath, releaseImage string,
+func extractReleaseImageStream(ctx context.Context, extractPath, releaseImage string,
 	registryAuthFilePath string) (*imagev1.ImageStream, string, error) {
 
 	if _, err := os.Stat(path.Join(extractPath, "image-references")); err != nil {
-		if err := runImageExtract(releaseImage, "/release-manifests/image-references", extractPath, registryAuthFilePath); err != nil {
-			return nil, "", fmt.Errorf("failed extracting image-references from %q: %w", releaseImage, err)
+		if err := extractImageReferences(ctx, extractPath, releaseImage, registryAuthFilePath); err != nil {
+			return nil, "", err
 		}
 	}
 	jsonFile, err := os.Open(filepath.Join(extractPath, "image-references"))
@@ -260,3 +281,27 @@ func extractReleaseImageStream(extractPath, releaseImage string,
 
 	return is, releaseImage, nil
 }
+
+// extractImageReferences extracts image-references into extractPath, whose cache entry lock the caller must hold,
+// unless another process extracted it first.  It is extracted into a temporary directory and moved into place, so
+// other processes never read a partial file.
+func extractImageReferences(ctx context.Context, extractPath, releaseImage, registryAuthFilePath string) error {
+	extraction, err := lockCacheExtraction(ctx, extractPath, syscall.LOCK_EX)
+	if err != nil {
+		return fmt.Errorf("failed waiting to extract image-references from %q: %w", releaseImage, err)
+	}
+	defer extraction.Unlock()
+	if _, err := os.Stat(path.Join(extractPath, "image-references")); err == nil {
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp(extractPath, ".image-references-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := runImageExtract(releaseImage, "/release-manifests/image-references", tmpDir, registryAuthFilePath); err != nil {
+		return fmt.Errorf("failed extracting image-references from %q: %w", releaseImage, err)
+	}
+	return os.Rename(filepath.Join(tmpDir, "image-references"), filepath.Join(extractPath, "image-references"))
+}
@@ -21,10 +21,13 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
+	"github.com/openshift/origin/pkg/clioptions/historicaldataoptions"
 	"github.com/openshift/origin/pkg/defaultmonitortests"
 	"github.com/openshift/origin/pkg/monitor"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/riskanalysis"
 	"github.com/openshift/origin/pkg/test/extensions"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
@@ -68,17 +71,37 @@ type GinkgoRunSuiteOptions struct {
 
 	DryRun        bool
 	PrintCommands bool
+	// Output is the format of the tests printed by a dry run, json or yaml, instead of their names.
+	Output string
+
+	// ShardIndex and ShardCount run only the tests assigned to one of several runs of the suite.
+	ShardIndex int
+	ShardCount int
+	// ShardDurationFiles are junit files of previous runs, used to balance the duration of the shards.
+	ShardDurationFiles []string
 	genericclioptions.IOStreams
 
 	StartTime time.Time
 
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+
+	DisruptionBackendsFile string
+	FailOnRemovedAPIUsage  bool
+	HistoricalDataFiles    []string
+	HistoricalDataFallback []string
+
+	DisruptionEvaluator        string
+	DisruptionFlakeProbability float64
+	DisruptionFailProbability  float64
 }
 
 func NewGinkgoRunSuiteOptions(streams genericclioptions.IOStreams) *GinkgoRunSuiteOptions {
 	return &GinkgoRunSuiteOptions{
-		IOStreams: streams,
+		DisruptionEvaluator:        string(disruptionlibrary.P99Evaluator),
+		DisruptionFlakeProbability: historicaldata.DefaultAnomalyThresholds.FlakeProbability,
+		DisruptionFailProbability:  historicaldata.DefaultAnomalyThresholds.FailProbability,
+		IOStreams:                  streams,
 	}
 }
 
@@ -87,7 +110,11 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 	monitorNames := defaultmonitortests.ListAllMonitorTests()
 
 	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the tests to run without executing them.")
+	flags.StringVar(&o.Output, "output", o.Output, "With --dry-run, print the tests and their metadata as json or yaml instead of their names.")
 	flags.BoolVar(&o.PrintCommands, "print-commands", o.PrintCommands, "Print the sub-commands that would be executed instead.")
+	flags.IntVar(&o.ShardIndex, "shard-index", o.ShardIndex, "With --shard-count, the zero-based index of the shard of the suite to run.")
+	flags.IntVar(&o.ShardCount, "shard-count", o.ShardCount, "Split the tests of the suite across this many runs, which each select the same tests, and run only the tests of --shard-index.  Combine the results of every shard with merge-results.")
+	flags.StringSliceVar(&o.ShardDurationFiles, "shard-durations-file", o.ShardDurationFiles, "Junit files of previous runs of the suite, used to balance the duration of the shards.")
 	flags.StringVar(&o.ClusterStabilityDuringTest, "cluster-stability", o.ClusterStabilityDuringTest, "cluster stability during test, usually dependent on the job: Stable or Disruptive. Empty default will be treated as Stable.")
 	flags.StringVar(&o.JUnitDir, "junit-dir", o.JUnitDir, "The directory to write test reports to.")
 	flags.IntVar(&o.Count, "count", o.Count, "Run each test a specified number of times. Defaults to 1 or the suite's preferred value. -1 will run forever.")
@@ -98,6 +125,13 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.StringSliceVar(&o.ExactMonitorTests, "monitor", o.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&o.DisableMonitorTests, "disable-monitor", o.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	flags.StringVar(&o.DisruptionBackendsFile, "disruption-backends", o.DisruptionBackendsFile, "A yaml file describing additional endpoints to monitor for disruption.")
+	flags.BoolVar(&o.FailOnRemovedAPIUsage, "fail-on-removed-api-usage", o.FailOnRemovedAPIUsage, "Fail, instead of flake, when platform components use APIs removed in the next kube release.")
+	flags.StringSliceVar(&o.HistoricalDataFiles, "historical-data-file", o.HistoricalDataFiles, "Disruption, alert, etcd metric, or machine config rollout historical data to use instead of the data embedded in this binary.  Usually created by the historical-data command.")
+	flags.StringSliceVar(&o.HistoricalDataFallback, "historical-data-fallback", o.HistoricalDataFallback, historicaldataoptions.FallbackFlagUsage())
+	flags.StringVar(&o.DisruptionEvaluator, "disruption-evaluator", o.DisruptionEvaluator, "How disruption is compared to historical data: p99 fails above the historical P99 plus grace, anomaly scores against the whole historical distribution.")
+	flags.Float64Var(&o.DisruptionFlakeProbability, "disruption-flake-probability", o.DisruptionFlakeProbability, "With --disruption-evaluator=anomaly, flake when fewer than this fraction of historical runs saw as much disruption.")
+	flags.Float64Var(&o.DisruptionFailProbability, "disruption-fail-probability", o.DisruptionFailProbability, "With --disruption-evaluator=anomaly, fail when fewer than this fraction of historical runs saw as much disruption.")
 }
 
 func (o *GinkgoRunSuiteOptions) Validate() error {
@@ -131,6 +165,31 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	upgrade bool) error {
 	ctx := context.Background()
 
+	if err := historicaldataoptions.OverrideHistoricalData(o.HistoricalDataFiles); err != nil {
+		return err
+	}
+	if err := historicaldataoptions.SetFallbackStrategies(o.HistoricalDataFallback); err != nil {
+		return err
+	}
+	if err := historicaldataoptions.SetDisruptionEvaluator(o.DisruptionEvaluator, o.DisruptionFlakeProbability, o.DisruptionFailProbability); err != nil {
+		return err
+	}
+
+	switch {
+	case len(o.Output) > 0 && !o.DryRun:
+		return fmt.Errorf("--output is only supported with --dry-run")
+	case o.Output != "" && o.Output != dryRunOutputJSON && o.Output != dryRunOutputYAML:
+		return fmt.Errorf("unknown --output, %q, expected %s or %s", o.Output, dryRunOutputJSON, dryRunOutputYAML)
+	case o.ShardCount < 0:
+		return fmt.Errorf("--shard-count must not be negative")
+	case o.ShardCount > 0 && (o.ShardIndex < 0 || o.ShardIndex >= o.ShardCount):
+		return fmt.Errorf("--shard-index must be at least 0 and less than --shard-count %d", o.ShardCount)
+	case o.ShardCount == 0 && o.ShardIndex != 0:
+		return fmt.Errorf("--shard-index requires --shard-count")
+	case o.ShardCount > 1 && len(o.JUnitDir) == 0:
+		return fmt.Errorf("--shard-count requires --junit-dir, where each shard writes the results merge-results combines")
+	}
+
 	tests, err := testsForSuite()
 	if err != nil {
 		return fmt.Errorf("failed reading origin test suites: %w", err)
@@ -157,13 +216,17 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		infoContext, infoContextCancel := context.WithTimeout(context.Background(), 30*time.Minute)
 		defer infoContextCancel()
 		extensionsInfo, err := externalBinaries.Info(infoContext, defaultBinaryParallelism)
+		// the reports explain incompatible extensions too, so they are written before failing
+		if reportErr := externalBinaries.WriteCompatibilityReports(); reportErr != nil {
+			logrus.WithError(reportErr).Warn("Unable to write the extension compatibility reports")
+		}
 		if err != nil {
 			return err
 		}
 		logrus.Infof("Discovered %d extensions", len(extensionsInfo))
 		for _, e := range extensionsInfo {
 			id := fmt.Sprintf("%s:%s:%s", e.Component.Product, e.Component.Kind, e.Component.Name)
-			logrus.Infof("Extension %s found in %s:%s using API version %s", id, e.Source.SourceImage, e.Source.SourceBinary, e.APIVersion)
+			logrus.Infof("Extension %s found in %s:%s using API version %s with capabilities %v", id, e.Source.SourceImage, e.Source.SourceBinary, e.APIVersion, e.Capabilities)
 		}
 
 		// List tests from all available binaries and convert them to origin's testCase format
@@ -214,6 +277,17 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	logrus.Infof("Found %d filtered tests", len(tests))
 
+	var selection string
+	if o.ShardCount > 1 {
+		durations, err := readTestDurations(o.ShardDurationFiles)
+		if err != nil {
+			return fmt.Errorf("could not read --shard-durations-file: %w", err)
+		}
+		selection = shardSelection(tests, durations)
+		tests = shardTests(tests, o.ShardIndex, o.ShardCount, durations)
+		logrus.Infof("Running %d tests in shard %d of %d", len(tests), o.ShardIndex, o.ShardCount)
+	}
+
 	count := o.Count
 	if count == 0 {
 		count = suite.Count
@@ -239,6 +313,9 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		return nil
 	}
 	if o.DryRun {
+		if len(o.Output) > 0 {
+			return writeDryRunTests(ctx, o.Out, o.Output, tests, timeout)
+		}
 		for _, test := range sortedTests(tests) {
 			fmt.Fprintf(o.Out, "%q\n", test.name)
 		}
@@ -265,6 +342,26 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 				return fmt.Errorf("could not create --junit-dir: %v", err)
 			}
 		}
+
+		// the tests of this run can be selected again without the cluster with --cluster-state-file
+		if err := writeClusterState(restConfig, o.JUnitDir, start); err != nil {
+			logrus.WithError(err).Warn("Unable to write the cluster state")
+		}
+
+		if o.ShardCount > 1 {
+			shardInfo := &ShardInfo{
+				Suite:                suite.Name,
+				Index:                o.ShardIndex,
+				Count:                o.ShardCount,
+				MaximumAllowedFlakes: suite.MaximumAllowedFlakes,
+				Start:                start,
+				Selection:            selection,
+				Tests:                testNames(sortedTests(tests)),
+			}
+			if err := writeShardInfo(shardInfo, o.JUnitDir); err != nil {
+				return fmt.Errorf("could not write the shard info: %w", err)
+			}
+		}
 	}
 
 	parallelism := o.Parallelism
@@ -331,25 +428,15 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	testOutputLock := &sync.Mutex{}
 	testOutputConfig := newTestOutputConfig(testOutputLock, o.Out, monitorEventRecorder, includeSuccess)
 
-	early, notEarly := splitTests(tests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Early]")
-	})
+	early, notEarly := splitTests(tests, isEarlyTest)
 
-	late, primaryTests := splitTests(notEarly, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Late]")
-	})
+	late, primaryTests := splitTests(notEarly, isLateTest)
 
-	kubeTests, openshiftTests := splitTests(primaryTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[Suite:k8s]")
-	})
+	kubeTests, openshiftTests := splitTests(primaryTests, isKubeTest)
 
-	storageTests, kubeTests := splitTests(kubeTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-storage]")
-	})
+	storageTests, kubeTests := splitTests(kubeTests, isStorageTest)
 
-	mustGatherTests, openshiftTests := splitTests(openshiftTests, func(t *testCase) bool {
-		return strings.Contains(t.name, "[sig-cli] oc adm must-gather")
-	})
+	mustGatherTests, openshiftTests := splitTests(openshiftTests, isMustGatherTest)
 
 	// If user specifies a count, duplicate the kube and openshift tests that many times.
 	expectedTestCount := len(early) + len(late)
@@ -617,13 +704,6 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 }
 
 func writeExtensionTestResults(tests []*testCase, dir, filePrefix, fileSuffix string, out io.Writer) error {
-	// Ensure the directory exists
-	err := os.MkdirAll(dir, 0755)
-	if err != nil {
-		fmt.Fprintf(out, "Failed to create directory %s: %v\n", dir, err)
-		return err
-	}
-
 	// Collect results into a slice
 	var results extensions.ExtensionTestResults
 	for _, test := range tests {
@@ -631,6 +711,16 @@ func writeExtensionTestResults(tests []*testCase, dir, filePrefix, fileSuffix st
 			results = append(results, test.extensionTestResult)
 		}
 	}
+	return writeExtensionTestResultList(results, dir, filePrefix, fileSuffix, out)
+}
+
+func writeExtensionTestResultList(results extensions.ExtensionTestResults, dir, filePrefix, fileSuffix string, out io.Writer) error {
+	// Ensure the directory exists
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to create directory %s: %v\n", dir, err)
+		return err
+	}
 
 	// Marshal results to JSON
 	data, err := json.MarshalIndent(results, "", "  ")
@@ -659,6 +749,24 @@ func writeExtensionTestResults(tests []*testCase, dir, filePrefix, fileSuffix st
 }
 
 func (o *GinkgoRunSuiteOptions) filterOutRebaseTests(restConfig *rest.Config, tests []*testCase) ([]*testCase, error) {
+	exclusions, err := rebaseExclusions(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*testCase, 0, len(tests))
+	for _, test := range tests {
+		if excluded, _ := rebaseExclusion(exclusions, test.name); excluded {
+			fmt.Fprintf(o.Out, "Skipping %q due to rebase in-progress\n", test.name)
+			continue
+		}
+		matches = append(matches, test)
+	}
+	return matches, nil
+}
+
+// rebaseExclusions returns the tests that are skipped while the cluster runs a newer kube than the tests expect.
+func rebaseExclusions(restConfig *rest.Config) ([]string, error) {
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
 	if err != nil {
 		return nil, err
@@ -670,39 +778,51 @@ func (o *GinkgoRunSuiteOptions) filterOutRebaseTests(restConfig *rest.Config, te
 	// TODO: this version along with below exclusions lists needs to be updated
 	// for the rebase in-progress.
 	if !strings.HasPrefix(serverVersion.Minor, "31") {
-		return tests, nil
+		return nil, nil
 	}
 
 	// Below list should only be filled in when we're trying to land k8s rebase.
 	// Don't pile them up!
-	exclusions := []string{
+	return []string{
 		// affected by the available controller split https://github.com/kubernetes/kubernetes/pull/126149
 		`[sig-api-machinery] health handlers should contain necessary checks`,
+	}, nil
+}
+
+func rebaseExclusion(exclusions []string, name string) (bool, string) {
+	for _, excl := range exclusions {
+		if strings.Contains(name, excl) {
+			return true, fmt.Sprintf("rebase in progress, excluded by %q", excl)
+		}
 	}
+	return false, ""
+}
 
-	matches := make([]*testCase, 0, len(tests))
-outerLoop:
-	for _, test := range tests {
-		for _, excl := range exclusions {
-			if strings.Contains(test.name, excl) {
-				fmt.Fprintf(o.Out, "Skipping %q due to rebase in-progress\n", test.name)
-				continue outerLoop
-			}
+// writeClusterState writes the ClusterState the tests were selected for next to the junit.
+func writeClusterState(restConfig *rest.Config, junitDir string, start time.Time) error {
+	clusterState := clusterdiscovery.ReplayedClusterState()
+	if clusterState == nil {
+		var err error
+		if clusterState, err = clusterdiscovery.DiscoverClusterState(restConfig); err != nil {
+			return err
 		}
-		matches = append(matches, test)
 	}
-	return matches, nil
+	timeSuffix := fmt.Sprintf("_%s", start.UTC().Format("20060102-150405"))
+	return clusterdiscovery.WriteClusterState(filepath.Join(junitDir, fmt.Sprintf("cluster-state%s.json", timeSuffix)), clusterState)
 }
 
 func determineEnvironmentFlags(upgrade bool, dryRun bool) (extensions.EnvironmentFlags, error) {
-	clientConfig, err := e2e.LoadConfig(true)
-	if err != nil {
-		logrus.WithError(err).Error("error calling e2e.LoadConfig")
-		return nil, err
-	}
-	clusterState, err := clusterdiscovery.DiscoverClusterState(clientConfig)
-	if err != nil {
-		logrus.WithError(err).Warn("error Discovering Cluster State, flags requiring it will not be present")
+	clusterState := clusterdiscovery.ReplayedClusterState()
+	if clusterState == nil {
+		clientConfig, err := e2e.LoadConfig(true)
+		if err != nil {
+			logrus.WithError(err).Error("error calling e2e.LoadConfig")
+			return nil, err
+		}
+		clusterState, err = clusterdiscovery.DiscoverClusterState(clientConfig)
+		if err != nil {
+			logrus.WithError(err).Warn("error Discovering Cluster State, flags requiring it will not be present")
+		}
 	}
 	provider := os.Getenv("TEST_PROVIDER")
 	if clusterState == nil { // If we know we cannot discover the clusterState, the provider must be set to "none" in order for the config to be loaded without error
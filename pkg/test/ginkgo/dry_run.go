@@ -0,0 +1,146 @@
+package ginkgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// The formats a dry run can print its tests in.
+const (
+	dryRunOutputJSON = "json"
+	dryRunOutputYAML = "yaml"
+)
+
+// TestBucket is the group of tests Run executes a test with.  Early and late tests run before and after every
+// other bucket.
+type TestBucket string
+
+const (
+	TestBucketEarly      TestBucket = "early"
+	TestBucketLate       TestBucket = "late"
+	TestBucketKube       TestBucket = "kube"
+	TestBucketStorage    TestBucket = "storage"
+	TestBucketOpenShift  TestBucket = "openshift"
+	TestBucketMustGather TestBucket = "must-gather"
+)
+
+// testBucket returns the bucket Run places the test in, splitting the tests in the same order Run does.
+func testBucket(test *testCase) TestBucket {
+	switch {
+	case isEarlyTest(test):
+		return TestBucketEarly
+	case isLateTest(test):
+		return TestBucketLate
+	case isKubeTest(test) && isStorageTest(test):
+		return TestBucketStorage
+	case isKubeTest(test):
+		return TestBucketKube
+	case isMustGatherTest(test):
+		return TestBucketMustGather
+	default:
+		return TestBucketOpenShift
+	}
+}
+
+// DryRunTest describes a test a suite would run, so tools sharding or reporting on the suite do not have to parse
+// test names.
+type DryRunTest struct {
+	Name string `json:"name"`
+	// OriginalName is the first name an extension knew the test as, if it was renamed.
+	OriginalName string `json:"originalName,omitempty"`
+
+	// Source is the extension that provides the test, or openshift-tests for its own tests.
+	Source string `json:"source"`
+	// SourceImage and SourceBinary are the payload image and the path in it of the extension binary.
+	SourceImage  string `json:"sourceImage,omitempty"`
+	SourceBinary string `json:"sourceBinary,omitempty"`
+
+	Labels    []string             `json:"labels,omitempty"`
+	Tags      map[string]string    `json:"tags,omitempty"`
+	Lifecycle extensions.Lifecycle `json:"lifecycle"`
+	Isolation extensions.Isolation `json:"isolation"`
+	// Timeout is how long the test may run before it is aborted.
+	Timeout string `json:"timeout"`
+
+	Early  bool       `json:"early"`
+	Late   bool       `json:"late"`
+	Serial bool       `json:"serial"`
+	Bucket TestBucket `json:"bucket"`
+}
+
+// newDryRunTest describes the test, which runs for at most the suite timeout unless it sets its own.
+func newDryRunTest(ctx context.Context, test *testCase, suiteTimeout time.Duration) DryRunTest {
+	timeout := suiteTimeout
+	if test.testTimeout != 0 {
+		timeout = test.testTimeout
+	}
+
+	ret := DryRunTest{
+		Name:      test.name,
+		Source:    originSource,
+		Lifecycle: extensions.LifecycleBlocking,
+		Timeout:   timeout.String(),
+		Early:     isEarlyTest(test),
+		Late:      isLateTest(test),
+		Serial:    isSerialTest(test),
+		Bucket:    testBucket(test),
+	}
+
+	if spec := test.extensionSpec; spec != nil {
+		ret.OriginalName = spec.OriginalName
+		ret.Source = spec.Source
+		ret.Tags = spec.Tags
+		ret.Isolation = spec.Resources.Isolation
+		if len(spec.Labels) > 0 {
+			ret.Labels = sets.List(spec.Labels)
+		}
+		// extensions that do not set a lifecycle are blocking
+		if len(spec.Lifecycle) > 0 {
+			ret.Lifecycle = spec.Lifecycle
+		}
+	}
+	if test.binary != nil {
+		// the info is cached from when the tests were listed
+		info, err := test.binary.Info(ctx)
+		if err != nil {
+			logrus.WithError(err).Warnf("unable to determine the source image of %q", test.name)
+		} else {
+			ret.SourceImage = info.Source.SourceImage
+			ret.SourceBinary = info.Source.SourceBinary
+		}
+	}
+	return ret
+}
+
+// writeDryRunTests writes the sorted tests with their metadata to out as json or yaml.
+func writeDryRunTests(ctx context.Context, out io.Writer, format string, tests []*testCase, suiteTimeout time.Duration) error {
+	dryRunTests := []DryRunTest{}
+	for _, test := range sortedTests(tests) {
+		dryRunTests = append(dryRunTests, newDryRunTest(ctx, test, suiteTimeout))
+	}
+
+	var content []byte
+	var err error
+	switch format {
+	case dryRunOutputJSON:
+		content, err = json.MarshalIndent(dryRunTests, "", "    ")
+		content = append(content, '\n')
+	case dryRunOutputYAML:
+		content, err = yaml.Marshal(dryRunTests)
+	default:
+		return fmt.Errorf("unknown dry run output %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
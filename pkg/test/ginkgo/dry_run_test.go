@@ -0,0 +1,101 @@
+package ginkgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+func TestTestBucket(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket TestBucket
+	}{
+		{name: "[sig-etcd] early test [Early] [Suite:openshift/conformance/parallel]", bucket: TestBucketEarly},
+		{name: "[sig-arch] late test [Late] [Suite:openshift/conformance/parallel]", bucket: TestBucketLate},
+		{name: "[sig-node] kube test [Suite:k8s]", bucket: TestBucketKube},
+		{name: "[sig-storage] kube storage test [Suite:k8s]", bucket: TestBucketStorage},
+		{name: "[sig-storage] openshift storage test [Suite:openshift/conformance/parallel]", bucket: TestBucketOpenShift},
+		{name: "[sig-cli] oc adm must-gather runs [Suite:openshift/conformance/parallel]", bucket: TestBucketMustGather},
+		{name: "[sig-cli] oc adm must-gather runs [Early] [Suite:openshift/conformance/parallel]", bucket: TestBucketEarly},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.bucket, testBucket(&testCase{name: test.name}))
+		})
+	}
+}
+
+func TestNewDryRunTest(t *testing.T) {
+	origin := &testCase{
+		name:        "[sig-apps] origin test [Serial] [Timeout:30m] [Suite:openshift/conformance/serial]",
+		testTimeout: 30 * time.Minute,
+	}
+	assert.Equal(t, DryRunTest{
+		Name:      origin.name,
+		Source:    originSource,
+		Lifecycle: extensions.LifecycleBlocking,
+		Timeout:   "30m0s",
+		Serial:    true,
+		Bucket:    TestBucketOpenShift,
+	}, newDryRunTest(context.Background(), origin, 15*time.Minute))
+
+	spec := &extensions.ExtensionTestSpec{
+		Name:         "[sig-storage] extension test [Suite:k8s]",
+		OriginalName: "[sig-storage] old extension test [Suite:k8s]",
+		Labels:       sets.New("Slow", "Disruptive"),
+		Tags:         map[string]string{"team": "storage"},
+		Resources:    extensions.Resources{Isolation: extensions.Isolation{Mode: "namespace", Conflict: []string{"volumes"}}},
+		Source:       "openshift:payload:hyperkube",
+		Lifecycle:    extensions.LifecycleInforming,
+	}
+	external := externalBinaryTestsToOriginTestCases(extensions.ExtensionTestSpecs{spec})[0]
+	assert.Equal(t, DryRunTest{
+		Name:         spec.Name,
+		OriginalName: spec.OriginalName,
+		Source:       "openshift:payload:hyperkube",
+		Labels:       []string{"Disruptive", "Slow"},
+		Tags:         map[string]string{"team": "storage"},
+		Lifecycle:    extensions.LifecycleInforming,
+		Isolation:    extensions.Isolation{Mode: "namespace", Conflict: []string{"volumes"}},
+		Timeout:      "15m0s",
+		Bucket:       TestBucketStorage,
+	}, newDryRunTest(context.Background(), external, 15*time.Minute))
+}
+
+func TestWriteDryRunTests(t *testing.T) {
+	tests := []*testCase{
+		{name: "[sig-node] b [Suite:k8s]"},
+		{name: "[sig-arch] a [Late] [Suite:openshift/conformance/parallel]"},
+	}
+
+	for _, format := range []string{dryRunOutputJSON, dryRunOutputYAML} {
+		t.Run(format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			require.NoError(t, writeDryRunTests(context.Background(), out, format, tests, time.Hour))
+
+			var written []DryRunTest
+			if format == dryRunOutputJSON {
+				require.NoError(t, json.Unmarshal(out.Bytes(), &written))
+			} else {
+				require.NoError(t, yaml.Unmarshal(out.Bytes(), &written))
+			}
+			require.Len(t, written, 2)
+			assert.Equal(t, tests[1].name, written[0].Name)
+			assert.Equal(t, TestBucketLate, written[0].Bucket)
+			assert.True(t, written[0].Late)
+			assert.Equal(t, TestBucketKube, written[1].Bucket)
+			assert.Equal(t, "1h0m0s", written[1].Timeout)
+		})
+	}
+
+	assert.Error(t, writeDryRunTests(context.Background(), &bytes.Buffer{}, "xml", tests, time.Hour))
+}
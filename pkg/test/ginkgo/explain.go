@@ -0,0 +1,177 @@
+package ginkgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+)
+
+// The stages of test selection that are not filters of the suite.
+const (
+	ExtensionEnvironmentStage = "extension environment"
+	SuiteDefinitionStage      = "suite definition"
+	RebaseExclusionsStage     = "rebase exclusions"
+)
+
+// originSource is the source of tests built into openshift-tests.
+const originSource = "openshift-tests"
+
+// TestSelectionStage is the result of a single stage of test selection for a test.
+type TestSelectionStage struct {
+	Stage  string `json:"stage"`
+	Passed bool   `json:"passed"`
+	// Reason is the rule or annotation that removed the test, empty when the stage passed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// TestSelection explains whether a test runs as part of a suite against the cluster.  Every stage is evaluated, even
+// after one has removed the test, so all the reasons a test does not run are reported at once.
+type TestSelection struct {
+	Name     string               `json:"name"`
+	Source   string               `json:"source"`
+	Included bool                 `json:"included"`
+	Stages   []TestSelectionStage `json:"stages"`
+}
+
+func (t *TestSelection) addStage(stage string, passed bool, reason string) {
+	if passed {
+		reason = ""
+	} else if len(reason) == 0 {
+		reason = fmt.Sprintf("removed by the %s", stage)
+	}
+	t.Stages = append(t.Stages, TestSelectionStage{Stage: stage, Passed: passed, Reason: reason})
+	t.Included = t.Included && passed
+}
+
+// explainDefinition returns whether the suite itself, before any required filters, selects the test.
+func (s *TestSuite) explainDefinition(name string) bool {
+	matches := s.Matches
+	if len(s.requiredFilters) > 0 {
+		matches = s.definitionMatches
+	}
+	return matches == nil || matches(name)
+}
+
+// ExplainTestSelection walks every test of openshift-tests and its extensions through the same stages of test
+// selection that Run applies for the suite.  definitionReason explains why the definition of the suite does not
+// select a test, like a [Disabled] annotation, and may return an empty string when there is no specific rule.
+func ExplainTestSelection(ctx context.Context, suite *TestSuite, restConfig *rest.Config, upgrade, dryRun bool, definitionReason func(name string) string) ([]*TestSelection, error) {
+	tests, err := testsForSuite()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading origin test suites: %w", err)
+	}
+
+	selections := []*TestSelection{}
+	if len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 {
+		externalSelections, err := explainExtensionEnvironment(ctx, upgrade, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, externalSelections...)
+
+		// the kube tests built into openshift-tests are replaced by the ones of the kube extension, see Run
+		var filteredTests []*testCase
+		for _, test := range tests {
+			if !strings.Contains(test.name, "[Suite:k8s]") {
+				filteredTests = append(filteredTests, test)
+			}
+		}
+		tests = filteredTests
+	}
+	for _, test := range tests {
+		selections = append(selections, &TestSelection{Name: test.name, Source: originSource, Included: true})
+	}
+
+	var exclusions []string
+	if restConfig != nil {
+		if exclusions, err = rebaseExclusions(restConfig); err != nil {
+			logrus.WithError(err).Warn("unable to determine the rebase exclusions, they will not be explained")
+			restConfig = nil
+		}
+	}
+
+	for _, selection := range selections {
+		name := selection.Name
+		if suite.explainDefinition(name) {
+			selection.addStage(SuiteDefinitionStage, true, "")
+		} else {
+			reason := definitionReason(name)
+			if len(reason) == 0 {
+				reason = fmt.Sprintf("not selected by the definition of suite %s", suite.Name)
+			}
+			selection.addStage(SuiteDefinitionStage, false, reason)
+		}
+
+		for _, filter := range suite.requiredFilters {
+			passed, reason := filter.Explain(name)
+			selection.addStage(filter.Name, passed, reason)
+		}
+
+		if restConfig != nil {
+			excluded, reason := rebaseExclusion(exclusions, name)
+			selection.addStage(RebaseExclusionsStage, !excluded, reason)
+		}
+	}
+
+	sort.Slice(selections, func(i, j int) bool {
+		return selections[i].Name < selections[j].Name
+	})
+	return selections, nil
+}
+
+// explainExtensionEnvironment lists the tests of every extension twice, once without and once with the environment
+// flags of the cluster, since the extensions decide for themselves which tests apply to an environment.
+func explainExtensionEnvironment(ctx context.Context, upgrade, dryRun bool) ([]*TestSelection, error) {
+	extractionContext, extractionContextCancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer extractionContextCancel()
+	cleanUpFn, externalBinaries, err := extensions.ExtractAllTestBinaries(extractionContext, 10)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanUpFn()
+
+	defaultBinaryParallelism := 10
+
+	infoContext, infoContextCancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer infoContextCancel()
+	if _, err := externalBinaries.Info(infoContext, defaultBinaryParallelism); err != nil {
+		return nil, err
+	}
+
+	envFlags, err := determineEnvironmentFlags(upgrade, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine environment flags: %w", err)
+	}
+
+	listContext, listContextCancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer listContextCancel()
+	allSpecs, err := externalBinaries.ListTests(listContext, defaultBinaryParallelism, nil)
+	if err != nil {
+		return nil, err
+	}
+	environmentSpecs, err := externalBinaries.ListTests(listContext, defaultBinaryParallelism, envFlags)
+	if err != nil {
+		return nil, err
+	}
+	listed := sets.New[string]()
+	for _, spec := range environmentSpecs {
+		listed.Insert(spec.Name)
+	}
+
+	selections := []*TestSelection{}
+	for _, spec := range allSpecs {
+		selection := &TestSelection{Name: spec.Name, Source: spec.Source, Included: true}
+		selection.addStage(ExtensionEnvironmentStage, listed.Has(spec.Name),
+			fmt.Sprintf("not listed by the extension when listing with %s", envFlags.String()))
+		selections = append(selections, selection)
+	}
+	return selections, nil
+}
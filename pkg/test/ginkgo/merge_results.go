@@ -0,0 +1,323 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift/origin/pkg/riskanalysis"
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// shardResults are the results one shard of a sharded run wrote to its --junit-dir.
+type shardResults struct {
+	dir               string
+	info              *ShardInfo
+	junits            []*junitapi.JUnitTestSuite
+	extensionResults  extensions.ExtensionTestResults
+	failureSummaries  []*riskanalysis.ProwJobRun
+	outcomesByName    map[string]testOutcome
+	assignedTestNames sets.Set[string]
+}
+
+// testOutcome is the result of a test in a run, after any retries of the run.
+type testOutcome int
+
+const (
+	outcomeSkipped testOutcome = iota
+	outcomePassed
+	outcomeFlaked
+	outcomeFailed
+)
+
+func readShardResults(dir string) (*shardResults, error) {
+	shardInfoFiles, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%s_*.json", shardInfoFilePrefix)))
+	if err != nil {
+		return nil, err
+	}
+	if len(shardInfoFiles) != 1 {
+		return nil, fmt.Errorf("expected one %s_*.json in %s, found %d", shardInfoFilePrefix, dir, len(shardInfoFiles))
+	}
+	content, err := os.ReadFile(shardInfoFiles[0])
+	if err != nil {
+		return nil, err
+	}
+	shard := &shardResults{dir: dir, info: &ShardInfo{}, outcomesByName: map[string]testOutcome{}}
+	if err := json.Unmarshal(content, shard.info); err != nil {
+		return nil, fmt.Errorf("unable to decode shard info %q: %w", shardInfoFiles[0], err)
+	}
+	shard.assignedTestNames = sets.New(shard.info.Tests...)
+
+	junitFiles, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, junitFile := range junitFiles {
+		junit, err := readJUnitTestSuite(junitFile)
+		if err != nil {
+			return nil, err
+		}
+		shard.junits = append(shard.junits, junit)
+	}
+
+	extensionResultFiles, err := filepath.Glob(filepath.Join(dir, "extension_test_result_e2e_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, extensionResultFile := range extensionResultFiles {
+		content, err := os.ReadFile(extensionResultFile)
+		if err != nil {
+			return nil, err
+		}
+		var results extensions.ExtensionTestResults
+		if err := json.Unmarshal(content, &results); err != nil {
+			return nil, fmt.Errorf("unable to decode extension test results %q: %w", extensionResultFile, err)
+		}
+		shard.extensionResults = append(shard.extensionResults, results...)
+	}
+
+	if shard.failureSummaries, err = riskanalysis.ReadJobRunTestFailureSummaries(dir); err != nil {
+		return nil, err
+	}
+
+	// a test that both failed and passed in a shard flaked, as the shard only retries when its failures are allowed
+	passed, failed, skipped := sets.New[string](), sets.New[string](), sets.New[string]()
+	for _, junit := range shard.junits {
+		for _, test := range junit.TestCases {
+			switch {
+			case test.SkipMessage != nil:
+				skipped.Insert(test.Name)
+			case test.FailureOutput != nil:
+				failed.Insert(test.Name)
+			default:
+				passed.Insert(test.Name)
+			}
+		}
+	}
+	for name := range skipped {
+		shard.outcomesByName[name] = outcomeSkipped
+	}
+	for name := range passed {
+		shard.outcomesByName[name] = outcomePassed
+	}
+	for name := range failed {
+		if passed.Has(name) {
+			shard.outcomesByName[name] = outcomeFlaked
+			continue
+		}
+		shard.outcomesByName[name] = outcomeFailed
+	}
+	return shard, nil
+}
+
+// MergedResults is the result of a whole suite, combined from the results of each of its shards.
+type MergedResults struct {
+	Suite  string
+	Passed []string
+	Flaked []string
+	Failed []string
+	// Missing are tests assigned to a shard that did not report a result, because the shard did not finish.
+	Missing []string
+	Skipped []string
+	// FlakesCountedAsFailures is set when more tests failed across the shards than the suite allows flakes, so the
+	// tests that passed when retried by their shard are failures, like they are when the suite runs in one process.
+	FlakesCountedAsFailures bool
+}
+
+// MergeShardResults combines the junit, extension test results, and test failure summaries that every shard of a
+// sharded run wrote to its --junit-dir into the results of the whole suite, written to outputDir.  A test run by more
+// than one shard, like the invariants every shard reports, fails if it failed in any shard without passing on retry.
+func MergeShardResults(dirs []string, outputDir string, out, errOut io.Writer) (*MergedResults, error) {
+	var shards []*shardResults
+	for _, dir := range dirs {
+		shard, err := readShardResults(dir)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+	if err := validateShards(shards); err != nil {
+		return nil, err
+	}
+
+	merged := mergeTestOutcomes(shards)
+
+	failedWithoutPass := sets.New(merged.Failed...)
+	suite := mergeJUnits(shards, failedWithoutPass)
+	var extensionResults extensions.ExtensionTestResults
+	for _, shard := range shards {
+		for _, result := range shard.extensionResults {
+			if result.Result == extensions.ResultPassed && failedWithoutPass.Has(result.Name) {
+				continue
+			}
+			extensionResults = append(extensionResults, result)
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	start := shards[0].info.Start
+	for _, shard := range shards {
+		if shard.info.Start.Before(start) {
+			start = shard.info.Start
+		}
+	}
+	timeSuffix := fmt.Sprintf("_%s", start.UTC().Format("20060102-150405"))
+	if err := writeJUnitReport(suite, "junit_e2e", timeSuffix, outputDir, errOut); err != nil {
+		return nil, fmt.Errorf("unable to write merged JUnit xml results: %w", err)
+	}
+	if err := writeExtensionTestResultList(extensionResults, outputDir, "extension_test_result_e2e", timeSuffix, errOut); err != nil {
+		return nil, fmt.Errorf("unable to write merged Extension Test Result JSON results: %w", err)
+	}
+	// the shards may have run against different clusters, the summary describes the cluster of the first shard
+	for _, shard := range shards {
+		if len(shard.failureSummaries) == 0 {
+			continue
+		}
+		if err := riskanalysis.WriteJobRunTestFailureSummaryForCluster(outputDir, timeSuffix, suite, shard.failureSummaries[0].ClusterData, ""); err != nil {
+			return nil, fmt.Errorf("unable to write merged job run failures summary: %w", err)
+		}
+		break
+	}
+
+	if merged.FlakesCountedAsFailures {
+		fmt.Fprintf(out, "More tests failed across the shards than the %d flakes suite %s allows, tests that passed on retry are failures\n\n",
+			shards[0].info.MaximumAllowedFlakes, merged.Suite)
+	}
+	if len(merged.Flaked) > 0 {
+		fmt.Fprintf(out, "Flaky tests:\n\n%s\n\n", strings.Join(merged.Flaked, "\n"))
+	}
+	if len(merged.Missing) > 0 {
+		fmt.Fprintf(out, "Tests without results:\n\n%s\n\n", strings.Join(merged.Missing, "\n"))
+	}
+	if len(merged.Failed) > 0 {
+		fmt.Fprintf(out, "Failing tests:\n\n%s\n\n", strings.Join(merged.Failed, "\n"))
+	}
+	return merged, nil
+}
+
+// validateShards ensures the results are of every shard of a single sharded run.
+func validateShards(shards []*shardResults) error {
+	if len(shards) == 0 {
+		return fmt.Errorf("no shard results to merge")
+	}
+	first := shards[0].info
+	seen := map[int]string{}
+	for _, shard := range shards {
+		info := shard.info
+		if info.Suite != first.Suite || info.Count != first.Count {
+			return fmt.Errorf("%s is shard %d of %d of suite %s, but %s is of %d shards of suite %s",
+				shard.dir, info.Index, info.Count, info.Suite, shards[0].dir, first.Count, first.Suite)
+		}
+		if info.Selection != first.Selection {
+			return fmt.Errorf("%s and %s split different tests across the shards, every shard must select the same tests with the same --shard-durations-file",
+				shards[0].dir, shard.dir)
+		}
+		if dir, ok := seen[info.Index]; ok {
+			return fmt.Errorf("%s and %s are both shard %d", dir, shard.dir, info.Index)
+		}
+		seen[info.Index] = shard.dir
+	}
+	var missing []string
+	for i := 0; i < first.Count; i++ {
+		if _, ok := seen[i]; !ok {
+			missing = append(missing, fmt.Sprintf("%d", i))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing the results of shards %s of %d", strings.Join(missing, ", "), first.Count)
+	}
+	return nil
+}
+
+// mergeTestOutcomes decides the result of every test of the suite from its results in each shard.  Each shard
+// retries its failures when it has no more than the suite allows flakes, but the suite run in one process would not
+// retry at all with more failures than that across all of its tests, so then the flakes of the shards are failures.
+func mergeTestOutcomes(shards []*shardResults) *MergedResults {
+	outcomes := map[string]testOutcome{}
+	assigned := sets.New[string]()
+	for _, shard := range shards {
+		assigned = assigned.Union(shard.assignedTestNames)
+		for name, outcome := range shard.outcomesByName {
+			if current, ok := outcomes[name]; !ok || outcome > current {
+				outcomes[name] = outcome
+			}
+		}
+	}
+
+	merged := &MergedResults{Suite: shards[0].info.Suite}
+	failedOnce, flaked := 0, 0
+	for name := range assigned {
+		switch outcomes[name] {
+		case outcomeFlaked:
+			flaked++
+			failedOnce++
+		case outcomeFailed:
+			failedOnce++
+		}
+	}
+	merged.FlakesCountedAsFailures = flaked > 0 && failedOnce > shards[0].info.MaximumAllowedFlakes
+
+	for name, outcome := range outcomes {
+		switch {
+		case outcome == outcomeFlaked && merged.FlakesCountedAsFailures && assigned.Has(name):
+			merged.Failed = append(merged.Failed, name)
+		case outcome == outcomeFlaked:
+			merged.Flaked = append(merged.Flaked, name)
+		case outcome == outcomeFailed:
+			merged.Failed = append(merged.Failed, name)
+		case outcome == outcomePassed:
+			merged.Passed = append(merged.Passed, name)
+		default:
+			merged.Skipped = append(merged.Skipped, name)
+		}
+	}
+	for name := range assigned {
+		if _, ok := outcomes[name]; !ok {
+			merged.Missing = append(merged.Missing, name)
+		}
+	}
+	for _, names := range [][]string{merged.Passed, merged.Flaked, merged.Failed, merged.Missing, merged.Skipped} {
+		sort.Strings(names)
+	}
+	return merged
+}
+
+// mergeJUnits combines the junit of every shard into one suite.  The passing results of tests that failed are
+// dropped, so a flake that became a failure is not reported as a flake.
+func mergeJUnits(shards []*shardResults, failed sets.Set[string]) *junitapi.JUnitTestSuite {
+	suite := &junitapi.JUnitTestSuite{}
+	for _, shard := range shards {
+		for _, junit := range shard.junits {
+			if len(suite.Name) == 0 {
+				suite.Name = junit.Name
+				suite.Properties = junit.Properties
+			}
+			// the shards run at the same time, so the suite takes as long as its longest shard
+			if junit.Duration > suite.Duration {
+				suite.Duration = junit.Duration
+			}
+			for _, test := range junit.TestCases {
+				if test.SkipMessage == nil && test.FailureOutput == nil && failed.Has(test.Name) {
+					continue
+				}
+				suite.NumTests++
+				switch {
+				case test.SkipMessage != nil:
+					suite.NumSkipped++
+				case test.FailureOutput != nil:
+					suite.NumFailed++
+				}
+				suite.TestCases = append(suite.TestCases, test)
+			}
+		}
+	}
+	return suite
+}
@@ -0,0 +1,166 @@
+package ginkgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShard struct {
+	// selection defaults to the selection of every other shard
+	selection string
+	tests     []string
+	junit     []*junitapi.JUnitTestCase
+	extension extensions.ExtensionTestResults
+}
+
+func writeFakeShards(t *testing.T, maximumAllowedFlakes int, shards ...fakeShard) []string {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var dirs []string
+	for i, shard := range shards {
+		dir := t.TempDir()
+		if len(shard.selection) == 0 {
+			shard.selection = "sha256:0123abcd"
+		}
+		require.NoError(t, writeShardInfo(&ShardInfo{
+			Suite:                "openshift/conformance/parallel",
+			Index:                i,
+			Count:                len(shards),
+			MaximumAllowedFlakes: maximumAllowedFlakes,
+			Start:                start.Add(time.Duration(i) * time.Minute),
+			Selection:            shard.selection,
+			Tests:                shard.tests,
+		}, dir))
+		suite := &junitapi.JUnitTestSuite{Name: "openshift-tests", TestCases: shard.junit, Duration: float64(100 * (i + 1))}
+		require.NoError(t, writeJUnitReport(suite, "junit_e2e", "_20240101-000000", dir, io.Discard))
+		require.NoError(t, writeExtensionTestResultList(shard.extension, dir, "extension_test_result_e2e", "_20240101-000000", io.Discard))
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+func passed(name string) *junitapi.JUnitTestCase {
+	return &junitapi.JUnitTestCase{Name: name}
+}
+
+func failed(name string) *junitapi.JUnitTestCase {
+	return &junitapi.JUnitTestCase{Name: name, FailureOutput: &junitapi.FailureOutput{Output: "fail [test.go:1]: failed"}}
+}
+
+func skipped(name string) *junitapi.JUnitTestCase {
+	return &junitapi.JUnitTestCase{Name: name, SkipMessage: &junitapi.SkipMessage{Message: "skip"}}
+}
+
+func TestMergeShardResults(t *testing.T) {
+	dirs := writeFakeShards(t, 2,
+		fakeShard{
+			tests: []string{"a", "b", "c"},
+			junit: []*junitapi.JUnitTestCase{passed("a"), failed("b"), passed("b"), skipped("c"), passed("invariant")},
+			extension: extensions.ExtensionTestResults{
+				{Name: "b", Result: extensions.ResultFailed},
+				{Name: "b", Result: extensions.ResultPassed},
+			},
+		},
+		fakeShard{
+			tests: []string{"d", "e"},
+			junit: []*junitapi.JUnitTestCase{passed("d"), failed("invariant")},
+		},
+	)
+
+	output := t.TempDir()
+	out := &bytes.Buffer{}
+	merged, err := MergeShardResults(dirs, output, out, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, &MergedResults{
+		Suite:   "openshift/conformance/parallel",
+		Passed:  []string{"a", "d"},
+		Flaked:  []string{"b"},
+		Failed:  []string{"invariant"},
+		Missing: []string{"e"},
+		Skipped: []string{"c"},
+	}, merged)
+	assert.Contains(t, out.String(), "Flaky tests:\n\nb\n\n")
+	assert.Contains(t, out.String(), "Tests without results:\n\ne\n\n")
+
+	suite, err := readJUnitTestSuite(filepath.Join(output, "junit_e2e__20240101-000000.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, "openshift-tests", suite.Name)
+	assert.Equal(t, uint(6), suite.NumTests, "the passing result of the failing invariant is dropped")
+	assert.Equal(t, uint(2), suite.NumFailed)
+	assert.Equal(t, uint(1), suite.NumSkipped)
+	assert.Equal(t, float64(200), suite.Duration)
+
+	content, err := os.ReadFile(filepath.Join(output, "extension_test_result_e2e__20240101-000000.json"))
+	require.NoError(t, err)
+	var results extensions.ExtensionTestResults
+	require.NoError(t, json.Unmarshal(content, &results))
+	assert.Len(t, results, 2)
+}
+
+func TestMergeShardResultsCountsFlakesAsFailures(t *testing.T) {
+	dirs := writeFakeShards(t, 1,
+		fakeShard{
+			tests: []string{"a", "b"},
+			junit: []*junitapi.JUnitTestCase{failed("a"), passed("a"), passed("b")},
+			extension: extensions.ExtensionTestResults{
+				{Name: "a", Result: extensions.ResultFailed},
+				{Name: "a", Result: extensions.ResultPassed},
+			},
+		},
+		fakeShard{
+			tests: []string{"c"},
+			junit: []*junitapi.JUnitTestCase{failed("c"), passed("c")},
+		},
+	)
+
+	output := t.TempDir()
+	merged, err := MergeShardResults(dirs, output, io.Discard, io.Discard)
+	require.NoError(t, err)
+	assert.True(t, merged.FlakesCountedAsFailures)
+	assert.Equal(t, []string{"a", "c"}, merged.Failed)
+	assert.Empty(t, merged.Flaked)
+
+	suite, err := readJUnitTestSuite(filepath.Join(output, "junit_e2e__20240101-000000.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), suite.NumTests)
+	assert.Equal(t, uint(2), suite.NumFailed)
+
+	content, err := os.ReadFile(filepath.Join(output, "extension_test_result_e2e__20240101-000000.json"))
+	require.NoError(t, err)
+	var results extensions.ExtensionTestResults
+	require.NoError(t, json.Unmarshal(content, &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, extensions.ResultFailed, results[0].Result)
+}
+
+func TestMergeShardResultsRequiresEveryShard(t *testing.T) {
+	dirs := writeFakeShards(t, 1,
+		fakeShard{tests: []string{"a"}, junit: []*junitapi.JUnitTestCase{passed("a")}},
+		fakeShard{tests: []string{"b"}, junit: []*junitapi.JUnitTestCase{passed("b")}},
+	)
+
+	_, err := MergeShardResults(dirs[:1], t.TempDir(), io.Discard, io.Discard)
+	assert.EqualError(t, err, "missing the results of shards 1 of 2")
+
+	_, err = MergeShardResults([]string{dirs[0], dirs[0]}, t.TempDir(), io.Discard, io.Discard)
+	assert.ErrorContains(t, err, "are both shard 0")
+}
+
+func TestMergeShardResultsRequiresTheSameSelection(t *testing.T) {
+	dirs := writeFakeShards(t, 1,
+		fakeShard{tests: []string{"a"}, junit: []*junitapi.JUnitTestCase{passed("a")}},
+		fakeShard{selection: "sha256:4567cdef", tests: []string{"a", "b"}, junit: []*junitapi.JUnitTestCase{passed("a"), passed("b")}},
+	)
+
+	_, err := MergeShardResults(dirs, t.TempDir(), io.Discard, io.Discard)
+	assert.ErrorContains(t, err, "split different tests across the shards")
+}
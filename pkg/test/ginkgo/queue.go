@@ -136,6 +136,28 @@ func isSerialTest(test *testCase) bool {
 	return false
 }
 
+func isEarlyTest(test *testCase) bool {
+	return strings.Contains(test.name, "[Early]")
+}
+
+func isLateTest(test *testCase) bool {
+	return strings.Contains(test.name, "[Late]")
+}
+
+func isKubeTest(test *testCase) bool {
+	return strings.Contains(test.name, "[Suite:k8s]")
+}
+
+// isStorageTest only applies to kube tests, storage tests from openshift run with the rest of openshift.
+func isStorageTest(test *testCase) bool {
+	return strings.Contains(test.name, "[sig-storage]")
+}
+
+// isMustGatherTest only applies to openshift tests.
+func isMustGatherTest(test *testCase) bool {
+	return strings.Contains(test.name, "[sig-cli] oc adm must-gather")
+}
+
 func copyTests(tests []*testCase) []*testCase {
 	copied := make([]*testCase, 0, len(tests))
 	for _, t := range tests {
@@ -0,0 +1,199 @@
+package ginkgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// shardInfoFilePrefix is the prefix of the file each shard writes to its --junit-dir, which merge-results reads.
+const shardInfoFilePrefix = "shard"
+
+// defaultShardTestDuration is the duration assumed for every test when no test has a known duration.
+const defaultShardTestDuration = time.Minute
+
+// ShardInfo records which tests of a suite a single shard of a sharded run was assigned, so the results of every
+// shard can be merged into the result of the whole suite.
+type ShardInfo struct {
+	Suite string `json:"suite"`
+	Index int    `json:"index"`
+	Count int    `json:"count"`
+	// MaximumAllowedFlakes is the number of failures of the whole suite up to which failed tests are retried.
+	MaximumAllowedFlakes int       `json:"maximumAllowedFlakes"`
+	Start                time.Time `json:"start"`
+	// Selection is a hash of every test of the suite before sharding and the duration the assignment assumed for
+	// each, see shardSelection.  Shards with different selections did not split the same tests the same way.
+	Selection string   `json:"selection"`
+	Tests     []string `json:"tests"`
+}
+
+// shardGroup is the set of tests that are balanced across shards together.  Early and late tests run before and
+// after the other buckets, and serial tests after the parallel tests of their bucket, so each shard receives a
+// balanced share of every group and no shard runs much longer than the others in any phase of the run.
+type shardGroup struct {
+	bucket TestBucket
+	serial bool
+}
+
+// shardBucketOrder fixes the order groups are assigned in, so the assignment does not depend on map iteration.
+var shardBucketOrder = []TestBucket{
+	TestBucketEarly,
+	TestBucketKube,
+	TestBucketStorage,
+	TestBucketOpenShift,
+	TestBucketMustGather,
+	TestBucketLate,
+}
+
+// shardTests returns the tests assigned to shard index of count, in the order they were passed.  Every shard that
+// selects the same tests computes the same assignment, so each test runs in exactly one shard.  Within each group
+// the longest tests are assigned first, each to the shard with the least duration of that group so far, or on a tie,
+// the least duration of all groups so far.
+func shardTests(tests []*testCase, index, count int, durations map[string]time.Duration) []*testCase {
+	if count <= 1 {
+		return tests
+	}
+
+	groups := map[shardGroup][]*testCase{}
+	for _, test := range tests {
+		group := shardGroup{bucket: testBucket(test), serial: isSerialTest(test)}
+		groups[group] = append(groups[group], test)
+	}
+
+	estimate := estimateTestDuration(tests, durations)
+	assigned := sets.New[string]()
+	totals := make([]time.Duration, count)
+	for _, bucket := range shardBucketOrder {
+		for _, serial := range []bool{false, true} {
+			group := groups[shardGroup{bucket: bucket, serial: serial}]
+			sort.Slice(group, func(i, j int) bool {
+				if di, dj := estimate(group[i]), estimate(group[j]); di != dj {
+					return di > dj
+				}
+				return group[i].name < group[j].name
+			})
+
+			loads := make([]time.Duration, count)
+			for _, test := range group {
+				shard := 0
+				for i := range loads {
+					if loads[i] < loads[shard] || (loads[i] == loads[shard] && totals[i] < totals[shard]) {
+						shard = i
+					}
+				}
+				loads[shard] += estimate(test)
+				totals[shard] += estimate(test)
+				if shard == index {
+					assigned.Insert(test.name)
+				}
+			}
+		}
+	}
+
+	var ret []*testCase
+	for _, test := range tests {
+		if assigned.Has(test.name) {
+			ret = append(ret, test)
+		}
+	}
+	return ret
+}
+
+// shardSelection returns a hash of the tests that are split across shards and the duration estimated for each, which
+// together decide the assignment, so every shard of a run computes the same hash.
+func shardSelection(tests []*testCase, durations map[string]time.Duration) string {
+	estimate := estimateTestDuration(tests, durations)
+	hash := sha256.New()
+	for _, test := range sortedTests(tests) {
+		fmt.Fprintf(hash, "%s\t%d\n", test.name, estimate(test))
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// estimateTestDuration returns how long each test is expected to take: its duration in a previous run, otherwise the
+// duration its extension declares, otherwise the average of the tests whose duration is known.
+func estimateTestDuration(tests []*testCase, durations map[string]time.Duration) func(*testCase) time.Duration {
+	known := func(test *testCase) (time.Duration, bool) {
+		if duration, ok := durations[test.name]; ok {
+			return duration, true
+		}
+		if test.extensionSpec != nil && len(test.extensionSpec.Resources.Duration) > 0 {
+			if duration, err := time.ParseDuration(test.extensionSpec.Resources.Duration); err == nil {
+				return duration, true
+			}
+		}
+		return 0, false
+	}
+
+	fallback := defaultShardTestDuration
+	var total time.Duration
+	var counted int64
+	for _, test := range tests {
+		if duration, ok := known(test); ok {
+			total += duration
+			counted++
+		}
+	}
+	if counted > 0 && total > 0 {
+		fallback = total / time.Duration(counted)
+	}
+
+	return func(test *testCase) time.Duration {
+		if duration, ok := known(test); ok {
+			return duration
+		}
+		return fallback
+	}
+}
+
+// readTestDurations reads the duration of every test that did not skip from junit files of previous runs.  When a
+// test appears more than once, the longest duration is used.
+func readTestDurations(filenames []string) (map[string]time.Duration, error) {
+	durations := map[string]time.Duration{}
+	for _, filename := range filenames {
+		suite, err := readJUnitTestSuite(filename)
+		if err != nil {
+			return nil, err
+		}
+		for _, test := range suite.TestCases {
+			if test.SkipMessage != nil {
+				continue
+			}
+			duration := time.Duration(test.Duration * float64(time.Second))
+			if duration > durations[test.Name] {
+				durations[test.Name] = duration
+			}
+		}
+	}
+	return durations, nil
+}
+
+func readJUnitTestSuite(filename string) (*junitapi.JUnitTestSuite, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	suite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(content, suite); err != nil {
+		return nil, fmt.Errorf("unable to decode junit %q: %w", filename, err)
+	}
+	return suite, nil
+}
+
+func writeShardInfo(info *ShardInfo, junitDir string) error {
+	content, err := json.MarshalIndent(info, "", "    ")
+	if err != nil {
+		return err
+	}
+	timeSuffix := fmt.Sprintf("_%s", info.Start.UTC().Format("20060102-150405"))
+	return os.WriteFile(filepath.Join(junitDir, fmt.Sprintf("%s%s.json", shardInfoFilePrefix, timeSuffix)), content, 0644)
+}
@@ -0,0 +1,90 @@
+package ginkgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestShardTests(t *testing.T) {
+	var tests []*testCase
+	durations := map[string]time.Duration{}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("[sig-apps] parallel %02d [Suite:openshift/conformance/parallel]", i)
+		tests = append(tests, &testCase{name: name})
+		durations[name] = time.Duration(i+1) * time.Minute
+	}
+	for i := 0; i < 4; i++ {
+		tests = append(tests,
+			&testCase{name: fmt.Sprintf("[sig-apps] serial %d [Serial] [Suite:openshift/conformance/serial]", i)},
+			&testCase{name: fmt.Sprintf("[sig-etcd] early %d [Early] [Suite:openshift/conformance/parallel]", i)},
+			&testCase{name: fmt.Sprintf("[sig-arch] late %d [Late] [Suite:openshift/conformance/parallel]", i)},
+		)
+	}
+
+	const count = 3
+	seen := sets.New[string]()
+	var loads []time.Duration
+	estimate := estimateTestDuration(tests, durations)
+	for index := 0; index < count; index++ {
+		shard := shardTests(tests, index, count, durations)
+		assert.Equal(t, testNames(shard), testNames(shardTests(tests, index, count, durations)), "assignment must be deterministic")
+
+		var load time.Duration
+		groups := map[shardGroup]int{}
+		for _, test := range shard {
+			assert.False(t, seen.Has(test.name), "%q assigned to more than one shard", test.name)
+			seen.Insert(test.name)
+			groups[shardGroup{bucket: testBucket(test), serial: isSerialTest(test)}]++
+			load += estimate(test)
+		}
+		loads = append(loads, load)
+
+		// every shard receives a share of each group
+		assert.Positive(t, groups[shardGroup{bucket: TestBucketEarly}])
+		assert.Positive(t, groups[shardGroup{bucket: TestBucketLate}])
+		assert.Positive(t, groups[shardGroup{bucket: TestBucketOpenShift, serial: true}])
+	}
+	assert.Equal(t, len(tests), seen.Len())
+	for _, load := range loads {
+		assert.InDelta(t, float64(loads[0]), float64(load), float64(5*time.Minute), "shards are not balanced: %v", loads)
+	}
+
+	// the order of the tests passed in is preserved
+	reversed := make([]*testCase, len(tests))
+	for i, test := range tests {
+		reversed[len(tests)-1-i] = test
+	}
+	assert.ElementsMatch(t, testNames(shardTests(tests, 1, count, durations)), testNames(shardTests(reversed, 1, count, durations)))
+
+	assert.Equal(t, tests, shardTests(tests, 0, 1, durations))
+
+	// every shard hashes the same selection, whatever order it selected the tests in
+	selection := shardSelection(tests, durations)
+	assert.Equal(t, selection, shardSelection(reversed, durations))
+	assert.NotEqual(t, selection, shardSelection(tests[1:], durations), "a test only some shards selected")
+	assert.NotEqual(t, selection, shardSelection(tests, nil), "shards balanced with different durations")
+}
+
+func TestReadTestDurations(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "junit_e2e_1.xml")
+	second := filepath.Join(dir, "junit_e2e_2.xml")
+	require.NoError(t, os.WriteFile(first, []byte(`<testsuite name="openshift-tests" tests="2" skipped="1" failures="0" time="100">
+    <testcase name="a" time="30"></testcase>
+    <testcase name="b" time="0"><skipped message="skip"></skipped></testcase>
+</testsuite>`), 0644))
+	require.NoError(t, os.WriteFile(second, []byte(`<testsuite name="openshift-tests" tests="1" skipped="0" failures="0" time="100">
+    <testcase name="a" time="45.5"></testcase>
+</testsuite>`), 0644))
+
+	durations, err := readTestDurations([]string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"a": 45500 * time.Millisecond}, durations)
+}
@@ -43,22 +43,29 @@ func (r *testSuiteRunnerImpl) RunOneTest(ctx context.Context, test *testCase) {
 	// remember that defers are last-added, first-executed.
 	testRunResult := &testRunResultHandle{}
 
-	// if we need to abort, then abort
-	defer r.maybeAbortOnFailureFn(testRunResult)
-
 	// record the test happening with the monitor
 	r.testOutput.monitorRecorder.AddIntervals(monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
 		Locator(monitorapi.NewLocator().E2ETest(test.name)).
 		Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted)).BuildNow())
 
-	defer recordTestResultInMonitor(testRunResult, r.testOutput.monitorRecorder)
-
 	// log the results to systemout
 	r.testSuiteProgress.LogTestStart(r.testOutput.out, test.name)
-	defer r.testSuiteProgress.TestEnded(test.name, testRunResult)
-	defer recordTestResultInLogWithoutOverlap(testRunResult, r.testOutput.testOutputLock, r.testOutput.out, r.testOutput.includeSuccessfulOutput)
 
-	testRunResult.testRunResult = r.commandContext.RunTestInNewProcess(ctx, test)
+	// the result is reported once, as soon as it is known.  Extension binaries stream their results, which may be
+	// well before the binary exits, otherwise it is reported when the test process exits.
+	var reportOnce sync.Once
+	report := func() {
+		reportOnce.Do(func() {
+			recordTestResultInLogWithoutOverlap(testRunResult, r.testOutput.testOutputLock, r.testOutput.out, r.testOutput.includeSuccessfulOutput)
+			r.testSuiteProgress.TestEnded(test.name, testRunResult)
+			recordTestResultInMonitor(testRunResult, r.testOutput.monitorRecorder)
+			// if we need to abort, then abort
+			r.maybeAbortOnFailureFn(testRunResult)
+		})
+	}
+	defer report()
+
+	testRunResult.testRunResult = r.commandContext.RunTestInNewProcess(ctx, test, testRunResult.setAndThen(report))
 	mutateTestCaseWithResults(test, testRunResult)
 }
 
@@ -138,6 +145,14 @@ type testRunResultHandle struct {
 	*testRunResult
 }
 
+// setAndThen returns a function that sets the result of the handle and then calls fn.
+func (h *testRunResultHandle) setAndThen(fn func()) func(*testRunResult) {
+	return func(result *testRunResult) {
+		h.testRunResult = result
+		fn()
+	}
+}
+
 type testRunResult struct {
 	name                string
 	start               time.Time
@@ -290,8 +305,9 @@ func recordTestResultInMonitor(testRunResult *testRunResultHandle, monitorRecord
 		Message(msg.HumanMessage("finished").Reason(monitorapi.E2ETestFinished)).BuildNow())
 }
 
-// RunTestInNewProcess runs a test case in a different process and returns a result
-func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase) *testRunResult {
+// RunTestInNewProcess runs a test case in a different process and returns a result.  When the test is run by an
+// extension binary, onResult is called with the result as soon as the binary streams it, before the binary exits.
+func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase, onResult func(*testRunResult)) *testRunResult {
 	ret := &testRunResult{
 		name:      test.name,
 		testState: TestUnknown,
@@ -307,23 +323,15 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 	testEnv := append(os.Environ(), updateEnvVars(c.env)...)
 
 	if test.binary != nil {
-		results := test.binary.RunTests(ctx, c.timeout, testEnv, test.name)
+		results := test.binary.RunTestsStreaming(ctx, c.timeout, testEnv, func(result *extensions.ExtensionTestResult) {
+			if onResult != nil {
+				onResult(extensionTestRunResult(ret, result))
+			}
+		}, test.name)
 		if len(results) != 1 {
 			fmt.Fprintf(os.Stderr, "warning: expected 1 result from external binary; received %d", len(results))
 		}
-		switch results[0].Result {
-		case extensions.ResultFailed:
-			ret.testState = TestFailed
-			ret.testOutputBytes = []byte(fmt.Sprintf("%s\n%s", results[0].Output, results[0].Error))
-		case extensions.ResultPassed:
-			ret.testState = TestSucceeded
-		case extensions.ResultSkipped:
-			ret.testState = TestSkipped
-		}
-		ret.start = extensions.Time(results[0].StartTime)
-		ret.end = extensions.Time(results[0].EndTime)
-		ret.extensionTestResult = results[0]
-		return ret
+		return extensionTestRunResult(ret, results[0])
 	}
 
 	testName := test.rawName
@@ -377,6 +385,34 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 	return ret
 }
 
+// extensionTestRunResult returns the result of a test run by an extension binary.  A result without times, like
+// the failure for a binary that crashed before producing one, ends when it is received.
+func extensionTestRunResult(started *testRunResult, result *extensions.ExtensionTestResult) *testRunResult {
+	ret := &testRunResult{
+		name:                started.name,
+		start:               started.start,
+		end:                 time.Now(),
+		testState:           TestUnknown,
+		extensionTestResult: result,
+	}
+	switch result.Result {
+	case extensions.ResultFailed:
+		ret.testState = TestFailed
+		ret.testOutputBytes = []byte(fmt.Sprintf("%s\n%s", result.Output, result.Error))
+	case extensions.ResultPassed:
+		ret.testState = TestSucceeded
+	case extensions.ResultSkipped:
+		ret.testState = TestSkipped
+	}
+	if result.StartTime != nil {
+		ret.start = extensions.Time(result.StartTime)
+	}
+	if result.EndTime != nil {
+		ret.end = extensions.Time(result.EndTime)
+	}
+	return ret
+}
+
 func updateEnvVars(envs []string) []string {
 	result := []string{}
 	for _, env := range envs {
@@ -387,8 +423,11 @@ func updateEnvVars(envs []string) []string {
 	// copied from provider.go
 	// TODO: add error handling, and maybe turn this into sharable helper?
 	config := &clusterdiscovery.ClusterConfiguration{}
-	clientConfig, _ := framework.LoadConfig(true)
-	clusterState, _ := clusterdiscovery.DiscoverClusterState(clientConfig)
+	clusterState := clusterdiscovery.ReplayedClusterState()
+	if clusterState == nil {
+		clientConfig, _ := framework.LoadConfig(true)
+		clusterState, _ = clusterdiscovery.DiscoverClusterState(clientConfig)
+	}
 	if clusterState != nil {
 		config, _ = clusterdiscovery.LoadConfig(clusterState)
 	}
@@ -53,9 +53,10 @@ func externalBinaryTestsToOriginTestCases(specs extensions.ExtensionTestSpecs) [
 	var tests []*testCase
 	for _, spec := range specs {
 		tests = append(tests, &testCase{
-			name:    spec.Name,
-			rawName: spec.Name,
-			binary:  spec.Binary,
+			name:          spec.Name,
+			rawName:       spec.Name,
+			binary:        spec.Binary,
+			extensionSpec: spec,
 		})
 	}
 	return tests
@@ -92,6 +93,8 @@ type testCase struct {
 	binaryName string
 	// binary is the reference when using an external binary
 	binary *extensions.TestBinary
+	// extensionSpec is the spec the external binary listed the test with
+	extensionSpec *extensions.ExtensionTestSpec
 
 	spec      types.TestSpec
 	locations []types.CodeLocation
@@ -159,10 +162,23 @@ type TestSuite struct {
 	ClusterStabilityDuringTest ClusterStabilityDuringTest
 
 	TestTimeout time.Duration
+
+	// definitionMatches is Matches before any required filters were added, the tests the suite itself selects.
+	definitionMatches TestMatchFunc
+	// requiredFilters are the filters added to Matches, in order, so the filter that removed a test can be reported.
+	requiredFilters []TestFilter
 }
 
 type TestMatchFunc func(name string) bool
 
+// TestFilter is a named filter that is required to match for a test to be part of a suite.
+type TestFilter struct {
+	// Name describes the filter, like "feature gates".
+	Name string
+	// Explain returns whether the test passes the filter, and if not, the rule or annotation that removed it.
+	Explain func(name string) (bool, string)
+}
+
 func (s *TestSuite) Filter(tests []*testCase) []*testCase {
 	matches := make([]*testCase, 0, len(tests))
 	for _, test := range tests {
@@ -178,6 +194,28 @@ func (s *TestSuite) AddRequiredMatchFunc(matchFn TestMatchFunc) {
 	if matchFn == nil {
 		return
 	}
+	s.AddRequiredFilter(TestFilter{
+		Name: "additional filter",
+		Explain: func(name string) (bool, string) {
+			return matchFn(name), ""
+		},
+	})
+}
+
+// AddRequiredFilter is AddRequiredMatchFunc for a filter that can explain which rule removed a test.
+func (s *TestSuite) AddRequiredFilter(filter TestFilter) {
+	if filter.Explain == nil {
+		return
+	}
+	matchFn := func(name string) bool {
+		matches, _ := filter.Explain(name)
+		return matches
+	}
+	if len(s.requiredFilters) == 0 {
+		s.definitionMatches = s.Matches
+	}
+	s.requiredFilters = append(s.requiredFilters, filter)
+
 	if s.Matches == nil {
 		s.Matches = matchFn
 		return
@@ -0,0 +1,59 @@
+package ginkgo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRequiredFilter(t *testing.T) {
+	suite := &TestSuite{
+		Name: "example",
+		Matches: func(name string) bool {
+			return strings.Contains(name, "[Suite:example]")
+		},
+	}
+	suite.AddRequiredFilter(TestFilter{
+		Name: "serial",
+		Explain: func(name string) (bool, string) {
+			if strings.Contains(name, "[Serial]") {
+				return false, "annotated [Serial]"
+			}
+			return true, ""
+		},
+	})
+	suite.AddRequiredMatchFunc(func(name string) bool {
+		return !strings.Contains(name, "[Slow]")
+	})
+
+	assert.True(t, suite.Matches("test [Suite:example]"))
+	assert.False(t, suite.Matches("test [Serial] [Suite:example]"))
+	assert.False(t, suite.Matches("test [Slow] [Suite:example]"))
+	assert.False(t, suite.Matches("test"))
+
+	assert.True(t, suite.explainDefinition("test [Serial] [Suite:example]"))
+	assert.False(t, suite.explainDefinition("test"))
+
+	selection := &TestSelection{Name: "test [Serial] [Slow] [Suite:example]", Included: true}
+	for _, filter := range suite.requiredFilters {
+		passed, reason := filter.Explain(selection.Name)
+		selection.addStage(filter.Name, passed, reason)
+	}
+	assert.False(t, selection.Included)
+	assert.Equal(t, []TestSelectionStage{
+		{Stage: "serial", Passed: false, Reason: "annotated [Serial]"},
+		{Stage: "additional filter", Passed: false, Reason: "removed by the additional filter"},
+	}, selection.Stages)
+}
+
+func TestRebaseExclusion(t *testing.T) {
+	exclusions := []string{"[sig-api-machinery] health handlers should contain necessary checks"}
+
+	excluded, reason := rebaseExclusion(exclusions, "[sig-api-machinery] health handlers should contain necessary checks [Suite:k8s]")
+	assert.True(t, excluded)
+	assert.Equal(t, `rebase in progress, excluded by "[sig-api-machinery] health handlers should contain necessary checks"`, reason)
+
+	excluded, _ = rebaseExclusion(exclusions, "[sig-api-machinery] other test")
+	assert.False(t, excluded)
+}
@@ -22,13 +22,30 @@ func SuitesString(suites []*ginkgo.TestSuite, prefix string) string {
 }
 
 func isDisabled(name string) bool {
+	return len(DisabledReason(name)) > 0
+}
+
+// DisabledReason returns the annotation that disables a test in every suite, or an empty string if the test is not
+// disabled.
+func DisabledReason(name string) string {
+	if match := disabledRegex.FindString(name); len(match) > 0 {
+		return fmt.Sprintf("annotated %s", match)
+	}
 	if strings.Contains(name, "[Disabled") {
-		return true
+		return "annotated [Disabled"
 	}
 
-	return shouldSkipUntil(name)
+	if shouldSkipUntil(name) {
+		return fmt.Sprintf("annotated %s, which has not passed yet", skippedUntilRegex.FindString(name))
+	}
+	return ""
 }
 
+var (
+	disabledRegex     = regexp.MustCompile(`\[Disabled[^]]*\]`)
+	skippedUntilRegex = regexp.MustCompile(`\[SkippedUntil:(\d{8}):blocker-bz\/([a-zA-Z0-9]+)\]`)
+)
+
 // shouldSkipUntil allows a test to be skipped with a time limit.
 // the test should be annotated with the 'SkippedUntil' tag, as shown below.
 //
@@ -39,13 +56,7 @@ func isDisabled(name string) bool {
 // if the specified date in the tag has not passed yet, the test
 // will be skipped by the runner.
 func shouldSkipUntil(name string) bool {
-	re, err := regexp.Compile(`\[SkippedUntil:(\d{8}):blocker-bz\/([a-zA-Z0-9]+)\]`)
-	if err != nil {
-		// it should only happen with a programmer error and unit
-		// test will prevent that
-		return false
-	}
-	matches := re.FindStringSubmatch(name)
+	matches := skippedUntilRegex.FindStringSubmatch(name)
 	if len(matches) != 3 {
 		return false
 	}
@@ -74,3 +74,39 @@ func TestSkippedUntil(t *testing.T) {
 		})
 	}
 }
+
+func TestDisabledReason(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 5).Format("01022006")
+
+	tests := []struct {
+		name     string
+		testName string
+		reason   string
+	}{
+		{
+			name:     "not disabled",
+			testName: "[sig-api-machinery] testing foo [Suite:openshift/conformance/parallel]",
+		},
+		{
+			name:     "disabled with a reason",
+			testName: "[sig-api-machinery] testing foo [Disabled:Broken] [Suite:openshift/conformance/parallel]",
+			reason:   "annotated [Disabled:Broken]",
+		},
+		{
+			name:     "skipped until a date in the future",
+			testName: fmt.Sprintf("[sig-api-machinery] testing foo [SkippedUntil:%s:blocker-bz/123456] [Suite:openshift]", future),
+			reason:   fmt.Sprintf("annotated [SkippedUntil:%s:blocker-bz/123456], which has not passed yet", future),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DisabledReason(test.testName); got != test.reason {
+				t.Errorf("Expected: %q, but got: %q", test.reason, got)
+			}
+			if got := isDisabled(test.testName); got != (len(test.reason) > 0) {
+				t.Errorf("Expected disabled to be %v", len(test.reason) > 0)
+			}
+		})
+	}
+}
@@ -3,16 +3,16 @@ package prometheus
 import (
 	"testing"
 
-	v1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/alerts"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/exceptionregistry"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMetricConditions_MatchesInterval(t *testing.T) {
 
-	_, allowedFiring, _, _ := alerts.AllowedAlertsDuringConformance(v1.FeatureSet(""))
+	_, allowedFiring, _, _ := alerts.AllowedAlertsDuringConformance(exceptionregistry.Environment{})
 
 	type args struct {
 		alertInterval monitorapi.Interval
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/apis/apiserver"
+	apiserverinstall "k8s.io/apiserver/pkg/apis/apiserver/install"
+	"k8s.io/apiserver/pkg/storage/value"
+	aestransformer "k8s.io/apiserver/pkg/storage/value/encrypt/aes"
+	"k8s.io/apiserver/pkg/storage/value/encrypt/secretbox"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// These match the prefixes the kube-apiserver writes in front of encrypted values.
+const (
+	encryptedPrefix       = "k8s:enc:"
+	aesCBCPrefix          = "k8s:enc:aescbc:v1:"
+	aesGCMPrefix          = "k8s:enc:aesgcm:v1:"
+	secretboxPrefix       = "k8s:enc:secretbox:v1:"
+	kmsPrefix             = "k8s:enc:kms:"
+	protobufEncodingBytes = "k8s\x00"
+)
+
+// valueDecoder turns the values stored in etcd back into objects.  Values are decrypted first when they were written
+// with an encryption provider, then decoded with the built-in scheme, falling back to unstructured JSON for the types
+// the scheme does not know, like CRD instances.
+type valueDecoder struct {
+	// transformers has one transformer per resource in the EncryptionConfiguration, empty without one.
+	transformers []value.Transformer
+}
+
+func newValueDecoder(encryptionConfigFile string) (*valueDecoder, error) {
+	if len(encryptionConfigFile) == 0 {
+		return &valueDecoder{}, nil
+	}
+	transformers, err := loadEncryptionConfig(encryptionConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return &valueDecoder{transformers: transformers}, nil
+}
+
+// decode returns the object stored under key.
+func (d *valueDecoder) decode(key string, data []byte) (runtime.Object, *schema.GroupVersionKind, error) {
+	data, err := d.decrypt(key, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err == nil {
+		return obj, gvk, nil
+	}
+	// protobuf cannot be read without the type, but CRD instances, the usual kinds the scheme does not know, are
+	// always stored as JSON
+	if bytes.HasPrefix(data, []byte(protobufEncodingBytes)) {
+		return nil, nil, err
+	}
+	obj, gvk, unstructuredErr := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+	if unstructuredErr != nil {
+		return nil, nil, fmt.Errorf("%v, and not JSON either: %v", err, unstructuredErr)
+	}
+	return obj, gvk, nil
+}
+
+func (d *valueDecoder) decrypt(key string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedPrefix)) {
+		return data, nil
+	}
+	provider := encryptionProvider(data)
+	if strings.HasPrefix(provider, kmsPrefix) {
+		return nil, fmt.Errorf("value is encrypted with %s, which needs the KMS plugin to decrypt", strings.TrimSuffix(provider, ":"))
+	}
+	if len(d.transformers) == 0 {
+		return nil, fmt.Errorf("value is encrypted with %s, use -encryption-config to decrypt it", strings.TrimSuffix(provider, ":"))
+	}
+
+	// the same key name may be configured for several resources, the first one that decrypts the value wins
+	var lastErr error
+	for _, transformer := range d.transformers {
+		out, _, err := transformer.TransformFromStorage(context.Background(), data, value.DefaultContext(key))
+		if err == nil {
+			return out, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("unable to decrypt value encrypted with %s: %v", strings.TrimSuffix(provider, ":"), lastErr)
+}
+
+// encryptionProvider returns the provider part of the prefix of an encrypted value, like k8s:enc:aescbc:v1:key1:.
+func encryptionProvider(data []byte) string {
+	parts := bytes.SplitN(data, []byte(":"), 6)
+	if len(parts) < 6 {
+		return encryptedPrefix
+	}
+	return string(bytes.Join(parts[:5], []byte(":"))) + ":"
+}
+
+// loadEncryptionConfig builds the transformers for the local key providers of an EncryptionConfiguration, the same
+// way the kube-apiserver does.  KMS providers are skipped since their keys never leave the plugin.
+func loadEncryptionConfig(filename string) ([]value.Transformer, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	configScheme := runtime.NewScheme()
+	apiserverinstall.Install(configScheme)
+	configObj, gvk, err := serializer.NewCodecFactory(configScheme).UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding encryption configuration %q: %v", filename, err)
+	}
+	config, ok := configObj.(*apiserver.EncryptionConfiguration)
+	if !ok {
+		return nil, fmt.Errorf("got unexpected config type %v in %q", gvk, filename)
+	}
+
+	ret := []value.Transformer{}
+	for _, resource := range config.Resources {
+		prefixTransformers := []value.PrefixTransformer{}
+		for _, provider := range resource.Providers {
+			var transformer value.PrefixTransformer
+			var err error
+			switch {
+			case provider.AESCBC != nil:
+				transformer, err = keyPrefixTransformer(aesCBCPrefix, provider.AESCBC.Keys, func(secret []byte) (value.Transformer, error) {
+					block, err := aes.NewCipher(secret)
+					if err != nil {
+						return nil, err
+					}
+					return aestransformer.NewCBCTransformer(block), nil
+				})
+			case provider.AESGCM != nil:
+				transformer, err = keyPrefixTransformer(aesGCMPrefix, provider.AESGCM.Keys, func(secret []byte) (value.Transformer, error) {
+					block, err := aes.NewCipher(secret)
+					if err != nil {
+						return nil, err
+					}
+					return aestransformer.NewGCMTransformer(block)
+				})
+			case provider.Secretbox != nil:
+				transformer, err = keyPrefixTransformer(secretboxPrefix, provider.Secretbox.Keys, func(secret []byte) (value.Transformer, error) {
+					if len(secret) != 32 {
+						return nil, fmt.Errorf("expected key size 32 for secretbox provider, got %d", len(secret))
+					}
+					keyArray := [32]byte{}
+					copy(keyArray[:], secret)
+					return secretbox.NewSecretboxTransformer(keyArray), nil
+				})
+			default:
+				// identity needs no transformation, and KMS cannot be decrypted offline
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error in encryption configuration %q for %v: %v", filename, resource.Resources, err)
+			}
+			prefixTransformers = append(prefixTransformers, transformer)
+		}
+		if len(prefixTransformers) == 0 {
+			continue
+		}
+		ret = append(ret, value.NewPrefixTransformers(fmt.Errorf("no provider for %v matches the value", resource.Resources), prefixTransformers...))
+	}
+	return ret, nil
+}
+
+// keyPrefixTransformer returns a transformer that picks between the named keys of a provider, values are prefixed
+// with the provider and then the name of the key that encrypted them.
+func keyPrefixTransformer(prefix string, keys []apiserver.Key, newTransformer func(secret []byte) (value.Transformer, error)) (value.PrefixTransformer, error) {
+	keyTransformers := []value.PrefixTransformer{}
+	for _, key := range keys {
+		secret, err := base64.StdEncoding.DecodeString(key.Secret)
+		if err != nil {
+			return value.PrefixTransformer{}, fmt.Errorf("could not decode secret for key %s: %v", key.Name, err)
+		}
+		transformer, err := newTransformer(secret)
+		if err != nil {
+			return value.PrefixTransformer{}, fmt.Errorf("error creating transformer for key %s: %v", key.Name, err)
+		}
+		keyTransformers = append(keyTransformers, value.PrefixTransformer{
+			Prefix:      []byte(key.Name + ":"),
+			Transformer: transformer,
+		})
+	}
+	return value.PrefixTransformer{
+		Prefix:      []byte(prefix),
+		Transformer: value.NewPrefixTransformers(fmt.Errorf("no matching key was found for %s", prefix), keyTransformers...),
+	}, nil
+}
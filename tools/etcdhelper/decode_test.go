@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+const encryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+- resources:
+  - secrets
+  providers:
+  - aescbc:
+      keys:
+      - name: key1
+        secret: YWVzY2JjLWtleS1mb3ItZXRjZGhlbHBlci10ZXN0ISE=
+  - identity: {}
+- resources:
+  - routes.route.openshift.io
+  providers:
+  - aesgcm:
+      keys:
+      - name: key2
+        secret: YWVzZ2NtLWtleS0xNmJ5dA==
+- resources:
+  - configmaps
+  providers:
+  - secretbox:
+      keys:
+      - name: key3
+        secret: c2VjcmV0Ym94LWtleS1mb3ItZXRjZGhlbHBlci0zMmI=
+  - kms:
+      name: vault
+      endpoint: unix:///var/run/kms.sock
+`
+
+func TestDecodeEncrypted(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "encryption-config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(encryptionConfig), 0644))
+	decoder, err := newValueDecoder(configFile)
+	require.NoError(t, err)
+	require.Len(t, decoder.transformers, 3)
+
+	secretJSON := []byte(`{"kind":"Secret","apiVersion":"v1","metadata":{"name":"foo","namespace":"bar"},"data":{"a":"Yg=="}}`)
+	for i, transformer := range decoder.transformers {
+		key := "/kubernetes.io/secrets/bar/foo"
+		encrypted, err := transformer.TransformToStorage(context.Background(), secretJSON, value.DefaultContext(key))
+		require.NoError(t, err)
+		assert.Equal(t, []string{aesCBCPrefix + "key1:", aesGCMPrefix + "key2:", secretboxPrefix + "key3:"}[i], encryptionProvider(encrypted))
+
+		obj, gvk, err := decoder.decode(key, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "Secret", gvk.Kind)
+		assert.Equal(t, []byte("b"), obj.(*corev1.Secret).Data["a"])
+
+		if i == 1 {
+			// aesgcm authenticates the key the value was stored under
+			_, _, err = decoder.decode("/kubernetes.io/secrets/bar/other", encrypted)
+			assert.Error(t, err)
+		}
+	}
+
+	_, _, err = decoder.decode("/kubernetes.io/configmaps/bar/foo", []byte("k8s:enc:kms:v2:vault:ciphertext"))
+	assert.EqualError(t, err, "value is encrypted with k8s:enc:kms:v2:vault, which needs the KMS plugin to decrypt")
+
+	noConfig, err := newValueDecoder("")
+	require.NoError(t, err)
+	_, _, err = noConfig.decode("/kubernetes.io/secrets/bar/foo", []byte("k8s:enc:aescbc:v1:key1:ciphertext"))
+	assert.EqualError(t, err, "value is encrypted with k8s:enc:aescbc:v1:key1, use -encryption-config to decrypt it")
+}
+
+func TestDecodeCustomResource(t *testing.T) {
+	decoder, err := newValueDecoder("")
+	require.NoError(t, err)
+
+	obj, gvk, err := decoder.decode("/kubernetes.io/example.com/widgets/bar/foo",
+		[]byte(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"foo","namespace":"bar"},"spec":{"size":3}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/v1, Kind=Widget", gvk.String())
+	assert.Equal(t, "foo", obj.(*unstructured.Unstructured).GetName())
+
+	_, _, err = decoder.decode("/kubernetes.io/example.com/widgets/bar/foo", []byte("k8s\x00\x0a\x0bnot a kind"))
+	assert.Error(t, err)
+}
@@ -8,11 +8,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"syscall"
 	"time"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/kubectl/pkg/scheme"
 
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/v3"
 
@@ -25,15 +30,19 @@ func init() {
 }
 
 func main() {
-	var endpoint, keyFile, certFile, caFile string
+	var endpoint, keyFile, certFile, caFile, snapshotFile, encryptionConfigFile string
+	var revision int64
 	flag.StringVar(&endpoint, "endpoint", "https://127.0.0.1:2379", "etcd endpoint.")
 	flag.StringVar(&keyFile, "key", "", "TLS client key.")
 	flag.StringVar(&certFile, "cert", "", "TLS client certificate.")
 	flag.StringVar(&caFile, "cacert", "", "Server TLS CA certificate.")
+	flag.StringVar(&snapshotFile, "snapshot", "", "Read from an etcd snapshot.db file instead of a live etcd.")
+	flag.StringVar(&encryptionConfigFile, "encryption-config", "", "EncryptionConfiguration file with the keys to decrypt encrypted resources.")
+	flag.Int64Var(&revision, "revision", 0, "Read keys as of this revision, or watch from it. Defaults to the latest revision.")
 	flag.Parse()
 
 	if flag.NArg() == 0 {
-		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key>\n")
+		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key> or watch [<key>]\n")
 		os.Exit(1)
 	}
 	if flag.Arg(0) == "get" && flag.NArg() == 1 {
@@ -44,12 +53,33 @@ func main() {
 		fmt.Fprint(os.Stderr, "ERROR: you cannot specify positional arguments with dump\n")
 		os.Exit(1)
 	}
+	if flag.Arg(0) == "watch" && len(snapshotFile) != 0 {
+		fmt.Fprint(os.Stderr, "ERROR: watch needs a live etcd, it cannot be used with -snapshot\n")
+		os.Exit(1)
+	}
 	action := flag.Arg(0)
 	key := ""
 	if flag.NArg() > 1 {
 		key = flag.Arg(1)
 	}
 
+	decoder, err := newValueDecoder(encryptionConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: unable to load encryption config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(snapshotFile) != 0 {
+		source, err := newSnapshotSource(snapshotFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		defer source.Close()
+		run(action, key, revision, source, nil, decoder)
+		return
+	}
+
 	var tlsConfig *tls.Config
 	if len(certFile) != 0 || len(keyFile) != 0 || len(caFile) != 0 {
 		tlsInfo := transport.TLSInfo{
@@ -75,15 +105,23 @@ func main() {
 		fmt.Fprintf(os.Stderr, "ERROR: unable to connect to etcd: %v\n", err)
 		os.Exit(1)
 	}
-	defer client.Close()
+	source := &etcdSource{client: client}
+	defer source.Close()
+	run(action, key, revision, source, client, decoder)
+}
 
+// run performs the action, client is only set when reading from a live etcd.
+func run(action, key string, revision int64, source kvSource, client *clientv3.Client, decoder *valueDecoder) {
+	var err error
 	switch action {
 	case "ls":
-		err = listKeys(client, key)
+		err = listKeys(source, key, revision)
 	case "get":
-		err = getKey(client, key)
+		err = getKey(source, key, revision, decoder)
 	case "dump":
-		err = dump(client)
+		err = dump(source, revision, decoder)
+	case "watch":
+		err = watch(client, key, revision, decoder)
 	default:
 		fmt.Fprintf(os.Stderr, "ERROR: invalid action: %s\n", action)
 		os.Exit(1)
@@ -94,36 +132,35 @@ func main() {
 	}
 }
 
-func listKeys(client *clientv3.Client, key string) error {
-	var resp *clientv3.GetResponse
-	var err error
-	if len(key) == 0 {
-		resp, err = clientv3.NewKV(client).Get(context.Background(), "/", clientv3.WithFromKey(), clientv3.WithKeysOnly())
-	} else {
-		resp, err = clientv3.NewKV(client).Get(context.Background(), key, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+func listKeys(source kvSource, key string, revision int64) error {
+	request := rangeRequest{start: "/", end: "\x00"}
+	if len(key) != 0 {
+		request = prefixRange(key)
 	}
+	request.revision = revision
+	request.keysOnly = true
+	kvs, err := source.Range(context.Background(), request)
 	if err != nil {
 		return err
 	}
 
-	for _, kv := range resp.Kvs {
+	for _, kv := range kvs {
 		fmt.Println(string(kv.Key))
 	}
 
 	return nil
 }
 
-func getKey(client *clientv3.Client, key string) error {
-	resp, err := clientv3.NewKV(client).Get(context.Background(), key)
+func getKey(source kvSource, key string, revision int64, decoder *valueDecoder) error {
+	kvs, err := source.Range(context.Background(), rangeRequest{start: key, revision: revision})
 	if err != nil {
 		return err
 	}
 
-	decoder := scheme.Codecs.UniversalDeserializer()
 	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, true)
 
-	for _, kv := range resp.Kvs {
-		obj, gvk, err := decoder.Decode(kv.Value, nil, nil)
+	for _, kv := range kvs {
+		obj, gvk, err := decoder.decode(string(kv.Key), kv.Value)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARN: unable to decode %s: %v\n", kv.Key, err)
 			continue
@@ -139,19 +176,23 @@ func getKey(client *clientv3.Client, key string) error {
 	return nil
 }
 
-func dump(client *clientv3.Client) error {
-	response, err := clientv3.NewKV(client).Get(context.Background(), "/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
+func dump(source kvSource, revision int64, decoder *valueDecoder) error {
+	request := prefixRange("/")
+	request.revision = revision
+	kvs, err := source.Range(context.Background(), request)
 	if err != nil {
 		return err
 	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return bytes.Compare(kvs[i].Key, kvs[j].Key) > 0
+	})
 
 	kvData := []etcd3kv{}
-	decoder := scheme.Codecs.UniversalDeserializer()
 	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, false)
 	objJSON := &bytes.Buffer{}
 
-	for _, kv := range response.Kvs {
-		obj, _, err := decoder.Decode(kv.Value, nil, nil)
+	for _, kv := range kvs {
+		obj, _, err := decoder.decode(string(kv.Key), kv.Value)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARN: error decoding value %q: %v\n", string(kv.Value), err)
 			continue
@@ -184,6 +225,47 @@ func dump(client *clientv3.Client) error {
 	return nil
 }
 
+// watch prints every change under key until interrupted, starting from revision to replay the history after it.
+func watch(client *clientv3.Client, key string, revision int64, decoder *valueDecoder) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if len(key) == 0 {
+		key = "/"
+	}
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if revision > 0 {
+		opts = append(opts, clientv3.WithRev(revision))
+	}
+
+	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, true)
+	for response := range clientv3.NewWatcher(client).Watch(clientv3.WithRequireLeader(ctx), key, opts...) {
+		if err := response.Err(); err != nil {
+			return err
+		}
+		for _, event := range response.Events {
+			printEvent(event, decoder, encoder)
+		}
+	}
+	return nil
+}
+
+func printEvent(event *clientv3.Event, decoder *valueDecoder, encoder runtime.Encoder) {
+	kv := event.Kv
+	fmt.Printf("%s %s (revision %d)\n", event.Type, kv.Key, kv.ModRevision)
+	if event.Type == mvccpb.DELETE {
+		return
+	}
+	obj, _, err := decoder.decode(string(kv.Key), kv.Value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: unable to decode %s: %v\n", kv.Key, err)
+		return
+	}
+	if err := encoder.Encode(obj, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: unable to encode %s: %v\n", kv.Key, err)
+	}
+}
+
 type etcd3kv struct {
 	Key            string `json:"key,omitempty"`
 	Value          string `json:"value,omitempty"`
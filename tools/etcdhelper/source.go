@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/v3"
+)
+
+// rangeRequest follows the etcd range semantics: an empty end is the single key start, an end of "\x00" is every key
+// from start on, anything else is every key in [start, end).
+type rangeRequest struct {
+	start string
+	end   string
+	// revision is the revision to read at, 0 is the latest.
+	revision int64
+	keysOnly bool
+}
+
+func prefixRange(key string) rangeRequest {
+	return rangeRequest{start: key, end: clientv3.GetPrefixRangeEnd(key)}
+}
+
+func (r rangeRequest) contains(key []byte) bool {
+	switch r.end {
+	case "":
+		return string(key) == r.start
+	case "\x00":
+		return string(key) >= r.start
+	default:
+		return string(key) >= r.start && string(key) < r.end
+	}
+}
+
+// kvSource is where etcdhelper reads keys from, a live etcd member or a snapshot file.
+type kvSource interface {
+	Range(ctx context.Context, request rangeRequest) ([]*mvccpb.KeyValue, error)
+	Close() error
+}
+
+type etcdSource struct {
+	client *clientv3.Client
+}
+
+func (s *etcdSource) Range(ctx context.Context, request rangeRequest) ([]*mvccpb.KeyValue, error) {
+	opts := []clientv3.OpOption{clientv3.WithRev(request.revision), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend)}
+	if len(request.end) > 0 {
+		opts = append(opts, clientv3.WithRange(request.end))
+	}
+	if request.keysOnly {
+		opts = append(opts, clientv3.WithKeysOnly())
+	}
+	resp, err := clientv3.NewKV(s.client).Get(ctx, request.start, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Kvs, nil
+}
+
+func (s *etcdSource) Close() error {
+	return s.client.Close()
+}
+
+// These are the bucket and key names of the etcd mvcc store in the bolt database.
+var (
+	keyBucketName      = []byte("key")
+	metaBucketName     = []byte("meta")
+	finishedCompactKey = []byte("finishedCompactRev")
+)
+
+// revBytesLen is the length of a revision key, 8 bytes of main revision, '_', and 8 bytes of sub revision.  Deletes
+// are recorded as tombstones, the same key with a trailing 't'.
+const revBytesLen = 8 + 1 + 8
+
+// snapshotSource reads the mvcc store of an etcd snapshot or data directory member/snap/db file, without a server.
+type snapshotSource struct {
+	db *bolt.DB
+}
+
+func newSnapshotSource(filename string) (*snapshotSource, error) {
+	db, err := bolt.Open(filename, 0400, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open snapshot %s: %v", filename, err)
+	}
+	return &snapshotSource{db: db}, nil
+}
+
+func (s *snapshotSource) Range(ctx context.Context, request rangeRequest) ([]*mvccpb.KeyValue, error) {
+	latest := map[string]*mvccpb.KeyValue{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		keys := tx.Bucket(keyBucketName)
+		if keys == nil {
+			return fmt.Errorf("not an etcd snapshot, there is no %q bucket", keyBucketName)
+		}
+		if request.revision > 0 {
+			if compacted := compactedRevision(tx); request.revision < compacted {
+				return fmt.Errorf("revision %d has been compacted, the snapshot has history from revision %d", request.revision, compacted)
+			}
+		}
+
+		var current int64
+		// revisions sort in order, so replaying them leaves the value of every key as of the requested revision
+		err := keys.ForEach(func(revBytes, data []byte) error {
+			if len(revBytes) < revBytesLen {
+				return fmt.Errorf("invalid revision key %x", revBytes)
+			}
+			current = int64(binary.BigEndian.Uint64(revBytes[0:8]))
+			if request.revision > 0 && current > request.revision {
+				return nil
+			}
+			kv := &mvccpb.KeyValue{}
+			if err := kv.Unmarshal(data); err != nil {
+				return fmt.Errorf("unable to decode the value at revision %d: %v", current, err)
+			}
+			if !request.contains(kv.Key) {
+				return nil
+			}
+			if len(revBytes) > revBytesLen && revBytes[revBytesLen] == 't' {
+				delete(latest, string(kv.Key))
+				return nil
+			}
+			latest[string(kv.Key)] = kv
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if request.revision > current {
+			return fmt.Errorf("revision %d is newer than the latest revision %d of the snapshot", request.revision, current)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*mvccpb.KeyValue{}
+	for _, kv := range latest {
+		if request.keysOnly {
+			kv.Value = nil
+		}
+		ret = append(ret, kv)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return bytes.Compare(ret[i].Key, ret[j].Key) < 0
+	})
+	return ret, nil
+}
+
+func compactedRevision(tx *bolt.Tx) int64 {
+	meta := tx.Bucket(metaBucketName)
+	if meta == nil {
+		return 0
+	}
+	revBytes := meta.Get(finishedCompactKey)
+	if len(revBytes) < 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(revBytes[0:8]))
+}
+
+func (s *snapshotSource) Close() error {
+	return s.db.Close()
+}
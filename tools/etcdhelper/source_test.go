@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func revisionKey(main, sub int64, tombstone bool) []byte {
+	ret := make([]byte, revBytesLen, revBytesLen+1)
+	binary.BigEndian.PutUint64(ret, uint64(main))
+	ret[8] = '_'
+	binary.BigEndian.PutUint64(ret[9:], uint64(sub))
+	if tombstone {
+		ret = append(ret, 't')
+	}
+	return ret
+}
+
+func writeSnapshot(t *testing.T, compacted int64, kvs ...*mvccpb.KeyValue) string {
+	filename := filepath.Join(t.TempDir(), "snapshot.db")
+	db, err := bolt.Open(filename, 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		keys, err := tx.CreateBucket(keyBucketName)
+		require.NoError(t, err)
+		for _, kv := range kvs {
+			data, err := kv.Marshal()
+			require.NoError(t, err)
+			// a tombstone has no value and no create revision
+			require.NoError(t, keys.Put(revisionKey(kv.ModRevision, 0, kv.CreateRevision == 0), data))
+		}
+		meta, err := tx.CreateBucket(metaBucketName)
+		require.NoError(t, err)
+		return meta.Put(finishedCompactKey, revisionKey(compacted, 0, false))
+	}))
+	return filename
+}
+
+func TestSnapshotSource(t *testing.T) {
+	filename := writeSnapshot(t, 2,
+		&mvccpb.KeyValue{Key: []byte("/kubernetes.io/configmaps/ns/a"), Value: []byte("a1"), CreateRevision: 2, ModRevision: 2, Version: 1},
+		&mvccpb.KeyValue{Key: []byte("/kubernetes.io/configmaps/ns/b"), Value: []byte("b1"), CreateRevision: 3, ModRevision: 3, Version: 1},
+		&mvccpb.KeyValue{Key: []byte("/kubernetes.io/configmaps/ns/a"), Value: []byte("a2"), CreateRevision: 2, ModRevision: 4, Version: 2},
+		&mvccpb.KeyValue{Key: []byte("/kubernetes.io/configmaps/ns/b"), ModRevision: 5},
+		&mvccpb.KeyValue{Key: []byte("/kubernetes.io/secrets/ns/c"), Value: []byte("c1"), CreateRevision: 6, ModRevision: 6, Version: 1},
+	)
+	source, err := newSnapshotSource(filename)
+	require.NoError(t, err)
+	defer source.Close()
+
+	values := func(request rangeRequest) map[string]string {
+		kvs, err := source.Range(context.Background(), request)
+		require.NoError(t, err)
+		ret := map[string]string{}
+		for _, kv := range kvs {
+			ret[string(kv.Key)] = string(kv.Value)
+		}
+		return ret
+	}
+
+	assert.Equal(t, map[string]string{
+		"/kubernetes.io/configmaps/ns/a": "a2",
+		"/kubernetes.io/secrets/ns/c":    "c1",
+	}, values(prefixRange("/")))
+	assert.Equal(t, map[string]string{"/kubernetes.io/configmaps/ns/a": "a2"}, values(prefixRange("/kubernetes.io/configmaps/")))
+	assert.Equal(t, map[string]string{"/kubernetes.io/secrets/ns/c": "c1"}, values(rangeRequest{start: "/kubernetes.io/secrets/ns/c"}))
+	assert.Equal(t, map[string]string{"/kubernetes.io/secrets/ns/c": ""}, values(rangeRequest{start: "/kubernetes.io/s", end: "\x00", keysOnly: true}))
+
+	history := prefixRange("/kubernetes.io/configmaps/")
+	history.revision = 3
+	assert.Equal(t, map[string]string{
+		"/kubernetes.io/configmaps/ns/a": "a1",
+		"/kubernetes.io/configmaps/ns/b": "b1",
+	}, values(history))
+
+	history.revision = 1
+	_, err = source.Range(context.Background(), history)
+	assert.EqualError(t, err, "revision 1 has been compacted, the snapshot has history from revision 2")
+	history.revision = 7
+	_, err = source.Range(context.Background(), history)
+	assert.EqualError(t, err, "revision 7 is newer than the latest revision 6 of the snapshot")
+}
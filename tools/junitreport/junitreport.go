@@ -50,8 +50,8 @@ func init() {
 const (
 	junitReportUsageLong = `Consume test output to create jUnit XML files and summarize jUnit XML files.
 
-%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test'
-and the output of 'oscmd' functions with $JUNIT_REPORT_OUTPUT set are supported. jUnit XML can be build with
+%[1]s consumes test output through Stdin and creates jUnit XML files. Currently, only the output of 'go test',
+'go test -json', and 'oscmd' functions with $JUNIT_REPORT_OUTPUT set are supported. jUnit XML can be build with
 nested or flat test suites. Sub-trees of test suites can be selected when using the nested test-suites represen-
 tation to only build XML for some subset of the test output. This parser is greedy, so all output not directly
 related to a test suite is considered test case output.
@@ -81,6 +81,9 @@ related to a test suite is considered test case output.
   # Consume 'go test' output to create a jUnit XML file with nested test suites rooted at 'github.com/maintainer'
   go test -v -cover ./... | junitreport --suites=nested --roots=github.com/maintainer > report.xml
 
+  # Consume 'go test -json' output, which attributes the output of parallel tests correctly, to create a jUnit XML file
+  go test -json -cover ./... 2>&1 | %[1]s --type=gotestjson > report.xml
+
   # Describe failures and skipped tests in an existing jUnit XML file
   cat report.xml | %[1]s summarize
 
@@ -11,6 +11,7 @@ import (
 	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotestjson"
 	"github.com/openshift/origin/tools/junitreport/pkg/parser/oscmd"
 )
 
@@ -26,11 +27,12 @@ var supportedBuilderTypes = []testSuitesBuilderType{flatBuilderType, nestedBuild
 type testParserType string
 
 const (
-	goTestParserType testParserType = "gotest"
-	osCmdParserType  testParserType = "oscmd"
+	goTestParserType     testParserType = "gotest"
+	goTestJSONParserType testParserType = "gotestjson"
+	osCmdParserType      testParserType = "oscmd"
 )
 
-var supportedTestParserTypes = []testParserType{goTestParserType, osCmdParserType}
+var supportedTestParserTypes = []testParserType{goTestParserType, goTestJSONParserType, osCmdParserType}
 
 type JUnitReportOptions struct {
 	// BuilderType is the type of test suites builder to use
@@ -67,6 +69,8 @@ func (o *JUnitReportOptions) Complete(builderType, parserType string, rootSuiteN
 	switch testParserType(parserType) {
 	case goTestParserType:
 		o.ParserType = goTestParserType
+	case goTestJSONParserType:
+		o.ParserType = goTestJSONParserType
 	case osCmdParserType:
 		o.ParserType = osCmdParserType
 	default:
@@ -91,6 +95,8 @@ func (o *JUnitReportOptions) Run() error {
 	switch o.ParserType {
 	case goTestParserType:
 		testParser = gotest.NewParser(builder, o.Stream)
+	case goTestJSONParserType:
+		testParser = gotestjson.NewParser(builder, o.Stream)
 	case osCmdParserType:
 		testParser = oscmd.NewParser(builder, o.Stream)
 	}
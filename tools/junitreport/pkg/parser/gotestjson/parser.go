@@ -0,0 +1,334 @@
+package gotestjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser"
+	"github.com/openshift/origin/tools/junitreport/pkg/parser/gotest"
+)
+
+// NewParser returns a new parser that's capable of parsing `go test -json` output
+func NewParser(builder builder.TestSuitesBuilder, stream bool) parser.TestOutputParser {
+	return &testOutputParser{
+		builder: builder,
+		stream:  stream,
+	}
+}
+
+type testOutputParser struct {
+	builder builder.TestSuitesBuilder
+	stream  bool
+}
+
+// event is a single line of `go test -json` output, as described by `go doc test2json`
+type event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+
+	// ImportPath is set on build-output and build-fail events instead of Package
+	ImportPath string
+	// FailedBuild is set on the package fail event when the package failed to build
+	FailedBuild string
+}
+
+const (
+	actionRun         = "run"
+	actionOutput      = "output"
+	actionPass        = "pass"
+	actionFail        = "fail"
+	actionSkip        = "skip"
+	actionBuildOutput = "build-output"
+)
+
+// packageTestName is the name of the test case recorded for failures of a package outside of any test, like build
+// errors or a panic in TestMain, the same name other go test reporters use
+const packageTestName = "TestMain"
+
+// logPrefix matches the file and line t.Log and friends start every message with
+var logPrefix = regexp.MustCompile(`^[^\s:]+\.go:\d+: `)
+
+// testRecord accumulates the events of a single test
+type testRecord struct {
+	testCase *api.TestCase
+	done     bool
+	// output is what the test printed, messages are the t.Log, t.Error and t.Skip lines
+	output   []string
+	messages []string
+	// inMessage is set while the lines of a multi-line message are read
+	inMessage bool
+}
+
+// addOutput records a line the test printed. t.Log and friends indent their messages four spaces for every level of
+// the test, and the lines after the first of a message four more, so only lines with the indent of the test named by
+// the event are messages. Anything else the test printed is output, even when it is indented.
+func (t *testRecord) addOutput(output string) {
+	indent := strings.Repeat("    ", strings.Count(t.testCase.Name, "/")+1)
+	message, indented := strings.CutPrefix(output, indent)
+	switch {
+	case indented && logPrefix.MatchString(message):
+		t.inMessage = true
+		t.messages = append(t.messages, message)
+	case indented && t.inMessage && strings.HasPrefix(message, "    "):
+		t.messages = append(t.messages, strings.TrimPrefix(message, "    "))
+	default:
+		t.inMessage = false
+		t.output = append(t.output, output)
+	}
+}
+
+// packageRecord accumulates the events of a single package
+type packageRecord struct {
+	tests       map[string]*testRecord
+	orderedRuns []string
+	output      []string
+	properties  map[string]string
+	// unfinished is set when the input ended before the result of the package
+	unfinished bool
+}
+
+func newPackageRecord() *packageRecord {
+	return &packageRecord{
+		tests:      map[string]*testRecord{},
+		properties: map[string]string{},
+	}
+}
+
+func (r *packageRecord) test(name string) *testRecord {
+	test, ok := r.tests[name]
+	if !ok {
+		test = &testRecord{testCase: &api.TestCase{Name: name}}
+		r.tests[name] = test
+		r.orderedRuns = append(r.orderedRuns, name)
+	}
+	return test
+}
+
+// Parse parses `go test -json` output into test suites. Every event names the package and test it belongs to, so
+// unlike `go test -v` output, output interleaved by parallel tests, subtests and packages is attributed correctly.
+// Lines that are not JSON are compiler output, which older versions of go write to stderr instead of as events.
+// Packages without a result, because go test was killed or the input was cut short, fail.
+func (p *testOutputParser) Parse(input *bufio.Scanner) (*api.TestSuites, error) {
+	packages := map[string]*packageRecord{}
+	// buildOutput is keyed by import path, and holds the compiler errors of packages that failed to build
+	buildOutput := map[string][]string{}
+	var currentBuild string
+
+	var count int
+	for input.Scan() {
+		line := input.Text()
+		count++
+
+		if !strings.HasPrefix(line, "{") {
+			// compiler output starts with the name of the package being built, like `# example.com/package`
+			if strings.HasPrefix(line, "# ") {
+				currentBuild = importPath(strings.TrimPrefix(line, "# "))
+				continue
+			}
+			if len(currentBuild) > 0 {
+				buildOutput[currentBuild] = append(buildOutput[currentBuild], line)
+			}
+			continue
+		}
+
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("unexpected output on line %d, not a test event: %v", count, err)
+		}
+
+		if e.Action == actionBuildOutput {
+			path := importPath(e.ImportPath)
+			output := strings.TrimSuffix(e.Output, "\n")
+			if !strings.HasPrefix(output, "# ") {
+				buildOutput[path] = append(buildOutput[path], output)
+			}
+			continue
+		}
+		if len(e.Package) == 0 {
+			continue
+		}
+
+		pkg, ok := packages[e.Package]
+		if !ok {
+			pkg = newPackageRecord()
+			packages[e.Package] = pkg
+		}
+
+		if len(e.Test) == 0 {
+			switch e.Action {
+			case actionOutput:
+				output := strings.TrimSuffix(e.Output, "\n")
+				if props, ok := gotest.ExtractProperties(output); ok {
+					for k, v := range props {
+						pkg.properties[k] = v
+					}
+				}
+				pkg.output = append(pkg.output, output)
+			case actionPass, actionFail, actionSkip:
+				suite, err := p.finishPackage(e, pkg, buildOutput[e.Package])
+				if err != nil {
+					return nil, fmt.Errorf("unexpected duration on line %d: %v", count, err)
+				}
+				delete(packages, e.Package)
+				if suite != nil {
+					p.builder.AddSuite(suite)
+				}
+			}
+			continue
+		}
+
+		test := pkg.test(e.Test)
+		switch e.Action {
+		case actionOutput:
+			output := strings.TrimSuffix(e.Output, "\n")
+			if isFraming(output) {
+				continue
+			}
+			test.addOutput(output)
+		case actionPass, actionFail, actionSkip:
+			test.done = true
+			switch e.Action {
+			case actionFail:
+				test.testCase.FailureOutput = &api.FailureOutput{}
+			case actionSkip:
+				test.testCase.SkipMessage = &api.SkipMessage{}
+			}
+			if err := test.testCase.SetDuration(elapsed(e.Elapsed)); err != nil {
+				return nil, fmt.Errorf("unexpected duration on line %d: %v", count, err)
+			}
+		}
+	}
+	if err := input.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read line %d: %v", count+1, err)
+	}
+
+	unfinished := make([]string, 0, len(packages))
+	for name := range packages {
+		unfinished = append(unfinished, name)
+	}
+	sort.Strings(unfinished)
+	for _, name := range unfinished {
+		pkg := packages[name]
+		pkg.unfinished = true
+		suite, err := p.finishPackage(event{Action: actionFail, Package: name}, pkg, buildOutput[name])
+		if err != nil {
+			return nil, err
+		}
+		p.builder.AddSuite(suite)
+	}
+
+	return p.builder.Build(), nil
+}
+
+// finishPackage builds the test suite for a package once its result is known. A package with no tests and no
+// failure, like one with no test files, has no suite.
+func (p *testOutputParser) finishPackage(e event, pkg *packageRecord, buildOutput []string) (*api.TestSuite, error) {
+	if e.Action == actionSkip && len(pkg.orderedRuns) == 0 {
+		return nil, nil
+	}
+
+	suite := &api.TestSuite{Name: e.Package}
+	for k, v := range pkg.properties {
+		suite.AddProperty(k, v)
+	}
+
+	anyTestFailed := false
+	for _, name := range pkg.orderedRuns {
+		test := pkg.tests[name]
+		switch {
+		case test.done:
+		case pkg.unfinished:
+			test.testCase.FailureOutput = &api.FailureOutput{Message: "the output ended before the test finished"}
+		case e.Action == actionFail:
+			// the package failed while the test was running, usually a panic or a timeout in another test
+			test.testCase.FailureOutput = &api.FailureOutput{Message: "the test did not finish before the package failed"}
+		}
+		anyTestFailed = anyTestFailed || test.testCase.FailureOutput != nil
+
+		switch {
+		case test.testCase.FailureOutput != nil:
+			// a panic or a failed assertion library call is plain output, so keep it with the failure
+			test.testCase.FailureOutput.Output = joinLines(append(test.messages, test.output...))
+		case test.testCase.SkipMessage != nil:
+			test.testCase.SkipMessage.Message = joinLines(test.messages)
+		default:
+			test.testCase.SystemOut = joinLines(test.output)
+		}
+		suite.AddTestCase(test.testCase)
+	}
+
+	if e.Action == actionFail && !anyTestFailed {
+		message := "the package failed outside of any test"
+		output := pkg.output
+		switch {
+		case len(e.FailedBuild) > 0 || len(buildOutput) > 0:
+			message = "the package failed to build"
+			output = append(append([]string{}, buildOutput...), output...)
+		case pkg.unfinished:
+			message = "the output ended before the package finished"
+		}
+		suite.AddTestCase(&api.TestCase{
+			Name: packageTestName,
+			FailureOutput: &api.FailureOutput{
+				Message: message,
+				Output:  joinLines(output),
+			},
+		})
+	}
+
+	if err := suite.SetDuration(elapsed(e.Elapsed)); err != nil {
+		return nil, err
+	}
+
+	if p.stream {
+		result := "ok  "
+		if e.Action == actionFail {
+			result = "FAIL"
+		}
+		fmt.Fprintf(os.Stdout, "%s\t%s\t%.3fs\n", result, e.Package, e.Elapsed)
+	}
+	return suite, nil
+}
+
+// importPath strips the test variant from an import path, `example.com/package [example.com/package.test]` is built
+// for the tests of `example.com/package`
+func importPath(path string) string {
+	if i := strings.Index(path, " "); i > 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// isFraming determines if the line is one go test writes around the output of a test, rather than output of the test
+func isFraming(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- PASS:", "--- FAIL:", "--- SKIP:"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func elapsed(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).String()
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,172 @@
+package gotestjson
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/flat"
+)
+
+// TestFlatParse tests that parsing the `go test -json` output in the test directory with a flat builder works as expected
+func TestFlatParse(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testFile       string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "parallel",
+			testFile: "3.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					parallelSuite,
+				},
+			},
+		},
+		{
+			name:     "build failure",
+			testFile: "4.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					buildFailureSuite,
+				},
+			},
+		},
+		{
+			name:     "indented output and unfinished package",
+			testFile: "5.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					indentedOutputSuite,
+					unfinishedSuite,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(flat.NewTestSuitesBuilder(), false)
+
+			testFile := "./../../../test/gotestjson/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%#v\n%#v", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}
+
+// parallelSuite is the suite for 3.txt, where the output of parallel tests is interleaved
+var parallelSuite = &api.TestSuite{
+	Name:       "package/name",
+	NumTests:   4,
+	NumSkipped: 1,
+	NumFailed:  1,
+	Duration:   0.05,
+	Properties: []*api.TestSuiteProperty{
+		{
+			Name:  "coverage.statements.pct",
+			Value: "12.5",
+		},
+	},
+	TestCases: []*api.TestCase{
+		{
+			Name:     "TestOne",
+			Duration: 0.02,
+		},
+		{
+			Name:     "TestTwo",
+			Duration: 0.01,
+			FailureOutput: &api.FailureOutput{
+				Output: "file_test.go:20: two failed\ntwo starting\n",
+			},
+		},
+		{
+			Name: "TestThree",
+		},
+		{
+			Name: "TestThree/subtest",
+			SkipMessage: &api.SkipMessage{
+				Message: "file_test.go:30: not today\n",
+			},
+		},
+	},
+}
+
+// buildFailureSuite is the suite for 4.txt, where the compiler output is not JSON
+var buildFailureSuite = &api.TestSuite{
+	Name:      "package/broken",
+	NumTests:  1,
+	NumFailed: 1,
+	TestCases: []*api.TestCase{
+		{
+			Name: "TestMain",
+			FailureOutput: &api.FailureOutput{
+				Message: "the package failed to build",
+				Output:  "broken/file_test.go:6:2: undefined: missing\nFAIL\tpackage/broken [build failed]\n",
+			},
+		},
+	},
+}
+
+// indentedOutputSuite is the first suite for 5.txt, where a test prints indented output and a multi-line message
+var indentedOutputSuite = &api.TestSuite{
+	Name:      "package/name",
+	NumTests:  1,
+	NumFailed: 1,
+	Duration:  0.02,
+	TestCases: []*api.TestCase{
+		{
+			Name:     "TestConfig",
+			Duration: 0.01,
+			FailureOutput: &api.FailureOutput{
+				Output: "config_test.go:12: unexpected replicas:\nexpected 2\nconfig:\n    replicas: 3\n",
+			},
+		},
+	},
+}
+
+// unfinishedSuite is the second suite for 5.txt, where the output ends while a test is running
+var unfinishedSuite = &api.TestSuite{
+	Name:      "package/killed",
+	NumTests:  2,
+	NumFailed: 1,
+	TestCases: []*api.TestCase{
+		{
+			Name: "TestDone",
+		},
+		{
+			Name: "TestSlow",
+			FailureOutput: &api.FailureOutput{
+				Message: "the output ended before the test finished",
+				Output:  "waiting for the server\n",
+			},
+		},
+	},
+}
+
+// TestParseLongLine tests that a line longer than the scanner buffer is an error rather than the end of the input
+func TestParseLongLine(t *testing.T) {
+	input := `{"Action":"start","Package":"package/name"}` + "\n" +
+		`{"Action":"output","Package":"package/name","Output":"` + strings.Repeat("x", bufio.MaxScanTokenSize) + `"}` + "\n"
+
+	parser := NewParser(flat.NewTestSuitesBuilder(), false)
+	_, err := parser.Parse(bufio.NewScanner(strings.NewReader(input)))
+	if err == nil || !strings.Contains(err.Error(), "unable to read line 2: bufio.Scanner: token too long") {
+		t.Errorf("expected the line to be too long, got %v", err)
+	}
+}
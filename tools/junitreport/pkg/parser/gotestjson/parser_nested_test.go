@@ -0,0 +1,105 @@
+package gotestjson
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/tools/junitreport/pkg/api"
+	"github.com/openshift/origin/tools/junitreport/pkg/builder/nested"
+)
+
+// TestNestedParse tests that parsing the `go test -json` output in the test directory with a nested builder works as expected
+func TestNestedParse(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		testFile       string
+		rootSuiteNames []string
+		expectedSuites *api.TestSuites
+	}{
+		{
+			name:     "parallel",
+			testFile: "3.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:       "package",
+						NumTests:   4,
+						NumSkipped: 1,
+						NumFailed:  1,
+						Duration:   0.05,
+						Children: []*api.TestSuite{
+							parallelSuite,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "parallel with restricted root",
+			testFile:       "3.txt",
+			rootSuiteNames: []string{"package/name"},
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					parallelSuite,
+				},
+			},
+		},
+		{
+			name:     "build failure",
+			testFile: "4.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package",
+						NumTests:  1,
+						NumFailed: 1,
+						Children: []*api.TestSuite{
+							buildFailureSuite,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "indented output and unfinished package",
+			testFile: "5.txt",
+			expectedSuites: &api.TestSuites{
+				Suites: []*api.TestSuite{
+					{
+						Name:      "package",
+						NumTests:  3,
+						NumFailed: 2,
+						Duration:  0.02,
+						Children: []*api.TestSuite{
+							unfinishedSuite,
+							indentedOutputSuite,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := NewParser(nested.NewTestSuitesBuilder(testCase.rootSuiteNames), false)
+
+			testFile := "./../../../test/gotestjson/testdata/" + testCase.testFile
+
+			reader, err := os.Open(testFile)
+			if err != nil {
+				t.Fatalf("unexpected error opening file %q: %v", testFile, err)
+			}
+			testSuites, err := parser.Parse(bufio.NewScanner(reader))
+			if err != nil {
+				t.Fatalf("unexpected error parsing file: %v", err)
+			}
+
+			if !reflect.DeepEqual(testSuites, testCase.expectedSuites) {
+				t.Errorf("did not produce the correct test suites from file:\n%#v\n%#v", testCase.expectedSuites, testSuites)
+			}
+		})
+	}
+}